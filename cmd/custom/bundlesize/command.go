@@ -0,0 +1,141 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this File except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundlesize
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+//go:embed *.tmpl
+var bundleSizeTemplate embed.FS
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle-size",
+		Short: "generates a custom report with the size of the bundles and its trend per package",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you are looking for to check what are the packages which have bundles that exceed or are close to
+exceed the size limit to be staged in a ConfigMap by OLM. The size is checked as OLM computes it
+(gzip followed by base64 encoding per manifest) and the report shows:
+
+- the size per bundle and the biggest manifests shipped on it
+- how much the bundles grew from one version to the next one per package
+
+**NOTE** The bundles report must be generated with a version of audit that collects the bundle size.
+
+## How to inform the threshold to warn?
+
+Use the --optional-values flag and the key warn-percent to inform from what percentage of the max size
+the bundles should be flagged (default: the value of --bundle-size-warn-percent used to generate the
+bundles report or 85), see:
+
+- --optional-values=warn-percent=75
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
+	optionalValueEmpty := map[string]string{}
+	cmd.Flags().StringToStringVarP(&custom.Flags.OptionalValues, "optional-values", "", optionalValueEmpty,
+		"Inform a []string map of key=values which can be used by the report. e.g. to flag the bundles which "+
+			"are using more than 75% of the size allowed use `--optional-values=warn-percent=75`")
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	sizeReport, err := custom.NewBundleSizeReport(bundlesReport, custom.Flags.OptionalValues, custom.Flags.Filter)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(sizeReport, sizeReport.ImageName, "bundle-size"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(sizeReport.ImageName, "bundle-size", "html"))
+
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t := template.Must(template.ParseFS(bundleSizeTemplate, "bundlesize_template.go.tmpl"))
+	err = t.Execute(f, sizeReport)
+	if err != nil {
+		panic(err)
+	}
+
+	f.Close()
+	log.Infof("Operation completed.")
+
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"strings"
 
 	"html/template"
 	"path/filepath"
@@ -40,23 +41,24 @@ func NewCmd() *cobra.Command {
 
 ## When should I use this command?
 
-if you are looking for check what are the packages and head of channels which uses the api removed 
-in the k8s 1.22 
+if you are looking for check what are the packages and head of channels which uses the APIs removed 
+in a k8s version. 
 
-OR 
+The APIs removed per k8s version are defined in a table embedded in audit (see 
+pkg/reports/custom/removed_apis.yaml), which is based on the Kubernetes deprecation guide. Use 
+--removed-apis-file to inform a file with the same format to check removals which are not in it yet. 
 
-which requests permissions in the API which will be removed in the k8s version 1.25 and 1.26.
+## How the check is done?
 
-**IMPORTANT** Note that for the k8s versions 1.25 and 1.26 we are unable to check what bundles 
-can or not work on these release. It is very unlike author add manifests with the APIs affected 
-and in many cases the Kinds are indeed not supported. 
-
-## How the check is done for 1.25 and 1.26?
-
-For these versions audit tool will check the cases where the bundles are asking permissions 
-to the APIs affected by looking at the rules (RBCA). However, by looking at the rules we 
-are unable to know if the Operator requires the versions which will be removed or not since
-the version is not present. 
+The bundles which use the APIs removed are found with the results of the validators and by 
+checking the manifests of the CSV which are not checked by them: the CRs of the alm-examples, 
+the rules of the webhookdefinitions, the apiservicedefinitions and the nativeAPIs. The report 
+shows the manifest where each API was found (e.g. alm-examples (CronJob example)). For the 
+versions where the table sets checkPermissions (e.g. 1.25 and 1.26) it is very unlike author add 
+manifests with the APIs affected, so that audit tool will also check the cases where the bundles 
+are asking permissions to the APIs affected by looking at the rules (RBAC). However, by looking at 
+the rules we are unable to know if the Operator requires the versions which will be removed or not 
+since the version is not present. 
 
 **For example:** The RBAC to create, patch, delete a CronJob can be checked but it does not
 have the versions so that, we are unable to know if the Operator still using v1beta1 
@@ -67,11 +69,7 @@ However, it allows us to check what are the packages which are more likely to be
 ## How to inform the version? 
 
 Use the --optional-values flag and the key k8s-version to inform the version which should 
-be used to generate the report, see: 
-
-- For 1.22 : --optional-values=k8s-version=1.22
-- For 1.25 : --optional-values=k8s-version=1.25
-- For 1.26 : --optional-values=k8s-version=1.26
+be used to generate the report (by default 1.22), e.g. --optional-values=k8s-version=1.29
 `,
 		PreRunE: validation,
 		RunE:    run,
@@ -96,6 +94,10 @@ be used to generate the report, see:
 			"against an Kubernetes version that it is intended to be distributed use `--optional-values=k8s-version=1.22`")
 	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
 		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.RemovedAPIsFile, "removed-apis-file", "",
+		"path of a YAML file with the APIs removed per k8s version to use instead of the table embedded in audit")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
 	return cmd
 }
 
@@ -105,6 +107,17 @@ func validation(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	if version, ok := custom.Flags.OptionalValues[custom.K8sVersionKey]; ok {
+		if _, err := custom.NormalizeK8sVersion(version); err != nil {
+			return err
+		}
+	}
+	if len(custom.Flags.RemovedAPIsFile) > 0 {
+		return custom.LoadRemovedAPIsFile(custom.Flags.RemovedAPIsFile)
+	}
 	return nil
 }
 
@@ -116,10 +129,22 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	apiDashReport := custom.NewAPIDashReport(bundlesReport, custom.Flags.OptionalValues, custom.Flags.Filter)
+	apiDashReport, err := custom.NewAPIDashReport(bundlesReport, custom.Flags.OptionalValues, custom.Flags.Filter)
+	if err != nil {
+		return err
+	}
+
+	reportType := fmt.Sprintf("deprecate-apis-%s", apiDashReport.K8SVersion)
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(apiDashReport, apiDashReport.ImageName, reportType); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
 
 	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
-		pkg.GetReportName(apiDashReport.ImageName, fmt.Sprintf("deprecate-apis-%s", apiDashReport.K8SVersion), "html"))
+		pkg.GetReportName(apiDashReport.ImageName, reportType, "html"))
 
 	f, err := os.Create(dashOutputPath)
 	if err != nil {
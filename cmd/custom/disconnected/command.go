@@ -0,0 +1,133 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disconnected
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+//go:embed *.tmpl
+var disconnectedTemplate embed.FS
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disconnected",
+		Short: "generates a custom report with the images which need to be mirrored for the disconnected installs",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you are looking for to check if the head of the channels can be installed in disconnected
+clusters, which requires all images used by the Operator to be informed in the spec.relatedImages
+and pinned by digest.
+
+The images found in the containers, init containers and RELATED_IMAGE_* env vars of the
+deployments and in the alm-examples are compared with the spec.relatedImages:
+
+- the images not informed in the spec.relatedImages or not pinned by digest are errors when the
+Operator claims to support the disconnected mode (features.operators.openshift.io/disconnected)
+and warnings otherwise
+- the images informed in the spec.relatedImages but not used are warnings
+
+The report also shows per package the list of images to mirror (bundle and related images) and
+the ones which need to be added to the additionalImages of the oc-mirror ImageSetConfiguration
+since they are not informed in the spec.relatedImages.
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	disconnectedReport := custom.NewDisconnectedReport(bundlesReport, custom.Flags.Filter)
+
+	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(disconnectedReport, disconnectedReport.ImageName, "disconnected"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(disconnectedReport.ImageName, "disconnected", "html"))
+
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t := template.Must(template.ParseFS(disconnectedTemplate, "disconnected_template.go.tmpl"))
+	err = t.Execute(f, disconnectedReport)
+	if err != nil {
+		panic(err)
+	}
+
+	f.Close()
+	log.Infof("Operation completed.")
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+//go:embed *.tmpl
+var featuresTemplate embed.FS
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "features",
+		Short: "generates a custom report with the compliance of the packages with the OpenShift feature annotations",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you are looking for to check if the head of the channels inform the infrastructure features 
+supported on OpenShift via the annotations features.operators.openshift.io/<feature>:
+
+- disconnected, fips-compliant, proxy-aware, tls-profiles, token-auth-aws, token-auth-azure, 
+token-auth-gcp, cnf, cni and csi
+
+The following checks are done:
+
+- all annotations are informed and their values are the string "true" or "false"
+- the legacy annotation operators.openshift.io/infrastructure-features (JSON list), which was 
+replaced by them, is not the only one used and is consistent with them
+- the claims are consistent with the manifests: the deployments of proxy-aware Operators handle the 
+env vars HTTP_PROXY, HTTPS_PROXY and NO_PROXY and the Operators which support the disconnected mode 
+inform the relatedImages and pin the images by digest
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	featuresReport := custom.NewFeaturesReport(bundlesReport, custom.Flags.Filter)
+
+	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(featuresReport, featuresReport.ImageName, "features"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(featuresReport.ImageName, "features", "html"))
+
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t := template.Must(template.ParseFS(featuresTemplate, "features_template.go.tmpl"))
+	err = t.Execute(f, featuresReport)
+	if err != nil {
+		panic(err)
+	}
+
+	f.Close()
+	log.Infof("Operation completed.")
+
+	return nil
+}
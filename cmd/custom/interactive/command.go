@@ -0,0 +1,105 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interactive",
+		Short: "generates a self-contained HTML report to search, sort and filter the findings of the bundles",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to explore the findings of the bundles in the browser or to attach them to a ticket. The HTML
+file generated has the data of the report embedded and does not load any resource, so it works offline. It allows:
+
+- full-text search in all fields
+- sorting by any column
+- filtering by package, channel, head of channel, default channel, severity and finding type
+
+The same report can be generated directly with $audit index bundles --output=html.
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*")
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating output...")
+	formatter, err := bundles.GetFormatter(bundles.HTML)
+	if err != nil {
+		return err
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(bundlesReport.Flags.IndexImage, "interactive", formatter.Extension()))
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := formatter.Format(f, &bundlesReport); err != nil {
+		return err
+	}
+
+	log.Infof("Operation completed.")
+	return nil
+}
@@ -15,12 +15,21 @@
 package custom
 
 import (
+	"github.com/operator-framework/audit/cmd/custom/bundlesize"
 	"github.com/operator-framework/audit/cmd/custom/deprecate"
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/audit/cmd/custom/disconnected"
+	"github.com/operator-framework/audit/cmd/custom/features"
+	"github.com/operator-framework/audit/cmd/custom/images"
+	"github.com/operator-framework/audit/cmd/custom/interactive"
+	"github.com/operator-framework/audit/cmd/custom/maxocp"
 	"github.com/operator-framework/audit/cmd/custom/multiarch"
 	"github.com/operator-framework/audit/cmd/custom/qa"
+	"github.com/operator-framework/audit/cmd/custom/rbac"
+	"github.com/operator-framework/audit/cmd/custom/security"
 	"github.com/operator-framework/audit/cmd/custom/validator"
+	"github.com/operator-framework/audit/cmd/custom/vulnerabilities"
 )
 
 func NewCmd() *cobra.Command {
@@ -34,6 +43,15 @@ func NewCmd() *cobra.Command {
 		qa.NewCmd(),
 		multiarch.NewCmd(),
 		validator.NewCmd(),
+		bundlesize.NewCmd(),
+		security.NewCmd(),
+		rbac.NewCmd(),
+		interactive.NewCmd(),
+		features.NewCmd(),
+		disconnected.NewCmd(),
+		images.NewCmd(),
+		vulnerabilities.NewCmd(),
+		maxocp.NewCmd(),
 	)
 
 	return indexCmd
@@ -0,0 +1,118 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maxocp
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/alpha"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+//go:embed *.tmpl
+var maxOCPTemplate embed.FS
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maxocp",
+		Short: "generates a custom report with the packages which use the APIs removed in 1.22 without the max OCP version",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you are looking for to monitor what are the packages which have bundles using the APIs removed in
+Kubernetes 1.22 (OCP 4.9) that are not blocked to be installed on OCP 4.9 via the annotation
+olm.maxOpenShiftVersion. Bundles which are considered as deprecated are ignored from this report.
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	maxOCPReport := alpha.NewMaxDashReport(bundlesReport)
+
+	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(maxOCPReport, maxOCPReport.ImageName, "maxocp"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(maxOCPReport.ImageName, "maxocp", "html"))
+
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	t := template.Must(template.ParseFS(maxOCPTemplate, "maxocp_template.go.tmpl"))
+	if err := t.Execute(f, maxOCPReport); err != nil {
+		return err
+	}
+
+	log.Infof("Operation completed.")
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/reports/custom"
@@ -42,6 +43,26 @@ If you are looking for:
 support for Multiple Architectures.
 - verify what are the packages which has head of channels that probably are not
 providing a valid configuration for this criteria
+- verify what are the platforms provided by the manager, containers and related images
+of the head of channels
+- verify when a package gained or lost an arch in a channel (--scope=all-bundles)
+
+## What is checked?
+
+The manifest lists of the images referenced in the CSV (containers, init containers and RELATED_IMAGE_*
+env vars of the deployments, alm-examples and spec.relatedImages) are inspected with the container tool
+and their platforms are compared with the ones declared via the labels operatorframework.io/arch.<arch>
+and operatorframework.io/os.<os> of the CSV.
+The report shows a matrix with the archs provided by each image and:
+- errors when an image does not provide a declared arch or os, or it is not a manifest list
+- warnings when the manager image provides archs which are not declared via the labels,
+the node affinity of the deployments does not match the declared archs or an image cannot be
+inspected
+
+By default, only the heads of the default channels which are not deprecated are checked
+(--scope=default-head). Use --scope=all-heads to check the heads of all channels or
+--scope=all-bundles to check all bundles. The archs supported by each version are shown per
+channel.
 `,
 		PreRunE: validation,
 		RunE:    run,
@@ -63,10 +84,15 @@ providing a valid configuration for this criteria
 		"inform the path of the directory to output the report. (Default: current directory)")
 	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
 		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
 	cmd.Flags().StringVar(&custom.Flags.ContainerEngine, "container-engine", pkg.Docker,
 		fmt.Sprintf("specifies the container tool to use. If not set, the default value is docker. "+
 			"Note that you can use the environment variable CONTAINER_ENGINE to inform this option. "+
 			"[Options: %s and %s]", pkg.Docker, pkg.Podman))
+	cmd.Flags().StringVar(&custom.Flags.MultiarchScope, "scope", custom.MultiarchScopeDefaultHead,
+		fmt.Sprintf("specifies the bundles checked: the heads of the default channels which are not deprecated, "+
+			"the heads of all channels or all bundles. [Options: %s]", strings.Join(custom.MultiarchScopes(), ", ")))
 
 	return cmd
 }
@@ -86,6 +112,13 @@ func validation(cmd *cobra.Command, args []string) error {
 			" The valid options are %s and %s", custom.Flags.ContainerEngine, pkg.Docker, pkg.Podman)
 	}
 
+	if err := custom.ValidateMultiarchScope(); err != nil {
+		return err
+	}
+
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -100,9 +133,17 @@ func run(cmd *cobra.Command, args []string) error {
 	log.Info("Generating data...")
 
 	multiarchReport := custom.NewMultipleArchitecturesReport(bundlesReport, custom.Flags.Filter,
-		custom.Flags.ContainerEngine)
+		custom.Flags.ContainerEngine, custom.Flags.MultiarchScope)
 
 	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(multiarchReport, multiarchReport.ImageName, "multiarch"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
 	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
 		pkg.GetReportName(multiarchReport.ImageName, "multiarch", "html"))
 
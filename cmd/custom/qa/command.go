@@ -16,9 +16,11 @@ package qa
 
 import (
 	"embed"
+	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -39,6 +41,21 @@ func NewCmd() *cobra.Command {
 ## When should I use this command?
 
 If you are looking for to check the quality of the head of channels according to specific criteria.
+
+## How the packages are graded?
+
+The criteria, the weight of each check in the score of the packages, the channel prefixes allowed, 
+the annotations required and the score thresholds are defined in a profile. By default, the profile 
+embedded in audit (see pkg/reports/custom/qa_profile.yaml) is used, which reproduces the criteria 
+used for the Red Hat catalogs. Use --profile to inform a file with the same format to grade the 
+packages against the standards of other catalogs (e.g. community, certified or internal ones).
+
+The profile also defines which checks of the metadata of the CSVs (shown on OperatorHub) are done:
+the description (length and Markdown), the icon (presence, mediatype and size), the maintainers, 
+the links, the keywords, the minKubeVersion, the provider, the containerImage annotation (matches 
+the manager image), the createdAt annotation (format) and the repository annotation (URL). Each 
+check can be disabled by removing it from the metadata.checks of the profile. Note that the 
+description and the icon cannot be checked when the report was generated with --csv-detail=summary.
 `,
 		PreRunE: validation,
 		RunE:    run,
@@ -59,6 +76,12 @@ If you are looking for to check the quality of the head of channels according to
 		"inform the path of the directory to output the report. (Default: current directory)")
 	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
 		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.RemovedAPIsFile, "removed-apis-file", "",
+		"path of a YAML file with the APIs removed per k8s version to use instead of the table embedded in audit")
+	cmd.Flags().StringVar(&custom.Flags.QAProfileFile, "profile", "",
+		"path of a YAML file with the profile used to grade the packages instead of the one embedded in audit")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
 	return cmd
 }
 
@@ -68,6 +91,17 @@ func validation(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	if len(custom.Flags.QAProfileFile) > 0 {
+		if err := custom.LoadQAProfileFile(custom.Flags.QAProfileFile); err != nil {
+			return err
+		}
+	}
+	if len(custom.Flags.RemovedAPIsFile) > 0 {
+		return custom.LoadRemovedAPIsFile(custom.Flags.RemovedAPIsFile)
+	}
 	return nil
 }
 
@@ -81,6 +115,14 @@ func run(cmd *cobra.Command, args []string) error {
 
 	apiDashReport := custom.NewQAReport(bundlesReport, custom.Flags.Filter)
 
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(apiDashReport, apiDashReport.ImageName, "qa"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
 	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
 		pkg.GetReportName(apiDashReport.ImageName, "qa", "html"))
 
@@ -0,0 +1,143 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+//go:embed *.tmpl
+var rbacTemplate embed.FS
+
+var criteria = custom.RBACCriteria{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rbac",
+		Short: "generates a custom report with the risk of the RBAC permissions requested by the packages",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you are looking for to check what are the packages which request risky RBAC permissions. The permissions
+and cluster permissions defined in the CSV of the head of the channels are scored by:
+
+- cluster-admin equivalent permissions (all verbs on all resources of all apiGroups)
+- use of the verbs escalate, bind and impersonate
+- permissions to read secrets in all namespaces
+- use of wildcards for verbs and resources
+- use of the sensitive verbs on the sensitive resources
+
+The permissions granted in the namespace scope score the half of those granted in the cluster scope.
+
+Note that the wildcards also match the verbs and resources above, e.g. all verbs on all resources of the core
+apiGroup scores reading the secrets, impersonate and the sensitive resources of the core apiGroup.
+
+## How to inform the sensitive verbs and resources?
+
+Use the flags --sensitive-verbs and --sensitive-resources, e.g.:
+
+- --sensitive-resources=nodes,daemonsets --sensitive-verbs=create,update,patch
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&custom.Flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `index` sub-command as required")
+	}
+	cmd.Flags().StringVar(&custom.Flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
+	cmd.Flags().StringSliceVar(&criteria.SensitiveVerbs, "sensitive-verbs", custom.DefaultSensitiveVerbs,
+		"verbs which are considered sensitive when granted on the sensitive resources")
+	cmd.Flags().StringSliceVar(&criteria.SensitiveResources, "sensitive-resources",
+		custom.DefaultSensitiveResources, "resources which are considered sensitive")
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(custom.Flags.OutputPath) > 0 {
+		if _, err := os.Stat(custom.Flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	bundlesReport, err := custom.ParseBundlesJSONReport()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	rbacReport := custom.NewRBACReport(bundlesReport, criteria, custom.Flags.Filter)
+
+	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(rbacReport, rbacReport.ImageName, "rbac"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
+		pkg.GetReportName(rbacReport.ImageName, "rbac", "html"))
+
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t := template.Must(template.ParseFS(rbacTemplate, "rbac_template.go.tmpl"))
+	err = t.Execute(f, rbacReport)
+	if err != nil {
+		panic(err)
+	}
+
+	f.Close()
+	log.Infof("Operation completed.")
+
+	return nil
+}
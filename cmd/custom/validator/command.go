@@ -16,9 +16,11 @@ package validator
 
 import (
 	"embed"
+	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -60,6 +62,8 @@ If you are looking for to check all scenarios which fails with some specific war
 		"inform the path of the directory to output the report. (Default: current directory)")
 	cmd.Flags().StringVar(&custom.Flags.Filter, "filter", "",
 		"filter by the packages names which are like *filter*")
+	cmd.Flags().StringVar(&custom.Flags.OutputFormat, "output", custom.HTML,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(custom.OutputFormats(), ", ")))
 	cmd.Flags().StringVar(&FilterValidation, "filter-validation", "",
 		"filter by the error/warnings results which contain *filter-validation*")
 	if err := cmd.MarkFlagRequired("filter-validation"); err != nil {
@@ -74,6 +78,9 @@ func validation(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
+	if err := custom.ValidateOutputFormat(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -89,6 +96,14 @@ func run(cmd *cobra.Command, args []string) error {
 	validatorReport := custom.NewValidatorReport(bundlesReport, custom.Flags.Filter, FilterValidation)
 
 	log.Info("Generating output...")
+	if custom.Flags.OutputFormat != custom.HTML {
+		if err := custom.WriteReport(validatorReport, validatorReport.ImageName, "validator"); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
 	dashOutputPath := filepath.Join(custom.Flags.OutputPath,
 		pkg.GetReportName(validatorReport.ImageName, "validator", "html"))
 
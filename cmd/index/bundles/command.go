@@ -41,9 +41,15 @@ import (
 	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/models"
 	index "github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/reports/gate"
+	"github.com/operator-framework/audit/pkg/sbom"
+	auditvalidation "github.com/operator-framework/audit/pkg/validation"
 )
 
 var flags = index.BindFlags{}
+var failOn []string
+var thresholds []gate.Threshold
+var vulnerabilityDB *sbom.Database
 
 func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -61,7 +67,13 @@ By running this command audit tool will:
 - Download and extract all bundles files by using the operator bundle path which is stored in the index db  
 - Get the required data for the report from the operator bundle manifest files 
 - Use the [operator-framework/api][of-api] to execute the bundle validator checks
+- Compare the CRDs of each bundle with the CRDs of the bundle it replaces in the same channel (file-based catalogs only,
+not done with --head-only since the replaced bundles are not audited)
 - Use SDK tool to execute the Scorecard bundle checks
+- Check if the images referenced by the CSV exist in their registries and verify their signatures (optional,
+see --check-images and --signature-keys)
+- Match the packages of the SBOMs of the images referenced by the CSV against an offline vulnerability database
+(optional, see --vulnerability-db)
 - Output a report providing the information obtained and processed in JSON format.
 
 `,
@@ -83,10 +95,31 @@ By running this command audit tool will:
 	}
 	cmd.Flags().BoolVar(&flags.StaticCheckFIPSCompliance, "static-check-fips-compliance", false,
 		"If set, the tool will perform a static check for FIPS compliance on all bundle images.")
+	cmd.Flags().BoolVar(&flags.CheckImages, "check-images", false,
+		"if set, the images referenced by the CSV (deployments, RELATED_IMAGE_* env vars, alm-examples and "+
+			"relatedImages) are checked in their registries with skopeo, without pulling them, to obtain if they "+
+			"exist, their digest, size and creation date (not informed for the multi-arch images)")
+	cmd.Flags().StringSliceVar(&flags.SignatureKeys, "signature-keys", nil,
+		"paths of the cosign public keys used to verify the signatures of the images checked with --check-images. "+
+			"An image is verified when its signature is valid for any of the keys")
+	cmd.Flags().StringVar(&flags.VulnerabilityDB, "vulnerability-db", "",
+		"path of the offline vulnerability database (OSV records as a JSON array or one JSON record per line). "+
+			"If set, the packages of the SBOMs of the images referenced by the CSV are matched against it and the "+
+			"vulnerabilities found are summarised per bundle")
+	cmd.Flags().StringVar(&flags.SBOMDir, "sbom-dir", "",
+		"path of the directory with the SBOMs (SPDX or CycloneDX JSON) of the images checked with "+
+			"--vulnerability-db, named by the image with the characters /, : and @ replaced by _ "+
+			"(e.g. quay.io_org_name_sha256_<digest>.json)")
+	cmd.Flags().BoolVar(&flags.GenerateSBOMs, "generate-sboms", false,
+		"if set, the SBOMs which are not found in the --sbom-dir are generated with syft and stored in it")
 	cmd.Flags().StringVar(&flags.Filter, "filter", "",
 		"filter by the packages names which are like *filter*")
 	cmd.Flags().StringVar(&flags.OutputFormat, "output", pkg.JSON,
-		fmt.Sprintf("inform the output format. [Options: %s]", pkg.JSON))
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(index.OutputFormats(), ", ")))
+	cmd.Flags().StringVar(&flags.CSVDetail, "csv-detail", index.CSVDetailFull,
+		fmt.Sprintf("inform how much of the CSV is embedded in the report. Note that the custom dashboards "+
+			"cannot be generated without it. [Options: %s, %s, %s]",
+			index.CSVDetailFull, index.CSVDetailSummary, index.CSVDetailNone))
 	cmd.Flags().StringVar(&flags.OutputPath, "output-path", currentPath,
 		"inform the path of the directory to output the report. (Default: current directory)")
 	cmd.Flags().Int32Var(&flags.Limit, "limit", 0,
@@ -97,6 +130,8 @@ By running this command audit tool will:
 		"if set, will disable the scorecard tests")
 	cmd.Flags().BoolVar(&flags.DisableValidators, "disable-validators", false,
 		"if set, will disable the validators tests")
+	cmd.Flags().Float64Var(&flags.BundleSizeWarnPercent, "bundle-size-warn-percent", auditvalidation.DefaultWarnPercent,
+		"percentage of the max bundle size allowed from which the validator warns that the bundle is close to the limit")
 	cmd.Flags().StringVar(&flags.Label, "label", "",
 		"filter by bundles which has index images where contains *label*")
 	cmd.Flags().StringVar(&flags.LabelValue, "label-value", "",
@@ -109,24 +144,18 @@ By running this command audit tool will:
 		fmt.Sprintf("specifies the container tool to use. If not set, the default value is docker. "+
 			"Note that you can use the environment variable CONTAINER_ENGINE to inform this option. "+
 			"[Options: %s and %s]", pkg.Docker, pkg.Podman))
+	cmd.Flags().StringSliceVar(&failOn, "fail-on", nil,
+		fmt.Sprintf("exit with the code %d when the heads of the channels fail the checks informed, "+
+			"e.g. --fail-on=validator-errors,scorecard-failures=2,deprecated-apis=1.25. "+
+			"[Checks: %s]", pkg.ExitThresholdExceeded, strings.Join(gate.Checks(), ", ")))
 
 	return cmd
 }
 
-// CheckFIPSAnnotations searches for variants of the FIPS annotations.
+// CheckFIPSAnnotations checks if the CSV claims to be FIPS compliant via the annotation
+// features.operators.openshift.io/fips-compliant or the legacy operators.openshift.io/infrastructure-features.
 func CheckFIPSAnnotations(csv *v1alpha1.ClusterServiceVersion) (bool, error) {
-	fipsAnnotationPatterns := []string{
-		"features.operators.openshift.io/fips-compliant",
-		"operators.openshift.io/infrastructure-features",
-	}
-
-	for _, pattern := range fipsAnnotationPatterns {
-		if value, exists := csv.Annotations[pattern]; exists &&
-			(strings.Contains(value, "fips") || strings.Contains(value, "true")) {
-			return true, nil
-		}
-	}
-	return false, nil
+	return pkg.IsClaimingFeature(csv.Annotations, pkg.FeatureFIPSCompliant), nil
 }
 
 // ExtractUniqueImageReferences get a unique list of operator image and related images
@@ -300,9 +329,23 @@ func validation(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid value informed via the --limit flag :%v", flags.Limit)
 	}
 
-	if len(flags.OutputFormat) > 0 && flags.OutputFormat != pkg.JSON {
-		return fmt.Errorf("invalid value informed via the --output flag :%v. "+
-			"The available option is: %s", flags.OutputFormat, pkg.JSON)
+	if len(flags.OutputFormat) > 0 {
+		if _, err := index.GetFormatter(flags.OutputFormat); err != nil {
+			return fmt.Errorf("invalid value informed via the --output flag :%v. "+
+				"The available options are: %s", flags.OutputFormat, strings.Join(index.OutputFormats(), ", "))
+		}
+	}
+
+	if flags.BundleSizeWarnPercent <= 0 || flags.BundleSizeWarnPercent > 100 {
+		return fmt.Errorf("invalid value informed via the --bundle-size-warn-percent flag :%v. "+
+			"It should be a number between 1 and 100", flags.BundleSizeWarnPercent)
+	}
+
+	if len(flags.CSVDetail) > 0 && flags.CSVDetail != index.CSVDetailFull &&
+		flags.CSVDetail != index.CSVDetailSummary && flags.CSVDetail != index.CSVDetailNone {
+		return fmt.Errorf("invalid value informed via the --csv-detail flag :%v. "+
+			"The available options are: %s, %s and %s", flags.CSVDetail, index.CSVDetailFull,
+			index.CSVDetailSummary, index.CSVDetailNone)
 	}
 
 	if len(flags.OutputPath) > 0 {
@@ -328,6 +371,35 @@ func validation(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if flags.CheckImages && !pkg.HasSkopeoInstalled() {
+		return errors.New("the images are checked with skopeo which was not found. " +
+			"Please, ensure that you have skopeo installed or do not use the flag --check-images")
+	}
+	if len(flags.SignatureKeys) > 0 {
+		if !flags.CheckImages {
+			return errors.New("the signatures are only verified when the images are checked, " +
+				"inform the flag --check-images")
+		}
+		if !pkg.HasCosignInstalled() {
+			return errors.New("the signatures are verified with cosign which was not found. " +
+				"Please, ensure that you have cosign installed or do not use the flag --signature-keys")
+		}
+		for _, key := range flags.SignatureKeys {
+			if _, err := os.Stat(key); err != nil {
+				return fmt.Errorf("invalid value informed via the --signature-keys flag: %s", err)
+			}
+		}
+	}
+
+	if err := validateVulnerabilityFlags(); err != nil {
+		return err
+	}
+
+	var err error
+	if thresholds, err = gate.ParseThresholds(failOn); err != nil {
+		return err
+	}
+
 	if len(flags.ContainerEngine) == 0 {
 		flags.ContainerEngine = pkg.GetContainerToolFromEnvVar()
 	}
@@ -339,11 +411,45 @@ func validation(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateVulnerabilityFlags checks the flags used to match the SBOMs of the images against the vulnerability
+// database and loads it
+func validateVulnerabilityFlags() error {
+	if len(flags.VulnerabilityDB) == 0 {
+		if len(flags.SBOMDir) > 0 || flags.GenerateSBOMs {
+			return errors.New("the SBOMs are only used to check the vulnerabilities, " +
+				"inform the flag --vulnerability-db")
+		}
+		return nil
+	}
+	if len(flags.SBOMDir) == 0 {
+		return errors.New("inform the directory with the SBOMs of the images via the --sbom-dir flag")
+	}
+	if info, err := os.Stat(flags.SBOMDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("invalid value informed via the --sbom-dir flag: %s is not a directory", flags.SBOMDir)
+	}
+	if flags.GenerateSBOMs && !pkg.HasSyftInstalled() {
+		return errors.New("the SBOMs are generated with syft which was not found. " +
+			"Please, ensure that you have syft installed or do not use the flag --generate-sboms")
+	}
+
+	var err error
+	if vulnerabilityDB, err = sbom.LoadDatabase(flags.VulnerabilityDB); err != nil {
+		return fmt.Errorf("invalid value informed via the --vulnerability-db flag: %s", err)
+	}
+	log.Infof("%d vulnerabilities loaded from %s", vulnerabilityDB.Records, flags.VulnerabilityDB)
+	return nil
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	log.Info("Starting audit...")
 
 	reportData := index.Data{}
 	reportData.Flags = flags
+	var evaluator *gate.Evaluator
+	if len(thresholds) > 0 {
+		evaluator = gate.NewEvaluator(thresholds, flags.ContainerEngine)
+		reportData.OnColumn = evaluator.Add
+	}
 	pkg.GenerateTemporaryDirs()
 
 	// to fix common possible typo issue
@@ -364,19 +470,45 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// the JSON report is written to the disk as the bundles are audited
+	if reportData.Flags.OutputFormat == pkg.JSON {
+		if err := reportData.StartStream(); err != nil {
+			return err
+		}
+	}
+
 	log.Info("Gathering data...")
 
 	// check here to see if it's index.db or file-based catalogs
 	if IsFBC(flags.IndexImage) {
-		reportData, _ = GetDataFromFBC(reportData)
+		reportData, err = GetDataFromFBC(reportData)
 	} else {
-		reportData, _ = GetDataFromIndexDB(reportData)
+		reportData, err = GetDataFromIndexDB(reportData)
 	}
-	if err := reportData.OutputReport(); err != nil {
+	if err != nil {
+		// the partial report is removed so that it is not taken as the report of the index
+		if errAbort := reportData.AbortStream(); errAbort != nil {
+			log.Errorf("unable to remove the partial report: %s", errAbort)
+		}
+		pkg.CleanupTemporaryDirs()
 		return err
 	}
-
+	err = reportData.OutputReport()
 	pkg.CleanupTemporaryDirs()
+	if err != nil {
+		return err
+	}
+
+	if evaluator != nil {
+		result := evaluator.Result(flags.IndexImage)
+		if err := result.WriteSummary(cmd.OutOrStdout()); err != nil {
+			return err
+		}
+		if err := result.Err(); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
 	log.Info("Operation completed.")
 	return nil
 }
@@ -437,6 +569,11 @@ func GetDataFromFBC(report index.Data) (index.Data, error) {
 		return report, fmt.Errorf("unable to file based config to internal model: %s", err)
 	}
 
+	if flags.HeadOnly && !flags.DisableValidators {
+		log.Warn("the CRDs upgrade check is skipped since the bundles replaced by the heads of the channels " +
+			"are not audited with --head-only")
+	}
+
 	const maxConcurrency = 4
 	packageChan := make(chan *alphamodel.Package, maxConcurrency)
 	resultsChan := make(chan *index.Data, maxConcurrency)
@@ -463,11 +600,17 @@ func GetDataFromFBC(report index.Data) (index.Data, error) {
 	}()
 
 	// Collect results
+	var errAdd error
 	for result := range resultsChan {
-		report.AuditBundle = append(report.AuditBundle, result.AuditBundle...)
+		for _, auditBundle := range result.AuditBundle {
+			if err := report.AddAuditBundle(auditBundle); err != nil && errAdd == nil {
+				errAdd = fmt.Errorf("unable to add the bundle %s to the report: %s",
+					auditBundle.OperatorBundleName, err)
+			}
+		}
 	}
 
-	return report, nil
+	return report, errAdd
 }
 
 func packageWorker(packageChan <-chan *alphamodel.Package, resultsChan chan<- *index.Data, wg *sync.WaitGroup) {
@@ -475,82 +618,147 @@ func packageWorker(packageChan <-chan *alphamodel.Package, resultsChan chan<- *i
 	for Package := range packageChan {
 		// Initialize a local variable to store results for this package
 		var result index.Data
+		for _, auditBundle := range auditPackage(Package, auditFBCBundle) {
+			result.AuditBundle = append(result.AuditBundle, *auditBundle)
+		}
+
+		// Send the result to the results channel
+		resultsChan <- &result
+	}
+}
 
-		// Iterate over the channels in the package
-		for _, channel := range Package.Channels {
-			headBundle, err := channel.Head()
-			if err != nil {
+// auditPackage returns the bundles of the channels of the package audited with the func informed. A bundle can be
+// in more than one channel, so it is audited only once and the report has one column per bundle with all channels
+// that it belongs to. It is the head of channel when it is the head of any of them, such as it is done when the
+// data is gathered from the index.db.
+func auditPackage(Package *alphamodel.Package,
+	audit func(*alphamodel.Package, *alphamodel.Bundle) *models.AuditBundle) []*models.AuditBundle {
+	auditedBundles := make(map[string]*models.AuditBundle)
+	var result []*models.AuditBundle
+	upgradesChecked := make(map[string]bool)
+
+	// Iterate over the channels in the package
+	for _, channelName := range pkg.SortedKeys(Package.Channels) {
+		channel := Package.Channels[channelName]
+		headBundle, err := channel.Head()
+		if err != nil {
+			continue
+		}
+
+		// store the bundles audited in the channel so that we can compare them with the bundles they replace
+		var channelBundles []*models.AuditBundle
+		replacesPerBundle := make(map[string]string)
+
+		for _, bundleName := range pkg.SortedKeys(channel.Bundles) {
+			bundle := channel.Bundles[bundleName]
+			isHead := headBundle == bundle
+			if !isHead && flags.HeadOnly {
 				continue
 			}
 
-			for _, bundle := range channel.Bundles {
-				auditBundle := models.NewAuditBundle(bundle.Name, bundle.Image)
-				if headBundle == bundle {
-					auditBundle.IsHeadOfChannel = true
-				} else {
-					if flags.HeadOnly {
-						continue
-					}
-				}
+			auditBundle, found := auditedBundles[bundle.Name]
+			if !found {
+				auditBundle = audit(Package, bundle)
+				auditedBundles[bundle.Name] = auditBundle
+				result = append(result, auditBundle)
+			}
+			auditBundle.Channels = append(auditBundle.Channels, channel.Name)
+			if isHead {
+				auditBundle.IsHeadOfChannel = true
+			}
 
-				log.Infof("Generating data from the bundle (%s)", bundle.Name)
-				var csv *v1alpha1.ClusterServiceVersion
-				err := json.Unmarshal([]byte(bundle.CsvJSON), &csv)
-				if err == nil {
-					auditBundle.CSVFromIndexDB = csv
-				} else {
-					auditBundle.Errors = append(auditBundle.Errors,
-						fmt.Errorf("unable to parse the csv from the index.db: %s", err).Error())
-				}
+			channelBundles = append(channelBundles, auditBundle)
+			replacesPerBundle[bundle.Name] = bundle.Replaces
+		}
 
-				// Call GetDataFromBundleImage
-				auditBundle = actions.GetDataFromBundleImage(auditBundle, flags.DisableScorecard,
-					flags.DisableValidators, flags.ServerMode, flags.Label,
-					flags.LabelValue, flags.ContainerEngine, flags.IndexImage)
+		if !flags.DisableValidators {
+			checkCRDUpgrades(channel.Name, channelBundles, replacesPerBundle, upgradesChecked)
+		}
+	}
+	return result
+}
 
-				// Extra inner loop for channels
-				for _, channel := range Package.Channels {
-					auditBundle.Channels = append(auditBundle.Channels, channel.Name)
-				}
+// auditFBCBundle gathers the data of the bundle of the package from its image and from the FBC
+func auditFBCBundle(Package *alphamodel.Package, bundle *alphamodel.Bundle) *models.AuditBundle {
+	auditBundle := models.NewAuditBundle(bundle.Name, bundle.Image)
+
+	log.Infof("Generating data from the bundle (%s)", bundle.Name)
+	var csv *v1alpha1.ClusterServiceVersion
+	err := json.Unmarshal([]byte(bundle.CsvJSON), &csv)
+	if err == nil {
+		auditBundle.CSVFromIndexDB = csv
+	} else {
+		auditBundle.Errors = append(auditBundle.Errors,
+			fmt.Errorf("unable to parse the csv from the index.db: %s", err).Error())
+	}
 
-				auditBundle.PackageName = Package.Name
-				auditBundle.DefaultChannel = Package.DefaultChannel.Name
+	// Call GetDataFromBundleImage
+	auditBundle = actions.GetDataFromBundleImage(auditBundle, flags.DisableScorecard,
+		flags.DisableValidators, flags.ServerMode, flags.Label,
+		flags.LabelValue, flags.ContainerEngine, flags.IndexImage, flags.BundleSizeWarnPercent)
 
-				// Collect properties not found in the index version
-				for _, property := range bundle.Properties {
-					auditBundle.PropertiesDB = append(auditBundle.PropertiesDB,
-						pkg.PropertiesAnnotation{Type: property.Type, Value: string(property.Value)})
-				}
-				headBundle, err := channel.Head()
-				if err == nil {
-					if headBundle == bundle {
-						auditBundle.IsHeadOfChannel = true
-					}
-				}
-				if flags.StaticCheckFIPSCompliance {
-					err = handleFIPS(auditBundle.OperatorBundleImagePath, csv, auditBundle)
-					if err != nil {
-						// Check for specific error types and provide more informative messages
-						if exitError, ok := err.(*exec.ExitError); ok {
-							if exitError.ExitCode() == 127 {
-								auditBundle.Errors = append(auditBundle.Errors,
-									"Failed to run FIPS external validator: Command not found.")
-							} else {
-								auditBundle.Errors = append(auditBundle.Errors,
-									fmt.Sprintf("FIPS external validator returned with exit code %d.", exitError.ExitCode()))
-							}
-						} else {
-							auditBundle.Errors = append(auditBundle.Errors,
-								fmt.Sprintf("Difficulty running FIPS external validator: %s", err.Error()))
-						}
-					}
+	auditBundle.PackageName = Package.Name
+	auditBundle.DefaultChannel = Package.DefaultChannel.Name
+
+	// Collect properties not found in the index version
+	for _, property := range bundle.Properties {
+		auditBundle.PropertiesDB = append(auditBundle.PropertiesDB,
+			pkg.PropertiesAnnotation{Type: property.Type, Value: string(property.Value)})
+	}
+	if flags.CheckImages {
+		actions.CheckImageReferences(auditBundle, flags.SignatureKeys)
+	}
+	if vulnerabilityDB != nil {
+		actions.CheckVulnerabilities(auditBundle, vulnerabilityDB, flags.SBOMDir, flags.GenerateSBOMs)
+	}
+	if flags.StaticCheckFIPSCompliance {
+		err = handleFIPS(auditBundle.OperatorBundleImagePath, csv, auditBundle)
+		if err != nil {
+			// Check for specific error types and provide more informative messages
+			if exitError, ok := err.(*exec.ExitError); ok {
+				if exitError.ExitCode() == 127 {
+					auditBundle.Errors = append(auditBundle.Errors,
+						"Failed to run FIPS external validator: Command not found.")
+				} else {
+					auditBundle.Errors = append(auditBundle.Errors,
+						fmt.Sprintf("FIPS external validator returned with exit code %d.", exitError.ExitCode()))
 				}
-				result.AuditBundle = append(result.AuditBundle, *auditBundle)
+			} else {
+				auditBundle.Errors = append(auditBundle.Errors,
+					fmt.Sprintf("Difficulty running FIPS external validator: %s", err.Error()))
 			}
 		}
+	}
+	return auditBundle
+}
 
-		// Send the result to the results channel
-		resultsChan <- &result
+// checkCRDUpgrades compares the CRDs of each bundle with the CRDs of the bundle that it replaces in the channel.
+// The upgrades found in the checked map are skipped since the same upgrade can be in more than one channel.
+func checkCRDUpgrades(channelName string, channelBundles []*models.AuditBundle, replacesPerBundle map[string]string,
+	checked map[string]bool) {
+	bundlesByName := make(map[string]*models.AuditBundle)
+	for _, auditBundle := range channelBundles {
+		bundlesByName[auditBundle.OperatorBundleName] = auditBundle
+	}
+
+	for _, auditBundle := range channelBundles {
+		replacesName := replacesPerBundle[auditBundle.OperatorBundleName]
+		replaces, found := bundlesByName[replacesName]
+		if !found {
+			if len(replacesName) > 0 && !flags.HeadOnly {
+				log.Infof("Skipping the CRDs upgrade check of the bundle (%s) since the bundle it replaces (%s) "+
+					"was not audited", auditBundle.OperatorBundleName, replacesName)
+			}
+			continue
+		}
+		upgrade := replaces.OperatorBundleName + "/" + auditBundle.OperatorBundleName
+		if checked[upgrade] {
+			continue
+		}
+		checked[upgrade] = true
+		log.Infof("Checking the CRDs upgrade from the bundle (%s) to (%s)",
+			replaces.OperatorBundleName, auditBundle.OperatorBundleName)
+		actions.RunCRDUpgradeValidator(auditBundle, replaces, channelName)
 	}
 }
 
@@ -601,7 +809,8 @@ func GetDataFromIndexDB(report index.Data) (index.Data, error) {
 
 		auditBundle = actions.GetDataFromBundleImage(auditBundle, report.Flags.DisableScorecard,
 			report.Flags.DisableValidators, report.Flags.ServerMode, report.Flags.Label,
-			report.Flags.LabelValue, flags.ContainerEngine, report.Flags.IndexImage)
+			report.Flags.LabelValue, flags.ContainerEngine, report.Flags.IndexImage,
+			report.Flags.BundleSizeWarnPercent)
 
 		sqlString := fmt.Sprintf("SELECT c.channel_name, c.package_name FROM channel_entry c "+
 			"where c.operatorbundle_name = '%s'", auditBundle.OperatorBundleName)
@@ -673,7 +882,18 @@ func GetDataFromIndexDB(report index.Data) (index.Data, error) {
 			auditBundle.IsHeadOfChannel = found > 0
 		}
 
-		report.AuditBundle = append(report.AuditBundle, *auditBundle)
+		if report.Flags.CheckImages {
+			actions.CheckImageReferences(auditBundle, report.Flags.SignatureKeys)
+		}
+		if vulnerabilityDB != nil {
+			actions.CheckVulnerabilities(auditBundle, vulnerabilityDB, report.Flags.SBOMDir,
+				report.Flags.GenerateSBOMs)
+		}
+
+		if err := report.AddAuditBundle(*auditBundle); err != nil {
+			return report, fmt.Errorf("unable to add the bundle %s to the report: %s",
+				auditBundle.OperatorBundleName, err)
+		}
 	}
 
 	return report, nil
@@ -0,0 +1,92 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	alphamodel "github.com/operator-framework/operator-registry/alpha/model"
+
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+// newChannel returns the channel of the package with the bundles informed as name=replaces
+func newChannel(p *alphamodel.Package, name string, bundles ...string) *alphamodel.Channel {
+	channel := &alphamodel.Channel{Package: p, Name: name, Bundles: map[string]*alphamodel.Bundle{}}
+	for _, b := range bundles {
+		values := strings.Split(b, "=")
+		channel.Bundles[values[0]] = &alphamodel.Bundle{Package: p, Channel: channel, Name: values[0],
+			Image: "quay.io/example/" + values[0], Replaces: values[1]}
+	}
+	return channel
+}
+
+func TestAuditPackage(t *testing.T) {
+	defer func(headOnly, disableValidators bool) {
+		flags.HeadOnly = headOnly
+		flags.DisableValidators = disableValidators
+	}(flags.HeadOnly, flags.DisableValidators)
+	flags.DisableValidators = true
+
+	p := &alphamodel.Package{Name: "foo"}
+	p.Channels = map[string]*alphamodel.Channel{
+		"stable": newChannel(p, "stable", "foo.v1=", "foo.v2=foo.v1"),
+		"fast":   newChannel(p, "fast", "foo.v1=", "foo.v2=foo.v1", "foo.v3=foo.v2"),
+		"alpha":  newChannel(p, "alpha", "foo.v2="),
+	}
+	p.DefaultChannel = p.Channels["stable"]
+
+	tests := []struct {
+		name     string
+		headOnly bool
+		want     []string
+	}{
+		{
+			name: "should audit each bundle once with all channels that it belongs to",
+			want: []string{"foo.v2 alpha,fast,stable true", "foo.v1 fast,stable false", "foo.v3 fast true"},
+		},
+		{
+			name:     "should only have the channels where the bundle is the head with head only",
+			headOnly: true,
+			want:     []string{"foo.v2 alpha,stable true", "foo.v3 fast true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags.HeadOnly = tt.headOnly
+			audited := map[string]int{}
+			audit := func(_ *alphamodel.Package, bundle *alphamodel.Bundle) *models.AuditBundle {
+				audited[bundle.Name]++
+				return models.NewAuditBundle(bundle.Name, bundle.Image)
+			}
+
+			var got []string
+			for _, b := range auditPackage(p, audit) {
+				got = append(got, b.OperatorBundleName+" "+strings.Join(b.Channels, ",")+" "+
+					strconv.FormatBool(b.IsHeadOfChannel))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("auditPackage() got = %v, want %v", got, tt.want)
+			}
+			for name, count := range audited {
+				if count != 1 {
+					t.Errorf("the bundle %s was audited %d times", name, count)
+				}
+			}
+		})
+	}
+}
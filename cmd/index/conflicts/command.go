@@ -0,0 +1,231 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conflicts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/cmd/index/bundles"
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/actions"
+	index "github.com/operator-framework/audit/pkg/reports/conflicts"
+)
+
+var flags = index.BindFlags{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "audit the index catalog image for APIs owned by more than one package",
+		Long: `Checks the APIs owned and required by the head bundles of every channel in the index catalog.
+
+## When should I use it?
+
+OLM does not allow two operators owning the same CRD to be installed in the same cluster.
+By running this command audit tool will:
+
+- Extract the index catalog (file-based or sqlite)
+- Build a map with the owner packages of each CRD and APIService provided by the head bundles of all channels
+- Report the packages which own the same API (CRD, APIService or both)
+- Report the packages which require an API owned by another package that does not provide the version required
+- Output a report providing the information obtained and processed in JSON format.
+`,
+		PreRunE: validation,
+		RunE:    run,
+	}
+
+	cmd.Flags().StringVar(&flags.IndexImage, "index-image", "",
+		"index image and tag which will be audit")
+	if err := cmd.MarkFlagRequired("index-image"); err != nil {
+		log.Fatalf("Failed to mark `index-image` flag for `conflicts` sub-command as required")
+	}
+
+	cmd.Flags().StringVar(&flags.Filter, "filter", "",
+		"filter by the packages names which are like *filter*. The packages are still checked against all "+
+			"packages of the catalog and only the results which involve them are reported")
+	cmd.Flags().StringVar(&flags.OutputFormat, "output", pkg.JSON,
+		fmt.Sprintf("inform the output format. [Options: %s]", pkg.JSON))
+	cmd.Flags().StringVar(&flags.OutputPath, "output-path", "",
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&flags.ContainerEngine, "container-engine", pkg.Docker,
+		fmt.Sprintf("specifies the container tool to use. If not set, the default value is docker. "+
+			"Note that you can use the environment variable CONTAINER_ENGINE to inform this option. "+
+			"[Options: %s and %s]", pkg.Docker, pkg.Podman))
+
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+
+	if len(flags.OutputFormat) > 0 && flags.OutputFormat != pkg.JSON {
+		return fmt.Errorf("invalid value informed via the --output flag :%v. "+
+			"The available option is: %s", flags.OutputFormat, pkg.JSON)
+	}
+
+	if len(flags.OutputPath) > 0 {
+		if _, err := os.Stat(flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if len(flags.ContainerEngine) == 0 {
+		flags.ContainerEngine = pkg.GetContainerToolFromEnvVar()
+	}
+	if flags.ContainerEngine != pkg.Docker && flags.ContainerEngine != pkg.Podman {
+		return fmt.Errorf("invalid value for the flag --container-engine (%s)."+
+			" The valid options are %s and %s", flags.ContainerEngine, pkg.Docker, pkg.Podman)
+	}
+
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting audit...")
+
+	pkg.GenerateTemporaryDirs()
+
+	// to fix common possible typo issue
+	flags.Filter = strings.ReplaceAll(flags.Filter, "”", "")
+
+	if err := actions.DownloadImage(flags.IndexImage, flags.ContainerEngine); err != nil {
+		return err
+	}
+
+	inspect, err := pkg.RunDockerInspect(flags.IndexImage, flags.ContainerEngine)
+	if err != nil {
+		log.Errorf("unable to inspect the index image: %s", err)
+	}
+
+	if err := actions.ExtractIndexDBorCatalogs(flags.IndexImage, flags.ContainerEngine); err != nil {
+		return err
+	}
+
+	log.Info("Gathering data...")
+
+	var heads []index.HeadBundle
+	if bundles.IsFBC(flags.IndexImage) {
+		heads, err = getHeadBundlesFromFBC()
+	} else {
+		heads, err = getHeadBundlesFromIndexDB()
+	}
+	if err != nil {
+		return err
+	}
+
+	report := index.NewReport(flags, inspect, heads)
+	if err := report.OutputReport(); err != nil {
+		return err
+	}
+
+	pkg.CleanupTemporaryDirs()
+	log.Info("Operation completed.")
+	return nil
+}
+
+func getHeadBundlesFromFBC() ([]index.HeadBundle, error) {
+	root := "./output/" + actions.GetVersionTagFromImage(flags.IndexImage) + "/configs"
+	fbc, err := declcfg.LoadFS(context.Background(), os.DirFS(root))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the file based config : %s", err)
+	}
+	model, err := declcfg.ConvertToModel(*fbc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to file based config to internal model: %s", err)
+	}
+
+	var heads []index.HeadBundle
+	for _, Package := range model {
+		for _, Channel := range Package.Channels {
+			headBundle, err := Channel.Head()
+			if err != nil {
+				log.Errorf("unable to get the head of the channel %s of the package %s: %s",
+					Channel.Name, Package.Name, err)
+				continue
+			}
+
+			var csv v1alpha1.ClusterServiceVersion
+			if len(headBundle.CsvJSON) > 0 {
+				if err := json.Unmarshal([]byte(headBundle.CsvJSON), &csv); err != nil {
+					log.Errorf("unable to parse the csv of the bundle %s: %s", headBundle.Name, err)
+					continue
+				}
+			} else {
+				// the csv is no longer stored in the catalogs which uses the olm.csv.metadata property
+				props, err := property.Parse(headBundle.Properties)
+				if err != nil {
+					log.Errorf("unable to parse the properties of the bundle %s: %s", headBundle.Name, err)
+					continue
+				}
+				if len(props.CSVMetadatas) > 0 {
+					csv.Spec.CustomResourceDefinitions = props.CSVMetadatas[0].CustomResourceDefinitions
+					csv.Spec.APIServiceDefinitions = props.CSVMetadatas[0].APIServiceDefinitions
+				}
+			}
+
+			heads = append(heads, index.NewHeadBundle(Package.Name, headBundle.Name, Channel.Name,
+				csv.Spec.CustomResourceDefinitions, csv.Spec.APIServiceDefinitions))
+		}
+	}
+	return heads, nil
+}
+
+func getHeadBundlesFromIndexDB() ([]index.HeadBundle, error) {
+	db, err := sql.Open("sqlite3", "./output/"+actions.GetVersionTagFromImage(flags.IndexImage)+"/index.db")
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect in to the database : %s", err)
+	}
+	defer db.Close()
+
+	query := `SELECT c.package_name, c.name, o.name, o.csv FROM channel c 
+		JOIN operatorbundle o ON o.name = c.head_operatorbundle_name`
+	row, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query the index db : %s", err)
+	}
+	defer row.Close()
+
+	var heads []index.HeadBundle
+	for row.Next() {
+		var packageName, channelName, bundleName string
+		var csvStruct sql.NullString
+		if err := row.Scan(&packageName, &channelName, &bundleName, &csvStruct); err != nil {
+			log.Errorf("unable to scan data from index %s\n", err.Error())
+			continue
+		}
+		var csv v1alpha1.ClusterServiceVersion
+		if csvStruct.Valid && len(csvStruct.String) > 0 {
+			if err := json.Unmarshal([]byte(csvStruct.String), &csv); err != nil {
+				log.Errorf("unable to parse the csv of the bundle %s: %s", bundleName, err)
+				continue
+			}
+		}
+
+		heads = append(heads, index.NewHeadBundle(packageName, bundleName, channelName,
+			csv.Spec.CustomResourceDefinitions, csv.Spec.APIServiceDefinitions))
+	}
+	return heads, nil
+}
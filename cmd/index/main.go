@@ -16,6 +16,7 @@ package index
 
 import (
 	"github.com/operator-framework/audit/cmd/index/bundles"
+	"github.com/operator-framework/audit/cmd/index/conflicts"
 	"github.com/operator-framework/audit/cmd/index/eus"
 	"github.com/operator-framework/audit/cmd/index/np"
 	"github.com/spf13/cobra"
@@ -37,6 +38,9 @@ func NewCmd() *cobra.Command {
 	indexCmd.AddCommand(
 		np.NewCmd(),
 	)
+	indexCmd.AddCommand(
+		conflicts.NewCmd(),
+	)
 
 	return indexCmd
 
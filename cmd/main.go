@@ -15,10 +15,13 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/operator-framework/audit/cmd/custom"
 	"github.com/operator-framework/audit/cmd/index"
+	"github.com/operator-framework/audit/cmd/report"
+	"github.com/operator-framework/audit/cmd/site"
+	"github.com/operator-framework/audit/pkg"
 
 	"github.com/spf13/cobra"
 )
@@ -35,8 +38,11 @@ func main() {
 
 	rootCmd.AddCommand(index.NewCmd())
 	rootCmd.AddCommand(custom.NewCmd())
+	rootCmd.AddCommand(report.NewCmd())
+	rootCmd.AddCommand(site.NewCmd())
 
+	// the error is output by cobra
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		os.Exit(pkg.ExitCode(err))
 	}
 }
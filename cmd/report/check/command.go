@@ -0,0 +1,128 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package check
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/reports/gate"
+)
+
+// BindFlags define the flags used to check the report
+type BindFlags struct {
+	File            string
+	FailOn          []string
+	ContainerEngine string
+}
+
+var flags = BindFlags{}
+
+var thresholds []gate.Threshold
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "checks the bundles of the JSON bundles report against thresholds to block pipelines",
+		Long: fmt.Sprintf(`use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to block the merges of an operator repository or of a catalog on the results of the audit.
+The heads of the channels which are not deprecated are checked against the thresholds informed with --fail-on
+and a summary is output. The same checks can be done when the report is generated with
+$audit index bundles --fail-on.
+
+## Checks
+
+- %s: the validators return errors
+- %s: the validators return warnings
+- %s: scorecard tests are failing
+- %s=<k8s version>: APIs removed in the k8s version are used (by default 1.22)
+- %s: the multiarch checks return errors for the head of the default channel (the images are inspected)
+
+By default, the threshold is exceeded when any bundle fails the check. Use <check>=<max> to allow max bundles
+failing it, e.g. --fail-on=validator-warnings=10.
+
+## Exit codes
+
+- %d: no threshold was exceeded
+- %d: the command failed, e.g. the report could not be read
+- %d: at least one threshold was exceeded
+- %d: no bundle was found in the report
+`, gate.ValidatorErrors, gate.ValidatorWarnings, gate.ScorecardFailures, gate.DeprecatedAPIs,
+			gate.MultiarchErrors, pkg.ExitOK, pkg.ExitFailure, pkg.ExitThresholdExceeded, pkg.ExitNoData),
+		PreRunE:      validation,
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVar(&flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `check` sub-command as required")
+	}
+	cmd.Flags().StringSliceVar(&flags.FailOn, "fail-on", nil,
+		fmt.Sprintf("checks which should not fail, e.g. --fail-on=validator-errors,deprecated-apis=1.25. "+
+			"[Checks: %s]", strings.Join(gate.Checks(), ", ")))
+	if err := cmd.MarkFlagRequired("fail-on"); err != nil {
+		log.Fatalf("Failed to mark `fail-on` flag for `check` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.ContainerEngine, "container-engine", pkg.Docker,
+		fmt.Sprintf("specifies the container tool used to inspect the images for the check %s. "+
+			"[Options: %s and %s]", gate.MultiarchErrors, pkg.Docker, pkg.Podman))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	var err error
+	if thresholds, err = gate.ParseThresholds(flags.FailOn); err != nil {
+		return err
+	}
+	if flags.ContainerEngine != pkg.Docker && flags.ContainerEngine != pkg.Podman {
+		return fmt.Errorf("invalid value for the flag --container-engine (%s)."+
+			" The valid options are %s and %s", flags.ContainerEngine, pkg.Docker, pkg.Podman)
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(flags.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	evaluator := gate.NewEvaluator(thresholds, flags.ContainerEngine)
+	report, err := bundles.DecodeReport(f, evaluator.Add)
+	if err != nil {
+		return fmt.Errorf("unable to read the report %s: %s", flags.File, err)
+	}
+
+	result := evaluator.Result(report.Flags.IndexImage)
+	if result.Bundles == 0 {
+		return bundles.ErrNoData
+	}
+	if err := result.WriteSummary(cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	return result.Err()
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/diff"
+)
+
+//go:embed *.tmpl
+var diffTemplate embed.FS
+
+const html = "html"
+
+// BindFlags define the flags used to compare the reports
+type BindFlags struct {
+	From         string
+	To           string
+	OutputPath   string
+	OutputFormat string
+}
+
+var flags = BindFlags{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "compares two JSON bundles reports and shows what has changed",
+		Long: `use this command with two results of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to know what has changed between two builds of an index image. The report generated with
+--from is compared with the report generated with --to and the following changes are shown:
+
+- packages added and removed
+- bundles added and removed
+- bundles which became head of a channel
+- default channel changes
+- maxOCPVersion changes
+- bundles which became deprecated
+- new and resolved validator errors, validator warnings and failing scorecard tests
+
+## Example
+
+$ audit-tool report diff --from=bundles_index_v4.8.json --to=bundles_index_v4.9.json --output=json
+`,
+		PreRunE:      validation,
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringVar(&flags.From, "from", "",
+		"path of the JSON bundles report used as the base of the comparison")
+	if err := cmd.MarkFlagRequired("from"); err != nil {
+		log.Fatalf("Failed to mark `from` flag for `diff` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.To, "to", "",
+		"path of the JSON bundles report compared with the base")
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		log.Fatalf("Failed to mark `to` flag for `diff` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the report. (Default: current directory)")
+	cmd.Flags().StringVar(&flags.OutputFormat, "output", html,
+		fmt.Sprintf("inform the output format. [Options: %s, %s]", html, pkg.JSON))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(flags.OutputPath) > 0 {
+		if _, err := os.Stat(flags.OutputPath); os.IsNotExist(err) {
+			return err
+		}
+	}
+	if flags.OutputFormat != html && flags.OutputFormat != pkg.JSON {
+		return fmt.Errorf("invalid value informed via the --output flag :%v. "+
+			"The available options are: %s and %s", flags.OutputFormat, html, pkg.JSON)
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	from, err := loadSnapshot(flags.From)
+	if err != nil {
+		return err
+	}
+	to, err := loadSnapshot(flags.To)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Generating data...")
+	diffReport := diff.NewReport(from, to)
+
+	log.Info("Generating output...")
+	if flags.OutputFormat == pkg.JSON {
+		if err := diffReport.WriteJSON(flags.OutputPath); err != nil {
+			return err
+		}
+		log.Infof("Operation completed.")
+		return nil
+	}
+
+	dashOutputPath := filepath.Join(flags.OutputPath, pkg.GetReportName(diffReport.To.Image, "diff", html))
+	f, err := os.Create(dashOutputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	t := template.Must(template.ParseFS(diffTemplate, "diff_template.go.tmpl"))
+	if err := t.Execute(f, diffReport); err != nil {
+		return err
+	}
+
+	log.Infof("Operation completed.")
+	return nil
+}
+
+func loadSnapshot(path string) (*diff.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshot, err := diff.LoadSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the report %s: %s", path, err)
+	}
+	return snapshot, nil
+}
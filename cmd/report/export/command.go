@@ -0,0 +1,132 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg/reports/export"
+)
+
+// BindFlags define the flags used to export the reports
+type BindFlags struct {
+	Files   []string
+	SQLite  string
+	Version string
+	Replace bool
+}
+
+var flags = BindFlags{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "exports JSON bundles reports into a SQLite database",
+		Long: `use this command with the results of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to use SQL to analyse the reports, e.g. to check how the findings change across the versions
+of an index. The reports are appended to the database informed, which is created when it does not exist, in the
+following tables:
+
+- reports: index image, index version, image ID and when the report was generated
+- packages: packages of each report and their default channel
+- bundles: bundles of each package with their version, maxOCPVersion and if they are head of channel or deprecated
+- channels: channels of each bundle
+- findings: validator, scorecard and audit findings of each bundle by severity
+- labels: labels of the bundle images
+- annotations: annotations of the bundles (source bundle) and of their CSVs (source csv)
+- properties: properties of the bundles
+- related_images: related images of the CSVs
+
+Each report is identified by its index image and version, which is by default the tag of the index image.
+
+## Example
+
+$ audit-tool report export --sqlite=audit.db --file=bundles_index_v4.8.json --file=bundles_index_v4.9.json
+$ sqlite3 audit.db "SELECT r.index_version, f.severity, count(*) FROM findings f
+    JOIN bundles b ON b.id = f.bundle_id JOIN reports r ON r.id = b.report_id
+    WHERE b.is_head_of_channel GROUP BY r.index_version, f.severity"
+`,
+		PreRunE:      validation,
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringSliceVar(&flags.Files, "file", nil,
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]. "+
+			"It can be informed more than once")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `export` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.SQLite, "sqlite", "",
+		"path of the SQLite database where the reports are exported")
+	if err := cmd.MarkFlagRequired("sqlite"); err != nil {
+		log.Fatalf("Failed to mark `sqlite` flag for `export` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.Version, "version", "",
+		"version which identifies the report in the database. (Default: the tag of the index image)")
+	cmd.Flags().BoolVar(&flags.Replace, "replace", false,
+		"replace the data of a report with the same index image and version already exported")
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(flags.Version) > 0 && len(flags.Files) > 1 {
+		return fmt.Errorf("the --version flag can only be used when one report is exported")
+	}
+	for _, file := range flags.Files {
+		if _, err := os.Stat(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	db, err := sql.Open("sqlite3", flags.SQLite)
+	if err != nil {
+		return fmt.Errorf("unable to open the database %s: %s", flags.SQLite, err)
+	}
+	defer db.Close()
+
+	for _, file := range flags.Files {
+		log.Infof("Exporting %s ...", file)
+		if err := exportFile(db, file); err != nil {
+			return fmt.Errorf("unable to export the report %s: %s", file, err)
+		}
+	}
+
+	log.Infof("Operation completed.")
+	return nil
+}
+
+func exportFile(db *sql.DB, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = export.Export(db, f, export.Options{Version: flags.Version, Replace: flags.Replace})
+	return err
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/cmd/report/check"
+	"github.com/operator-framework/audit/cmd/report/diff"
+	"github.com/operator-framework/audit/cmd/report/export"
+	"github.com/operator-framework/audit/cmd/report/query"
+	"github.com/operator-framework/audit/cmd/report/validate"
+)
+
+func NewCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "work with the JSON reports generated by audit",
+		Long:  "use the sub-commands to check and process the JSON reports generated by audit-tool index bundles",
+	}
+
+	reportCmd.AddCommand(
+		validate.NewCmd(),
+		diff.NewCmd(),
+		query.NewCmd(),
+		export.NewCmd(),
+		check.NewCmd(),
+	)
+
+	return reportCmd
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg/reports/query"
+)
+
+// BindFlags define the flags used to query the report
+type BindFlags struct {
+	File         string
+	Where        string
+	Select       []string
+	OutputFormat string
+}
+
+var flags = BindFlags{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "selects the bundles of the JSON bundles report which match an expression",
+		Long: `use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to check which bundles of the report match a criteria without writing a script. The expression
+informed with --where is checked against each bundle of the report and the fields informed with --select are
+output for the bundles which match it.
+
+## Fields
+
+Any JSON key of the bundles of the report can be used, e.g. csv.spec.install.spec.deployments. Keys with dots
+or slashes are informed between brackets, e.g. annotations['operators.openshift.io/valid-subscription'] (use
+single quotes in --select). The following short names can also be used:
+
+- package, bundle, bundleImage, channels, defaultChannel, head, deprecated, fromDefaultChannel, maxOCPVersion
+- labels, annotations, properties, csv
+- validatorErrors, validatorWarnings, scorecardFailingTests, auditErrors
+
+When a field has a list of values, e.g. the deployments of the CSV, a comparison is true when it is true for any
+of the values.
+
+## Operators
+
+- comparisons: ==, !=, <, <=, >, >= (numbers and versions such as 4.8 < 4.10), =~ (or matches) and contains
+- logical: and (&&), or (||), not (!) and parentheses
+
+## Example
+
+$ audit-tool report query --file=bundles.json \
+    --where='head and fromDefaultChannel and not maxOCPVersion and
+             csv.spec.install.spec.deployments.spec.template.spec.hostNetwork == true' \
+    --select=package,bundle,channels --output=csv
+`,
+		PreRunE:      validation,
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVar(&flags.File, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `query` sub-command as required")
+	}
+	cmd.Flags().StringVar(&flags.Where, "where", "",
+		"expression which the bundles should match. (Default: all bundles)")
+	cmd.Flags().StringSliceVar(&flags.Select, "select", query.DefaultFields,
+		"fields which should be output for the bundles which match the expression")
+	cmd.Flags().StringVar(&flags.OutputFormat, "output", query.Table,
+		fmt.Sprintf("inform the output format. [Options: %s]", strings.Join(query.OutputFormats(), ", ")))
+	return cmd
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	for _, format := range query.OutputFormats() {
+		if flags.OutputFormat == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value informed via the --output flag :%v. "+
+		"The available options are: %s", flags.OutputFormat, strings.Join(query.OutputFormats(), ", "))
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	q, err := query.New(flags.Where, flags.Select)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(flags.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := q.Run(f)
+	if err != nil {
+		return fmt.Errorf("unable to query the report %s: %s", flags.File, err)
+	}
+	return result.Write(cmd.OutOrStdout(), flags.OutputFormat)
+}
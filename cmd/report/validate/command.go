@@ -0,0 +1,76 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+var file string
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "checks the JSON bundles report against its JSON Schema",
+		Long: fmt.Sprintf(`use this command with the result of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to ensure that a JSON bundles report follows the JSON Schema of the version %s
+(see %s) before consuming it. The reports generated before the schema was versioned
+(without the schemaVersion field) can still be loaded by the dashboard commands, which migrate them, but
+they are reported as not following the schema.
+`, bundles.SchemaVersion, bundles.SchemaFile()),
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVar(&file, "file", "",
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]")
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		log.Fatalf("Failed to mark `file` flag for `validate` sub-command as required")
+	}
+	return cmd
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	issues, err := bundles.ValidateReport(f)
+	if err != nil {
+		return fmt.Errorf("unable to read the report %s: %s", file, err)
+	}
+
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			log.Error(issue)
+		}
+		return fmt.Errorf("the report %s does not follow the schema %s (%d issues found)",
+			file, bundles.SchemaVersion, len(issues))
+	}
+
+	log.Infof("The report %s follows the schema %s", file, bundles.SchemaVersion)
+	return nil
+}
@@ -0,0 +1,347 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package site
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/audit/cmd/custom"
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	customreports "github.com/operator-framework/audit/pkg/reports/custom"
+	"github.com/operator-framework/audit/pkg/site"
+)
+
+//go:embed *.tmpl
+var siteTemplates embed.FS
+
+// Dashboards which can be generated for each report
+const (
+	QA              = "qa"
+	BundleSize      = "bundle-size"
+	Security        = "security"
+	RBAC            = "rbac"
+	DeprecateAPIs   = "deprecate-apis"
+	Multiarch       = "multiarch"
+	Interactive     = "interactive"
+	Features        = "features"
+	Disconnected    = "disconnected"
+	Images          = "images"
+	Vulnerabilities = "vulnerabilities"
+	MaxOCP          = "maxocp"
+	Validator       = "validator"
+)
+
+// BindFlags define the flags used to generate the site
+type BindFlags struct {
+	Files           []string
+	ReportsDir      string
+	OutputPath      string
+	Title           string
+	Dashboards      []string
+	K8SVersions     []string
+	ContainerEngine string
+	QAProfile       string
+	// FilterValidation is the text of the validator errors and warnings shown in the validator dashboards
+	FilterValidation string
+}
+
+var flags = BindFlags{}
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "site",
+		Short: "generates a static site with the dashboards of the JSON bundles reports",
+		Long: `use this command with the results of $audit index bundles [OPTIONS].
+
+## When should I use this command?
+
+If you would like to publish the dashboards of your catalogs, such as in https://operator-framework.github.io/audit/.
+The dashboards informed are generated for each bundles report in the output directory, organized by catalog and
+version (tag of the index image), with:
+
+- index.html: landing page with the dashboards of all catalogs and versions
+- dashboards/<catalog>/<version>/*.html: dashboards with the navigation to the other versions of the catalog
+
+The dashboards already in the output directory are kept, so the site can be generated in steps, e.g. to generate
+the multiarch dashboards, which pull the images, separately.
+
+## Dashboards
+
+- qa, bundle-size, security, rbac, features, disconnected, interactive, maxocp
+- deprecate-apis (one per Kubernetes version informed with --k8s-versions)
+- validator (not generated by default since it requires the validation informed with --filter-validation)
+- multiarch (not generated by default since it pulls the images of the bundles)
+- images (not generated by default since it requires the reports generated with --check-images)
+- vulnerabilities (not generated by default since it requires the reports generated with --vulnerability-db)
+
+## Example
+
+$ audit-tool site --reports-dir=testdata/reports --output-path=site --title="My catalogs"
+`,
+		PreRunE:      validation,
+		RunE:         run,
+		SilenceUsage: true,
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	cmd.Flags().StringSliceVar(&flags.Files, "file", nil,
+		"path of the JSON File result of the command audit-tool index bundles --index-image=<image> [OPTIONS]. "+
+			"It can be informed more than once")
+	cmd.Flags().StringVar(&flags.ReportsDir, "reports-dir", "",
+		"path of a directory with the JSON bundles reports (files bundles_*.json), which is walked recursively")
+	cmd.Flags().StringVar(&flags.OutputPath, "output-path", currentPath,
+		"inform the path of the directory to output the site. (Default: current directory)")
+	cmd.Flags().StringVar(&flags.Title, "title", "Available Dashboards",
+		"title of the landing page")
+	cmd.Flags().StringSliceVar(&flags.Dashboards, "dashboards", defaultDashboards(),
+		fmt.Sprintf("dashboards which should be generated. [Options: %s]", strings.Join(allDashboards(), ", ")))
+	cmd.Flags().StringSliceVar(&flags.K8SVersions, "k8s-versions", customreports.RemovedAPIsK8sVersions(),
+		"Kubernetes versions used to generate the deprecate-apis dashboards")
+	cmd.Flags().StringVar(&flags.ContainerEngine, "container-engine", pkg.Docker,
+		fmt.Sprintf("specifies the container tool to use to generate the multiarch dashboards. "+
+			"Supported values: %s and %s.", pkg.Docker, pkg.Podman))
+	cmd.Flags().StringVar(&flags.QAProfile, "qa-profile", "",
+		"path of a YAML file with the profile used to grade the packages in the qa dashboards")
+	cmd.Flags().StringVar(&flags.FilterValidation, "filter-validation", "",
+		"filter the validator dashboards by the error/warnings results which contain *filter-validation*")
+	return cmd
+}
+
+// defaultDashboards returns the dashboards generated by default, which do not require to pull the images
+func defaultDashboards() []string {
+	return []string{QA, BundleSize, Security, RBAC, DeprecateAPIs, Features, Disconnected, Interactive, MaxOCP}
+}
+
+// allDashboards returns all dashboards. The images and vulnerabilities dashboards are only useful for the reports
+// generated with the flags --check-images and --vulnerability-db, and the validator dashboard requires the
+// validation informed, so they are not generated by default.
+func allDashboards() []string {
+	return append(defaultDashboards(), Multiarch, Images, Vulnerabilities, Validator)
+}
+
+func isDashboard(name string) bool {
+	for _, d := range allDashboards() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validation(cmd *cobra.Command, args []string) error {
+	if len(flags.Files) == 0 && len(flags.ReportsDir) == 0 {
+		return fmt.Errorf("inform the reports with --file or --reports-dir")
+	}
+	if _, err := os.Stat(flags.OutputPath); os.IsNotExist(err) {
+		return err
+	}
+	for _, d := range flags.Dashboards {
+		if !isDashboard(d) {
+			return fmt.Errorf("invalid value informed via the --dashboards flag :%v. "+
+				"The available options are: %s", d, strings.Join(allDashboards(), ", "))
+		}
+		if d == Validator && len(flags.FilterValidation) == 0 {
+			return fmt.Errorf("inform the validation of the validator dashboards with --filter-validation")
+		}
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	log.Info("Starting ...")
+
+	files, err := reportFiles()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	for _, file := range files {
+		image, err := indexImage(file)
+		if err != nil {
+			return fmt.Errorf("unable to read the report %s: %s", file, err)
+		}
+		names[site.CatalogDir(image)] = site.CatalogName(image)
+
+		outputDir := site.OutputDir(flags.OutputPath, image)
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return err
+		}
+		log.Infof("Generating the dashboards of %s ...", image)
+		for _, dashboardArgs := range dashboardsArgs(file, outputDir) {
+			if err := runDashboard(dashboardArgs); err != nil {
+				return fmt.Errorf("unable to generate the dashboard %s of the report %s: %s",
+					dashboardArgs[0], file, err)
+			}
+		}
+	}
+
+	log.Info("Generating the site ...")
+	s, err := site.Scan(flags.OutputPath, names)
+	if err != nil {
+		return err
+	}
+	if err := addNavigation(s); err != nil {
+		return err
+	}
+	if err := writeIndex(s); err != nil {
+		return err
+	}
+
+	log.Infof("Operation completed.")
+	return nil
+}
+
+// reportFiles returns the files informed and the bundles reports found in the reports dir
+func reportFiles() ([]string, error) {
+	files := append([]string{}, flags.Files...)
+	if len(flags.ReportsDir) == 0 {
+		return files, nil
+	}
+	err := filepath.Walk(flags.ReportsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasPrefix(info.Name(), "bundles") && strings.HasSuffix(info.Name(), ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// indexImage returns the index image of the report without keeping its columns in memory
+func indexImage(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	report, err := bundles.DecodeReport(f, func(bundles.Column) error { return nil })
+	if err != nil {
+		return "", err
+	}
+	if len(report.Flags.IndexImage) == 0 {
+		return "", fmt.Errorf("the report has no index image")
+	}
+	return report.Flags.IndexImage, nil
+}
+
+// dashboardsArgs returns the arguments of the dashboard commands which should be executed for the report
+func dashboardsArgs(file, outputDir string) [][]string {
+	common := []string{fmt.Sprintf("--file=%s", file), fmt.Sprintf("--output-path=%s", outputDir)}
+	var result [][]string
+	for _, d := range flags.Dashboards {
+		switch d {
+		case DeprecateAPIs:
+			for _, version := range flags.K8SVersions {
+				result = append(result, append([]string{"deprecate-apis",
+					fmt.Sprintf("--optional-values=k8s-version=%s", version)}, common...))
+			}
+		case QA:
+			args := append([]string{QA}, common...)
+			if len(flags.QAProfile) > 0 {
+				args = append(args, fmt.Sprintf("--profile=%s", flags.QAProfile))
+			}
+			result = append(result, args)
+		case Validator:
+			result = append(result, append([]string{Validator,
+				fmt.Sprintf("--filter-validation=%s", flags.FilterValidation)}, common...))
+		case Multiarch:
+			result = append(result, append([]string{"multiarch",
+				fmt.Sprintf("--container-engine=%s", flags.ContainerEngine)}, common...))
+		default:
+			result = append(result, append([]string{d}, common...))
+		}
+	}
+	return result
+}
+
+// runDashboard executes the dashboard command with the arguments informed
+func runDashboard(args []string) error {
+	// the flags of the dashboards are global, so the values of the previous execution are cleaned
+	customreports.Flags = customreports.BindFlags{}
+	dashboardCmd := custom.NewCmd()
+	dashboardCmd.SetArgs(args)
+	dashboardCmd.SilenceUsage = true
+	dashboardCmd.SilenceErrors = true
+	return dashboardCmd.Execute()
+}
+
+// addNavigation adds to each dashboard the links to the landing page and to the other dashboards of the catalog
+func addNavigation(s *site.Site) error {
+	t := template.Must(template.ParseFS(siteTemplates, "nav_template.go.tmpl"))
+	for _, catalog := range s.Catalogs {
+		for _, version := range catalog.Versions {
+			for _, dashboard := range version.Dashboards {
+				var nav bytes.Buffer
+				if err := t.Execute(&nav, struct {
+					Current Dashboard
+					Version site.Version
+					Catalog site.Catalog
+				}{Current: Dashboard(dashboard), Version: version, Catalog: catalog}); err != nil {
+					return err
+				}
+				if err := site.InjectNav(filepath.Join(flags.OutputPath, dashboard.Path), nav.Bytes()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Dashboard is the dashboard which shows the navigation
+type Dashboard site.Dashboard
+
+// Link returns the link from the current dashboard to the dashboard informed
+func (d Dashboard) Link(target site.Dashboard) string {
+	return site.Dashboard(d).Relative(target.Path)
+}
+
+// Home returns the link from the current dashboard to the landing page
+func (d Dashboard) Home() string {
+	return site.Dashboard(d).Relative("index.html")
+}
+
+func writeIndex(s *site.Site) error {
+	f, err := os.Create(filepath.Join(flags.OutputPath, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	t := template.Must(template.ParseFS(siteTemplates, "index_template.go.tmpl"))
+	return t.Execute(f, struct {
+		Title string
+		*site.Site
+	}{Title: flags.Title, Site: s})
+}
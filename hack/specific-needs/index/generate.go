@@ -115,7 +115,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	t := template.Must(template.ParseFiles(filepath.Join(currentPath, "hack/index/template.go.tmpl")))
+	t := template.Must(template.ParseFiles(filepath.Join(currentPath, "hack/specific-needs/index/template.go.tmpl")))
 	err = t.Execute(f, index)
 	if err != nil {
 		log.Fatalf("error to exec %v", err)
@@ -0,0 +1,194 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+// rawManifest has the data used from the manifest of the image, which is a manifest list (or OCI index) for the
+// multi-arch images
+type rawManifest struct {
+	Manifests []struct {
+		Digest string `json:"digest"`
+	} `json:"manifests"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+}
+
+// imageConfig has the data used from the config of the image
+type imageConfig struct {
+	Created string `json:"created"`
+}
+
+// notFoundMessages are the errors returned by the registries when the image or its repository does not exist
+var notFoundMessages = []string{"manifest unknown", "name unknown", "statuscode: 404"}
+
+// runImageInspect returns the manifest of the image as it is stored in the registry. Unlike skopeo inspect without
+// --raw, it does not resolve the manifest lists to the image of the host platform, so that the digest is the one
+// used to pin the image. It is a variable so that it can be replaced in the tests.
+var runImageInspect = func(image string) ([]byte, error) {
+	return pkg.RunCommand(exec.Command("skopeo", "inspect", "--raw", "docker://"+image))
+}
+
+// runConfigInspect returns the config of the image. It is a variable so that it can be replaced in the tests.
+var runConfigInspect = func(image string) ([]byte, error) {
+	return pkg.RunCommand(exec.Command("skopeo", "inspect", "--config", "docker://"+image))
+}
+
+// runSignatureVerify verifies the cosign signature of the image with the public key. The image is informed by
+// digest so that the signature verified is the one of the manifest inspected.
+// It is a variable so that it can be replaced in the tests.
+var runSignatureVerify = func(image, key string) error {
+	_, err := pkg.RunCommand(exec.Command("cosign", "verify", "--key", key, image))
+	return err
+}
+
+// checkedImages keeps the results since the same images are usually referenced by more than one bundle
+var checkedImages = struct {
+	sync.Mutex
+	results map[string]models.ImageReference
+}{results: map[string]models.ImageReference{}}
+
+// CheckImageReferences resolves the images referenced by the CSV of the bundle (see pkg.ImagesFromCSV) against
+// their registries and, when public keys are informed, verifies their cosign signatures
+func CheckImageReferences(auditBundle *models.AuditBundle, signatureKeys []string) {
+	csv := bundleCSV(auditBundle)
+	if csv == nil {
+		return
+	}
+
+	sources, err := pkg.ImagesFromCSV(csv)
+	if err != nil {
+		auditBundle.Errors = append(auditBundle.Errors,
+			fmt.Sprintf("unable to check the images of the alm-examples: %s", err))
+	}
+
+	var images []string
+	for image := range sources {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	auditBundle.ImageReferences = nil
+	for _, image := range images {
+		ref := checkImage(image, signatureKeys)
+		ref.Sources = sources[image]
+		auditBundle.ImageReferences = append(auditBundle.ImageReferences, ref)
+	}
+}
+
+// bundleCSV returns the CSV of the bundle image or, when the bundle could not be extracted, the one from the index
+func bundleCSV(auditBundle *models.AuditBundle) *v1alpha1.ClusterServiceVersion {
+	if auditBundle.Bundle != nil && auditBundle.Bundle.CSV != nil {
+		return auditBundle.Bundle.CSV
+	}
+	return auditBundle.CSVFromIndexDB
+}
+
+func checkImage(image string, signatureKeys []string) models.ImageReference {
+	checkedImages.Lock()
+	ref, found := checkedImages.results[image]
+	checkedImages.Unlock()
+	if found {
+		return ref
+	}
+
+	log.Infof("checking the image %s in its registry", image)
+	ref = inspectImage(image)
+	if ref.Status == models.ImageExists && len(signatureKeys) > 0 {
+		ref.Signature = models.SignatureNotVerified
+		for _, key := range signatureKeys {
+			if err := runSignatureVerify(imageByDigest(image, ref.Digest), key); err == nil {
+				ref.Signature = models.SignatureVerified
+				ref.SignatureKey = key
+				break
+			}
+		}
+	}
+
+	checkedImages.Lock()
+	checkedImages.results[image] = ref
+	checkedImages.Unlock()
+	return ref
+}
+
+// inspectImage checks if the image exists and obtains its digest from its manifest. The size and the created date
+// are only informed for the single-arch images since they are different for each platform of a manifest list.
+func inspectImage(image string) models.ImageReference {
+	ref := models.ImageReference{Image: image, Status: models.ImageExists}
+	output, err := runImageInspect(image)
+	if err != nil {
+		ref.Status = models.ImageUnknown
+		ref.Error = err.Error()
+		for _, msg := range notFoundMessages {
+			if strings.Contains(strings.ToLower(err.Error()), msg) {
+				ref.Status = models.ImageNotFound
+				break
+			}
+		}
+		return ref
+	}
+
+	var manifest rawManifest
+	if err := json.Unmarshal(output, &manifest); err != nil {
+		ref.Status = models.ImageUnknown
+		ref.Error = fmt.Sprintf("unable to parse the manifest of the image: %s", err)
+		return ref
+	}
+	ref.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256(output))
+	if len(manifest.Manifests) > 0 {
+		return ref
+	}
+
+	for _, layer := range manifest.Layers {
+		ref.Size += layer.Size
+	}
+	output, err = runConfigInspect(imageByDigest(image, ref.Digest))
+	if err != nil {
+		log.Debugf("unable to inspect the config of the image %s: %s", image, err)
+		return ref
+	}
+	var config imageConfig
+	if err := json.Unmarshal(output, &config); err == nil {
+		ref.Created = config.Created
+	}
+	return ref
+}
+
+// imageByDigest returns the image referenced by the digest informed, e.g. quay.io/org/name@sha256:<digest>,
+// dropping its tag. The image is returned as it is when it is already pinned by digest.
+func imageByDigest(image, digest string) string {
+	if len(digest) == 0 || pkg.IsPinnedByDigest(image) {
+		return image
+	}
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		image = image[:index]
+	}
+	return image + "@" + digest
+}
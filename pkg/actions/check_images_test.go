@@ -0,0 +1,124 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+func TestCheckImageReferences(t *testing.T) {
+	defer func(inspect, config func(string) ([]byte, error), verify func(string, string) error) {
+		runImageInspect = inspect
+		runConfigInspect = config
+		runSignatureVerify = verify
+		checkedImages.results = map[string]models.ImageReference{}
+	}(runImageInspect, runConfigInspect, runSignatureVerify)
+
+	manifest := `{"schemaVersion": 2, "layers": [{"size": 100}, {"size": 23}]}`
+	manifestList := `{"schemaVersion": 2, "manifests": [{"digest": "sha256:1"}, {"digest": "sha256:2"}]}`
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))
+	listDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifestList)))
+
+	inspected := map[string]int{}
+	runImageInspect = func(image string) ([]byte, error) {
+		inspected[image]++
+		switch image {
+		case "quay.io/example/foo:v1", "quay.io/example/operand:v1":
+			return []byte(manifest), nil
+		case "quay.io/example/multiarch:v1":
+			return []byte(manifestList), nil
+		case "quay.io/example/deleted:v1":
+			return nil, fmt.Errorf("skopeo inspect failed: reading manifest v1 in quay.io/example/deleted: " +
+				"manifest unknown")
+		case "quay.io/example/broken:v1":
+			return nil, fmt.Errorf("skopeo inspect failed: exec: \"skopeo\": executable file not found in $PATH")
+		default:
+			return nil, fmt.Errorf("skopeo inspect failed: unauthorized: access to the requested resource is " +
+				"not authorized")
+		}
+	}
+	runConfigInspect = func(image string) ([]byte, error) {
+		if image != "quay.io/example/foo@"+digest && image != "quay.io/example/operand@"+digest {
+			t.Errorf("unexpected config inspected for %s", image)
+		}
+		return []byte(`{"created": "2024-01-02T03:04:05Z"}`), nil
+	}
+	runSignatureVerify = func(image, key string) error {
+		if image == "quay.io/example/foo@"+digest && key == "second.pub" {
+			return nil
+		}
+		return fmt.Errorf("no matching signatures")
+	}
+
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Spec.RelatedImages = []v1alpha1.RelatedImage{
+		{Image: "quay.io/example/foo:v1"}, {Image: "quay.io/example/operand:v1"},
+		{Image: "quay.io/example/deleted:v1"}, {Image: "private.io/example/other:v1"},
+		{Image: "quay.io/example/multiarch:v1"}, {Image: "quay.io/example/broken:v1"},
+	}
+	for i := 0; i < 2; i++ {
+		auditBundle := &models.AuditBundle{Bundle: &apimanifests.Bundle{CSV: csv}}
+		CheckImageReferences(auditBundle, []string{"first.pub", "second.pub"})
+		if len(auditBundle.ImageReferences) != 6 {
+			t.Fatalf("unexpected images: %+v", auditBundle.ImageReferences)
+		}
+
+		want := map[string]string{
+			"private.io/example/other:v1":  "unknown  0  ",
+			"quay.io/example/broken:v1":    "unknown  0  ",
+			"quay.io/example/deleted:v1":   "not found  0  ",
+			"quay.io/example/foo:v1":       "exists " + digest + " 123 2024-01-02T03:04:05Z verified",
+			"quay.io/example/operand:v1":   "exists " + digest + " 123 2024-01-02T03:04:05Z not verified",
+			"quay.io/example/multiarch:v1": "exists " + listDigest + " 0  not verified",
+		}
+		for _, ref := range auditBundle.ImageReferences {
+			got := fmt.Sprintf("%s %s %d %s %s", ref.Status, ref.Digest, ref.Size, ref.Created, ref.Signature)
+			if got != want[ref.Image] {
+				t.Errorf("got %q for %s, want %q", got, ref.Image, want[ref.Image])
+			}
+		}
+	}
+
+	// the images are only inspected once
+	if inspected["quay.io/example/foo:v1"] != 1 {
+		t.Errorf("the image was inspected %d times", inspected["quay.io/example/foo:v1"])
+	}
+}
+
+func TestImageByDigest(t *testing.T) {
+	for _, tt := range []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/example/foo:v1", want: "quay.io/example/foo@sha256:1234"},
+		{image: "quay.io/example/foo", want: "quay.io/example/foo@sha256:1234"},
+		{image: "localhost:5000/example/foo:v1", want: "localhost:5000/example/foo@sha256:1234"},
+		{image: "localhost:5000/example/foo", want: "localhost:5000/example/foo@sha256:1234"},
+		{image: "quay.io/example/foo@sha256:5678", want: "quay.io/example/foo@sha256:5678"},
+	} {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := imageByDigest(tt.image, "sha256:1234"); got != tt.want {
+				t.Errorf("imageByDigest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/sbom"
+)
+
+// runSBOMGenerate generates the SBOM of the image in the CycloneDX JSON format with syft, which reads the image
+// from its registry. It is a variable so that it can be replaced in the tests.
+var runSBOMGenerate = func(image, path string) error {
+	_, err := pkg.RunCommand(exec.Command("syft", "registry:"+image, "-q", "-o", "cyclonedx-json="+path))
+	return err
+}
+
+// sbomCheck is the check of the vulnerabilities of an image, whose result is only available once done is closed
+type sbomCheck struct {
+	done   chan struct{}
+	result models.ImageVulnerabilities
+}
+
+// checkedSBOMs keeps the results since the same images are usually referenced by more than one bundle. The entry
+// is added before the image is checked so that the bundles audited concurrently wait for it instead of generating
+// the same SBOM again.
+var checkedSBOMs = struct {
+	sync.Mutex
+	results map[string]*sbomCheck
+}{results: map[string]*sbomCheck{}}
+
+// SBOMFileName returns the name of the file with the SBOM of the image in the SBOMs directory, e.g.
+// quay.io_org_name_sha256_1234.json for quay.io/org/name@sha256:1234
+func SBOMFileName(image string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(image) + ".json"
+}
+
+// CheckVulnerabilities matches the packages of the SBOMs of the images referenced by the CSV of the bundle
+// (see pkg.ImagesFromCSV) against the vulnerability database. The SBOMs are read from the directory informed
+// and, when generate is true, the missing ones are generated with syft and stored in it.
+func CheckVulnerabilities(auditBundle *models.AuditBundle, db *sbom.Database, sbomDir string, generate bool) {
+	csv := bundleCSV(auditBundle)
+	if csv == nil {
+		return
+	}
+
+	sources, err := pkg.ImagesFromCSV(csv)
+	if err != nil {
+		auditBundle.Errors = append(auditBundle.Errors,
+			fmt.Sprintf("unable to check the images of the alm-examples: %s", err))
+	}
+	var images []string
+	for image := range sources {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	summary := models.VulnerabilitySummary{}
+	counted := map[string]bool{}
+	for _, image := range images {
+		result := checkImageVulnerabilities(image, db, sbomDir, generate)
+		for _, v := range result.Vulnerabilities {
+			key := v.ID + "/" + v.Package
+			if !counted[key] {
+				counted[key] = true
+				countSeverity(&summary.VulnerabilityCounts, v.Severity)
+			}
+		}
+		summary.Images = append(summary.Images, result)
+	}
+	auditBundle.Vulnerabilities = &summary
+}
+
+func checkImageVulnerabilities(image string, db *sbom.Database, sbomDir string,
+	generate bool) models.ImageVulnerabilities {
+	checkedSBOMs.Lock()
+	check, found := checkedSBOMs.results[image]
+	if !found {
+		check = &sbomCheck{done: make(chan struct{})}
+		checkedSBOMs.results[image] = check
+	}
+	checkedSBOMs.Unlock()
+	if found {
+		<-check.done
+		return check.result
+	}
+	defer close(check.done)
+
+	result := &check.result
+	result.Image = image
+	path := filepath.Join(sbomDir, SBOMFileName(image))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if generate {
+			log.Infof("generating the SBOM of the image %s", image)
+			if err := generateSBOM(image, path); err != nil {
+				result.Error = fmt.Sprintf("unable to generate the SBOM: %s", err)
+			}
+		} else {
+			result.Error = fmt.Sprintf("the SBOM was not found (%s)", path)
+		}
+	}
+
+	if len(result.Error) == 0 {
+		matchSBOM(result, path, db)
+	}
+	return *result
+}
+
+// generateSBOM generates the SBOM of the image into a temporary file which is renamed to the path informed once
+// it is complete, so that a partial SBOM is never read from the SBOMs directory
+func generateSBOM(image, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := runSBOMGenerate(image, tmp.Name()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// matchSBOM matches the packages of the SBOM against the vulnerability database
+func matchSBOM(result *models.ImageVulnerabilities, path string, db *sbom.Database) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Error = fmt.Sprintf("unable to read the SBOM: %s", err)
+		return
+	}
+	format, packages, err := sbom.Parse(data)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.SBOMFormat = format
+	result.Packages = len(packages)
+
+	found := map[string]bool{}
+	for _, p := range packages {
+		for _, finding := range db.Match(p) {
+			key := finding.ID + "/" + finding.Package + "/" + finding.Version
+			if found[key] {
+				continue
+			}
+			found[key] = true
+			result.Vulnerabilities = append(result.Vulnerabilities, models.Vulnerability{ID: finding.ID,
+				Package: finding.Package, Version: finding.Version, Severity: finding.Severity,
+				FixedIn: finding.FixedIn})
+			countSeverity(&result.VulnerabilityCounts, finding.Severity)
+		}
+	}
+	sort.Slice(result.Vulnerabilities, func(i, j int) bool {
+		return result.Vulnerabilities[i].ID < result.Vulnerabilities[j].ID
+	})
+}
+
+func countSeverity(counts *models.VulnerabilityCounts, severity string) {
+	switch severity {
+	case sbom.SeverityCritical:
+		counts.Critical++
+	case sbom.SeverityHigh:
+		counts.High++
+	case sbom.SeverityMedium:
+		counts.Medium++
+	case sbom.SeverityLow:
+		counts.Low++
+	default:
+		counts.Unknown++
+	}
+}
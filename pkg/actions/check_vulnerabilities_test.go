@@ -0,0 +1,161 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/sbom"
+)
+
+func TestCheckVulnerabilities(t *testing.T) {
+	defer func(generate func(string, string) error) {
+		runSBOMGenerate = generate
+		checkedSBOMs.results = map[string]*sbomCheck{}
+	}(runSBOMGenerate)
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "osv.json")
+	if err := os.WriteFile(dbPath, []byte(`[
+{"id": "GO-1", "affected": [{"package": {"ecosystem": "Go", "name": "golang.org/x/net"},
+ "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.17.0"}]}]}],
+ "database_specific": {"severity": "CRITICAL"}},
+{"id": "GO-2", "affected": [{"package": {"ecosystem": "Go", "name": "golang.org/x/text"},
+ "versions": ["0.3.0"]}], "database_specific": {"severity": "HIGH"}}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sbom.LoadDatabase(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sbomDir := filepath.Join(dir, "sboms")
+	if err := os.Mkdir(sbomDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	vulnerable := []byte(`{"bomFormat": "CycloneDX", "components": [
+{"name": "golang.org/x/net", "purl": "pkg:golang/golang.org/x/net@v0.10.0"},
+{"name": "golang.org/x/text", "purl": "pkg:golang/golang.org/x/text@v0.3.0"}]}`)
+	for _, image := range []string{"quay.io/example/foo@sha256:1234", "quay.io/example/operand:v1"} {
+		if err := os.WriteFile(filepath.Join(sbomDir, SBOMFileName(image)), vulnerable, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generated := map[string]int{}
+	runSBOMGenerate = func(image, path string) error {
+		generated[image]++
+		if image == "quay.io/example/generated:v1" {
+			return os.WriteFile(path, []byte(`{"spdxVersion": "SPDX-2.3", "packages": []}`), 0600)
+		}
+		return fmt.Errorf("unauthorized")
+	}
+
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Spec.RelatedImages = []v1alpha1.RelatedImage{
+		{Image: "quay.io/example/foo@sha256:1234"}, {Image: "quay.io/example/operand:v1"},
+		{Image: "quay.io/example/generated:v1"}, {Image: "private.io/example/other:v1"},
+	}
+
+	auditBundle := &models.AuditBundle{Bundle: &apimanifests.Bundle{CSV: csv}}
+	CheckVulnerabilities(auditBundle, db, sbomDir, false)
+	summary := auditBundle.Vulnerabilities
+	if summary == nil || len(summary.Images) != 4 || len(generated) != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	// the same vulnerabilities are found in two images but they are only counted once for the bundle
+	if summary.Critical != 1 || summary.High != 1 {
+		t.Errorf("unexpected counts: %+v", summary.VulnerabilityCounts)
+	}
+	for _, image := range summary.Images {
+		switch image.Image {
+		case "quay.io/example/foo@sha256:1234":
+			if image.SBOMFormat != sbom.FormatCycloneDX || image.Packages != 2 || len(image.Vulnerabilities) != 2 ||
+				image.Vulnerabilities[0].FixedIn != "0.17.0" {
+				t.Errorf("unexpected result: %+v", image)
+			}
+		case "quay.io/example/generated:v1", "private.io/example/other:v1":
+			if len(image.Error) == 0 {
+				t.Errorf("expected error for the image without SBOM: %+v", image)
+			}
+		}
+	}
+
+	checkedSBOMs.results = map[string]*sbomCheck{}
+	auditBundle = &models.AuditBundle{Bundle: &apimanifests.Bundle{CSV: csv}}
+	CheckVulnerabilities(auditBundle, db, sbomDir, true)
+	for _, image := range auditBundle.Vulnerabilities.Images {
+		switch image.Image {
+		case "quay.io/example/generated:v1":
+			if image.SBOMFormat != sbom.FormatSPDX || len(image.Error) > 0 {
+				t.Errorf("unexpected result for the SBOM generated: %+v", image)
+			}
+		case "private.io/example/other:v1":
+			if image.Error != "unable to generate the SBOM: unauthorized" {
+				t.Errorf("unexpected error: %q", image.Error)
+			}
+		}
+	}
+	if len(generated) != 2 {
+		t.Errorf("only the missing SBOMs should be generated: %v", generated)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(sbomDir, "*.tmp")); len(tmp) > 0 {
+		t.Errorf("the temporary SBOMs should be removed: %v", tmp)
+	}
+}
+
+func TestCheckVulnerabilitiesConcurrently(t *testing.T) {
+	defer func(generate func(string, string) error) {
+		runSBOMGenerate = generate
+		checkedSBOMs.results = map[string]*sbomCheck{}
+	}(runSBOMGenerate)
+
+	var generated int32
+	runSBOMGenerate = func(image, path string) error {
+		atomic.AddInt32(&generated, 1)
+		return os.WriteFile(path, []byte(`{"spdxVersion": "SPDX-2.3", "packages": []}`), 0600)
+	}
+
+	sbomDir := t.TempDir()
+	db := &sbom.Database{}
+	var wg sync.WaitGroup
+	results := make([]models.ImageVulnerabilities, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checkImageVulnerabilities("quay.io/example/foo:v1", db, sbomDir, true)
+		}(i)
+	}
+	wg.Wait()
+
+	if generated != 1 {
+		t.Errorf("the SBOM was generated %d times", generated)
+	}
+	for _, result := range results {
+		if result.SBOMFormat != sbom.FormatSPDX || len(result.Error) > 0 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	}
+}
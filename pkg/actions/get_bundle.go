@@ -30,6 +30,7 @@ import (
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/validation"
 )
 
 // Manifest define the manifest.json which is  required to read the bundle
@@ -46,7 +47,8 @@ func GetDataFromBundleImage(auditBundle *models.AuditBundle,
 	label,
 	labelValue string,
 	containerEngine string,
-	indexImage string) *models.AuditBundle {
+	indexImage string,
+	bundleSizeWarnPercent float64) *models.AuditBundle {
 
 	if len(auditBundle.OperatorBundleImagePath) < 1 {
 		log.Errorf("not found bundle path stored in the index.db")
@@ -90,6 +92,14 @@ func GetDataFromBundleImage(auditBundle *models.AuditBundle,
 		return auditBundle
 	}
 
+	// Gathering the size of each manifest as OLM will compute it to stage the bundle
+	auditBundle.BundleSize, err = validation.NewBundleSizeFromDir(filepath.Join(bundleDir, "bundle"))
+	if err != nil {
+		log.Errorf("unable to calculate the bundle size: %s", err)
+		auditBundle.Errors = append(auditBundle.Errors,
+			fmt.Errorf("unable to calculate the bundle size: %s", err).Error())
+	}
+
 	annotationsPath := filepath.Join(bundleDir, "bundle/metadata/annotations.yaml")
 
 	// If find the annotations file then, check for the scorecard path on it.
@@ -119,7 +129,8 @@ func GetDataFromBundleImage(auditBundle *models.AuditBundle,
 
 	// Run validators
 	if !disableValidators {
-		auditBundle = RunValidators(filepath.Join(bundleDir, "bundle"), auditBundle, indexImage)
+		auditBundle = RunValidators(filepath.Join(bundleDir, "bundle"), auditBundle, indexImage,
+			bundleSizeWarnPercent)
 
 	}
 
@@ -16,6 +16,7 @@ package actions
 
 import (
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/operator-framework/audit/pkg/validation"
@@ -26,16 +27,17 @@ import (
 	ocp "github.com/redhat-openshift-ecosystem/ocp-olm-catalog-validator/pkg/validation"
 )
 
-func RunValidators(bundlePath string, auditBundle *models.AuditBundle, indexImage string) *models.AuditBundle {
+func RunValidators(bundlePath string, auditBundle *models.AuditBundle, indexImage string,
+	bundleSizeWarnPercent float64) *models.AuditBundle {
 	checkBundleAgainstCommonCriteria(auditBundle)
 	fromOCPValidator(auditBundle, bundlePath)
 
-	// If the index is < 4.9 then do thw following check
-	if strings.Contains(indexImage, "4.6") ||
+	// If the index is < 4.9 then the bundles are not compressed by OLM
+	// and we need to check the uncompressed size as well
+	checkUncompressedSize := strings.Contains(indexImage, "4.6") ||
 		strings.Contains(indexImage, "4.7") ||
-		strings.Contains(indexImage, "4.8") {
-		fromAuditValidatorsBundleSize(auditBundle)
-	}
+		strings.Contains(indexImage, "4.8")
+	fromAuditValidatorsBundleSize(auditBundle, checkUncompressedSize, bundleSizeWarnPercent)
 
 	return auditBundle
 }
@@ -91,9 +93,17 @@ func fromOCPValidator(auditBundle *models.AuditBundle, bundlePath string) {
 	auditBundle.ValidatorsResults = append(auditBundle.ValidatorsResults, nonEmptyResults...)
 }
 
-func fromAuditValidatorsBundleSize(auditBundle *models.AuditBundle) {
+func fromAuditValidatorsBundleSize(auditBundle *models.AuditBundle, checkUncompressedSize bool,
+	warnPercent float64) {
 	validators := validation.BundleSizeValidator
 	objs := auditBundle.Bundle.ObjectsToValidate()
+	optionalValues := map[string]string{
+		validation.CheckUncompressedSizeKey: strconv.FormatBool(checkUncompressedSize),
+	}
+	if warnPercent > 0 {
+		optionalValues[validation.WarnPercentKey] = strconv.FormatFloat(warnPercent, 'f', -1, 64)
+	}
+	objs = append(objs, optionalValues)
 
 	nonEmptyResults := []errors.ManifestResult{}
 	results := validators.Validate(objs...)
@@ -106,3 +116,26 @@ func fromAuditValidatorsBundleSize(auditBundle *models.AuditBundle) {
 
 	auditBundle.ValidatorsResults = append(auditBundle.ValidatorsResults, nonEmptyResults...)
 }
+
+// RunCRDUpgradeValidator checks the CRDs shipped in the bundle against the CRDs of the bundle
+// which is replaced by it in the channel informed
+func RunCRDUpgradeValidator(auditBundle *models.AuditBundle, replaces *models.AuditBundle,
+	channel string) *models.AuditBundle {
+	if auditBundle.Bundle == nil || replaces == nil || replaces.Bundle == nil {
+		return auditBundle
+	}
+
+	results := validation.CRDUpgradeValidator.Validate(&validation.BundleUpgrade{
+		Channel:  channel,
+		Bundle:   auditBundle.Bundle,
+		Replaces: replaces.Bundle,
+	})
+
+	for _, result := range results {
+		if result.HasError() || result.HasWarn() {
+			auditBundle.ValidatorsResults = append(auditBundle.ValidatorsResults, result)
+		}
+	}
+
+	return auditBundle
+}
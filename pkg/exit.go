@@ -0,0 +1,56 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import "errors"
+
+// Exit codes of audit-tool. They allow the pipelines to know why the audit failed.
+const (
+	// ExitOK is returned when the command succeeds and no threshold informed via --fail-on was exceeded
+	ExitOK = 0
+	// ExitFailure is returned when the command is unable to generate the report, e.g. invalid flags
+	ExitFailure = 1
+	// ExitThresholdExceeded is returned when the report was generated but a threshold informed via --fail-on
+	// was exceeded
+	ExitThresholdExceeded = 2
+	// ExitNoData is returned when no bundle was found for the criteria informed
+	ExitNoData = 3
+)
+
+// ExitError is an error which defines the exit code of audit-tool
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the error returned by the commands
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// FeaturesAnnotationPrefix is the prefix of the annotations used to inform the infrastructure features
+// supported by the Operators on OpenShift. Their values should be the strings "true" or "false".
+// See: https://docs.openshift.com/container-platform/latest/operators/operator_sdk/osdk-generating-csvs.html
+const FeaturesAnnotationPrefix = "features.operators.openshift.io/"
+
+// Infrastructure features which are informed via the annotations features.operators.openshift.io/<feature>
+const (
+	FeatureDisconnected   = "disconnected"
+	FeatureFIPSCompliant  = "fips-compliant"
+	FeatureProxyAware     = "proxy-aware"
+	FeatureTLSProfiles    = "tls-profiles"
+	FeatureTokenAuthAWS   = "token-auth-aws"
+	FeatureTokenAuthAzure = "token-auth-azure"
+	FeatureTokenAuthGCP   = "token-auth-gcp"
+	FeatureCNF            = "cnf"
+	FeatureCNI            = "cni"
+	FeatureCSI            = "csi"
+)
+
+// Features returns the infrastructure features which should be informed via the annotations
+func Features() []string {
+	return []string{FeatureDisconnected, FeatureFIPSCompliant, FeatureProxyAware, FeatureTLSProfiles,
+		FeatureTokenAuthAWS, FeatureTokenAuthAzure, FeatureTokenAuthGCP, FeatureCNF, FeatureCNI, FeatureCSI}
+}
+
+// FeatureAnnotation returns the annotation of the feature, e.g. features.operators.openshift.io/disconnected
+func FeatureAnnotation(feature string) string {
+	return FeaturesAnnotationPrefix + feature
+}
+
+// legacyFeatures maps the values of the legacy annotation operators.openshift.io/infrastructure-features
+// (lower case) to the features
+var legacyFeatures = map[string]string{
+	"disconnected":   FeatureDisconnected,
+	"fips":           FeatureFIPSCompliant,
+	"fips-compliant": FeatureFIPSCompliant,
+	"proxy-aware":    FeatureProxyAware,
+	"tls-profiles":   FeatureTLSProfiles,
+	"token-auth-aws": FeatureTokenAuthAWS,
+	"cnf":            FeatureCNF,
+	"cni":            FeatureCNI,
+	"csi":            FeatureCSI,
+}
+
+// LegacyFeatures returns the features informed via the legacy annotation
+// operators.openshift.io/infrastructure-features, which is a JSON list (e.g. ["disconnected", "proxy-aware"]).
+// The values which are not known are returned as unknown. An error is returned when it is not a JSON list.
+func LegacyFeatures(annotations map[string]string) (features []string, unknown []string, err error) {
+	value, ok := annotations[InfrastructureAnnotation]
+	if !ok {
+		return nil, nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil, nil, err
+	}
+	for _, v := range values {
+		if feature, ok := legacyFeatures[strings.ToLower(strings.TrimSpace(v))]; ok {
+			features = append(features, feature)
+		} else {
+			unknown = append(unknown, v)
+		}
+	}
+	return features, unknown, nil
+}
+
+// IsClaimingFeature returns true when the feature is informed as supported via its annotation or, when it is not
+// informed, via the legacy annotation operators.openshift.io/infrastructure-features
+func IsClaimingFeature(annotations map[string]string, feature string) bool {
+	if value, ok := annotations[FeatureAnnotation(feature)]; ok {
+		return strings.TrimSpace(value) == "true"
+	}
+
+	features, _, err := LegacyFeatures(annotations)
+	if err != nil {
+		// the value is not a JSON list, so the feature is searched in the text
+		for legacy, f := range legacyFeatures {
+			if f == feature && strings.Contains(strings.ToLower(annotations[InfrastructureAnnotation]), legacy) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	semverv4 "github.com/blang/semver/v4"
@@ -41,8 +42,8 @@ const InfrastructureAnnotation = "operators.openshift.io/infrastructure-features
 
 // PropertiesAnnotation used to Unmarshal the JSON in the CSV annotation
 type PropertiesAnnotation struct {
-	Type  string
-	Value string
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
 func (p PropertiesAnnotation) String() string {
@@ -109,6 +110,16 @@ func GetUniqueValues(array []string) []string {
 	return result
 }
 
+// SortedKeys returns the keys of the map sorted
+func SortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func WriteJSON(data []byte, imageName, outputPath, typeName string) error {
 	var prettyJSON bytes.Buffer
 	err := json.Indent(&prettyJSON, data, "", "\t")
@@ -233,6 +244,29 @@ func HasSDKInstalled() bool {
 	return err == nil
 }
 
+// HasSkopeoInstalled will return true when find skopeo installed, which is used to inspect the images
+// without pulling them
+func HasSkopeoInstalled() bool {
+	command := exec.Command("skopeo", "--version")
+	_, err := RunCommand(command)
+	return err == nil
+}
+
+// HasCosignInstalled will return true when find cosign installed, which is used to verify the signatures
+// of the images
+func HasCosignInstalled() bool {
+	command := exec.Command("cosign", "version")
+	_, err := RunCommand(command)
+	return err == nil
+}
+
+// HasSyftInstalled will return true when find syft installed, which is used to generate the SBOMs of the images
+func HasSyftInstalled() bool {
+	command := exec.Command("syft", "version")
+	_, err := RunCommand(command)
+	return err == nil
+}
+
 // ReadFile will return the bites of file
 func ReadFile(file string) ([]byte, error) {
 	jsonFile, err := os.Open(file)
@@ -248,18 +282,16 @@ func ReadFile(file string) ([]byte, error) {
 }
 
 // IsFollowingChannelNameConventional will check the channels.
+//
+// Deprecated: the qa dashboard checks the channel names against the prefixes of its profile
+// (channelPrefixes). This func only checks the default prefixes candidate, stable and fast.
 func IsFollowingChannelNameConventional(channel string) bool {
-	const candidate = "candidate"
-	const stable = "stable"
-	const fast = "fast"
-
-	if !strings.HasPrefix(channel, candidate) &&
-		!strings.HasPrefix(channel, stable) &&
-		!strings.HasPrefix(channel, fast) {
-		return false
+	for _, prefix := range []string{"candidate", "stable", "fast"} {
+		if strings.HasPrefix(channel, prefix) {
+			return true
+		}
 	}
-
-	return true
+	return false
 }
 
 // GetContainerToolFromEnvVar retrieves the value of the environment variable and defaults to docker when not set
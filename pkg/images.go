@@ -0,0 +1,131 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Sources where the images are referenced in the CSV
+const (
+	ImageSourceContainer     = "container"
+	ImageSourceInitContainer = "initContainer"
+	ImageSourceEnvVar        = "env"
+	ImageSourceALMExamples   = "alm-examples"
+	ImageSourceRelatedImages = "relatedImages"
+)
+
+// RelatedImageEnvPrefix is the prefix of the env vars used to inform the images of the operands to the Operators
+const RelatedImageEnvPrefix = "RELATED_IMAGE_"
+
+// ImagesFromCSV returns the images of the containers, init containers and RELATED_IMAGE_* env vars of the
+// deployments, of the alm-examples and of the spec.relatedImages with the sources where they were found (sorted).
+// The images found are returned even when the alm-examples cannot be parsed.
+func ImagesFromCSV(csv *v1alpha1.ClusterServiceVersion) (map[string][]string, error) {
+	sources := map[string][]string{}
+	add := func(image, source string) {
+		if len(image) == 0 {
+			return
+		}
+		for _, s := range sources[image] {
+			if s == source {
+				return
+			}
+		}
+		sources[image] = append(sources[image], source)
+	}
+
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		spec := deployment.Spec.Template.Spec
+		for _, containers := range []struct {
+			source     string
+			containers []corev1.Container
+		}{{ImageSourceContainer, spec.Containers}, {ImageSourceInitContainer, spec.InitContainers}} {
+			for _, c := range containers.containers {
+				add(c.Image, containers.source)
+				for _, env := range c.Env {
+					if strings.HasPrefix(env.Name, RelatedImageEnvPrefix) {
+						add(env.Value, ImageSourceEnvVar)
+					}
+				}
+			}
+		}
+	}
+
+	almImages, err := imagesFromALMExamples(csv)
+	for _, image := range almImages {
+		add(image, ImageSourceALMExamples)
+	}
+
+	for _, related := range csv.Spec.RelatedImages {
+		add(related.Image, ImageSourceRelatedImages)
+	}
+
+	for image := range sources {
+		sort.Strings(sources[image])
+	}
+	return sources, err
+}
+
+// imagesFromALMExamples returns the images informed in the alm-examples, which are the string values of the keys
+// with image in the name (e.g. image or sidecarImage) and the values pinned by digest
+func imagesFromALMExamples(csv *v1alpha1.ClusterServiceVersion) ([]string, error) {
+	almExamples := csv.Annotations["alm-examples"]
+	if len(strings.TrimSpace(almExamples)) == 0 {
+		return nil, nil
+	}
+
+	var examples interface{}
+	if err := json.Unmarshal([]byte(almExamples), &examples); err != nil {
+		return nil, err
+	}
+
+	var images []string
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				walk(k, child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(key, child)
+			}
+		case string:
+			if looksLikeImage(v) && (strings.Contains(strings.ToLower(key), "image") || IsPinnedByDigest(v)) {
+				images = append(images, v)
+			}
+		}
+	}
+	walk("", examples)
+	return images, nil
+}
+
+// looksLikeImage returns true when the value can be an image reference, e.g. quay.io/org/name:tag
+func looksLikeImage(value string) bool {
+	return len(value) > 0 && !strings.ContainsAny(value, " \t\n") && strings.Contains(value, "/")
+}
+
+// IsPinnedByDigest returns true when the image is referenced by digest, e.g. quay.io/org/name@sha256:<digest>
+func IsPinnedByDigest(image string) bool {
+	index := strings.LastIndex(image, "@")
+	return index > 0 && strings.Contains(image[index:], ":")
+}
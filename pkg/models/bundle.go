@@ -20,6 +20,7 @@ import (
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/api/pkg/validation/errors"
 	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/validation"
 )
 
 // AuditBundle defines the data per bundle which is gathering to generate the reports
@@ -37,8 +38,11 @@ type AuditBundle struct {
 	Channels                []string
 	HasCustomScorecardTests bool
 	IsHeadOfChannel         bool
-	BundleImageLabels       map[string]string `json:"bundleImageLabels,omitempty"`
-	BundleAnnotations       map[string]string `json:"bundleAnnotations,omitempty"`
+	BundleImageLabels       map[string]string      `json:"bundleImageLabels,omitempty"`
+	BundleAnnotations       map[string]string      `json:"bundleAnnotations,omitempty"`
+	BundleSize              *validation.BundleSize `json:"bundleSize,omitempty"`
+	ImageReferences         []ImageReference
+	Vulnerabilities         *VulnerabilitySummary
 	Errors                  []string
 }
 
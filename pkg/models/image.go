@@ -0,0 +1,44 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// Status of the images referenced by the bundles which informs if they exist in their registries
+const (
+	ImageExists   = "exists"
+	ImageNotFound = "not found"
+	// ImageUnknown is used when the registry could not be checked, e.g. when the access is denied
+	ImageUnknown = "unknown"
+)
+
+// Results of the verification of the signatures of the images against the public keys informed
+const (
+	SignatureVerified    = "verified"
+	SignatureNotVerified = "not verified"
+)
+
+// ImageReference defines the data of an image referenced by the CSV of the bundle obtained from its registry
+type ImageReference struct {
+	Image   string   `json:"image"`
+	Sources []string `json:"sources,omitempty"`
+	Status  string   `json:"status"`
+	Digest  string   `json:"digest,omitempty"`
+	// Size is the compressed size of the layers of the image
+	Size    int64  `json:"size,omitempty"`
+	Created string `json:"created,omitempty"`
+	// Signature is only informed when the signatures are verified
+	Signature    string `json:"signature,omitempty"`
+	SignatureKey string `json:"signatureKey,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
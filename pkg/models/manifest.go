@@ -0,0 +1,40 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ManifestReference defines a manifest shipped in the bundle by the API which it uses, so that the APIs
+// removed can be checked without keeping the manifests in the report
+type ManifestReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name,omitempty"`
+}
+
+// NewManifestReferences returns the references of the manifests informed, e.g. the objects of the bundle
+func NewManifestReferences(objs []*unstructured.Unstructured) []ManifestReference {
+	refs := []ManifestReference{}
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		refs = append(refs, ManifestReference{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(),
+			Name: obj.GetName()})
+	}
+	return refs
+}
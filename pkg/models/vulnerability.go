@@ -0,0 +1,51 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+// Vulnerability is a vulnerability found in a package of the SBOM of an image
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Severity string `json:"severity"`
+	FixedIn  string `json:"fixedIn,omitempty"`
+}
+
+// VulnerabilityCounts are the number of vulnerabilities found per severity
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+// ImageVulnerabilities defines the vulnerabilities found in the SBOM of an image referenced by the CSV
+type ImageVulnerabilities struct {
+	Image string `json:"image"`
+	// SBOMFormat is spdx or cyclonedx. It is empty when the SBOM of the image was not found.
+	SBOMFormat      string          `json:"sbomFormat,omitempty"`
+	Packages        int             `json:"packages"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	VulnerabilityCounts
+	Error string `json:"error,omitempty"`
+}
+
+// VulnerabilitySummary summarises the vulnerabilities found in the images of the bundle. The same vulnerability
+// of a package found in more than one image is only counted once.
+type VulnerabilitySummary struct {
+	VulnerabilityCounts
+	Images []ImageVulnerabilities `json:"images"`
+}
@@ -15,6 +15,7 @@
 package alpha
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/operator-framework/audit/pkg/reports/custom"
@@ -72,13 +73,14 @@ func NewMaxDashReport(bundlesReport bundles.Report) *MaxDashReport {
 	isOK := mapPkgsComplyingMaxOcpVersion(mapPackagesWithBundles)
 	isNotOK := make(map[string][]custom.BundleDeprecate)
 	for key := range mapPackagesWithBundles {
-		if len(isOK[key]) == 0 {
+		// the bundles without package are ignored as done to check the packages complying
+		if len(isOK[key]) == 0 && key != "" {
 
 			// Filter the bundles to output only what is not OK to make
 			// easier the report conference
 			var notOKBundles []custom.BundleDeprecate
 			for _, b := range mapPackagesWithBundles[key] {
-				if !b.BundleData.IsDeprecated && len(b.ApisRemoved1_22) > 0 &&
+				if !b.BundleData.IsDeprecated && len(b.RemovedAPIsFor("1.22")) > 0 &&
 					!isMaxOCPVersionLowerThan49(b.BundleData.MaxOCPVersion) {
 					notOKBundles = append(notOKBundles, b)
 				}
@@ -99,6 +101,10 @@ func NewMaxDashReport(bundlesReport bundles.Report) *MaxDashReport {
 		})
 	}
 
+	sort.Slice(apiDash.NotOK, func(i, j int) bool {
+		return apiDash.NotOK[i].Name < apiDash.NotOK[j].Name
+	})
+
 	return &apiDash
 
 }
@@ -125,7 +131,7 @@ func mapPkgsComplyingMaxOcpVersion(
 
 func hasWrongMaxOcpVersion(bundlesPerPkg []custom.BundleDeprecate) bool {
 	for _, v := range bundlesPerPkg {
-		if !v.BundleData.IsDeprecated && len(v.ApisRemoved1_22) > 0 &&
+		if !v.BundleData.IsDeprecated && len(v.RemovedAPIsFor("1.22")) > 0 &&
 			!isMaxOCPVersionLowerThan49(v.BundleData.MaxOCPVersion) {
 			return true
 		}
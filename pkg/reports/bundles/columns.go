@@ -24,6 +24,7 @@ import (
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/validation"
 )
 
 const olmproperties = "olm.properties"
@@ -51,6 +52,12 @@ type Column struct {
 	BundleAnnotations        map[string]string               `json:"bundleAnnotations,omitempty"`
 	BundleCSV                *v1alpha1.ClusterServiceVersion `json:"csv,omitempty"`
 	PropertiesFromDB         []pkg.PropertiesAnnotation      `json:"propertiesFromDB,omitempty"`
+	BundleSize               *validation.BundleSize          `json:"bundleSize,omitempty"`
+	ImageReferences          []models.ImageReference         `json:"imageReferences,omitempty"`
+	// Manifests are the APIs used by the manifests of the bundle. They are not informed when the bundle could not
+	// be loaded or in the reports generated before they were added.
+	Manifests       []models.ManifestReference   `json:"manifests,omitempty"`
+	Vulnerabilities *models.VulnerabilitySummary `json:"vulnerabilities,omitempty"`
 }
 
 func NewColumn(v models.AuditBundle) *Column {
@@ -65,7 +72,13 @@ func NewColumn(v models.AuditBundle) *Column {
 	col.BundleImageLabels = v.BundleImageLabels
 	col.BundleAnnotations = v.BundleAnnotations
 	col.PropertiesFromDB = v.PropertiesDB
+	col.BundleSize = v.BundleSize
+	col.ImageReferences = v.ImageReferences
+	col.Vulnerabilities = v.Vulnerabilities
 
+	if v.Bundle != nil {
+		col.Manifests = models.NewManifestReferences(v.Bundle.Objects)
+	}
 	if v.Bundle != nil && v.Bundle.CSV != nil {
 		col.BundleCSV = v.Bundle.CSV
 	} else if v.CSVFromIndexDB != nil {
@@ -87,6 +100,34 @@ func NewColumn(v models.AuditBundle) *Column {
 	return &col
 }
 
+// ApplyCSVDetail drops the data of the CSV which should not be in the report according to the detail informed
+func (c *Column) ApplyCSVDetail(detail string) {
+	if c.BundleCSV == nil {
+		return
+	}
+	switch detail {
+	case CSVDetailNone:
+		c.BundleCSV = nil
+	case CSVDetailSummary:
+		csv := c.BundleCSV.DeepCopy()
+		csv.Spec.Icon = nil
+		csv.Spec.Description = ""
+		csv.ObjectMeta.ManagedFields = nil
+		delete(csv.Annotations, "alm-examples")
+		for i := range csv.Spec.CustomResourceDefinitions.Owned {
+			csv.Spec.CustomResourceDefinitions.Owned[i].SpecDescriptors = nil
+			csv.Spec.CustomResourceDefinitions.Owned[i].StatusDescriptors = nil
+			csv.Spec.CustomResourceDefinitions.Owned[i].ActionDescriptor = nil
+		}
+		for i := range csv.Spec.APIServiceDefinitions.Owned {
+			csv.Spec.APIServiceDefinitions.Owned[i].SpecDescriptors = nil
+			csv.Spec.APIServiceDefinitions.Owned[i].StatusDescriptors = nil
+			csv.Spec.APIServiceDefinitions.Owned[i].ActionDescriptor = nil
+		}
+		c.BundleCSV = csv
+	}
+}
+
 func (c *Column) SetMaxOpenshiftVersion() {
 
 	if c.BundleCSV != nil {
@@ -15,31 +15,93 @@
 package bundles
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	sq "github.com/Masterminds/squirrel"
 	"github.com/operator-framework/audit/pkg"
 
 	"github.com/operator-framework/audit/pkg/models"
 )
 
+// ErrNoData is returned when no bundle was found for the criteria informed
+var ErrNoData = &pkg.ExitError{Code: pkg.ExitNoData, Err: errors.New("no data was found for the criteria informed. " +
+	"Please, ensure that you provide valid information")}
+
 type Data struct {
 	AuditBundle       []models.AuditBundle
 	Flags             BindFlags
 	IndexImageInspect pkg.DockerInspect
+	// OnColumn is called for each bundle added to the report, e.g. to check the thresholds informed via --fail-on
+	OnColumn func(Column) error
+	stream   *StreamWriter
+	// streamPackages are the package names of the bundles streamed by their image path and streamPending are the
+	// bundles without package name which are only streamed when the report is closed.
+	// See fixPackageNameInconsistency
+	streamPackages map[string]string
+	streamPending  []models.AuditBundle
+}
+
+// StartStream creates the temporary report file so that the bundles added are written to the disk as they are audited
+// instead of kept in memory. It is only supported for the JSON output format.
+func (d *Data) StartStream() error {
+	if d.Flags.OutputFormat != pkg.JSON {
+		return fmt.Errorf("the report can only be streamed in the %s format", pkg.JSON)
+	}
+	stream, err := NewStreamWriter(d.Flags, d.IndexImageInspect, time.Now().Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	d.stream = stream
+	d.streamPackages = make(map[string]string)
+	return nil
+}
+
+// AddAuditBundle adds the bundle audited to the report. When the report is streamed the bundle is written
+// to the disk and not kept in memory.
+func (d *Data) AddAuditBundle(auditBundle models.AuditBundle) error {
+	if d.stream == nil {
+		d.AuditBundle = append(d.AuditBundle, auditBundle)
+		return nil
+	}
+
+	// do not add bundle which has not the label
+	if len(d.Flags.Label) > 0 && !auditBundle.FoundLabel {
+		return nil
+	}
+
+	// the package name can only be fixed when all bundles were audited
+	if auditBundle.PackageName == "" {
+		d.streamPending = append(d.streamPending, auditBundle)
+		return nil
+	}
+	d.streamPackages[auditBundle.OperatorBundleImagePath] = auditBundle.PackageName
+	return d.writeColumn(auditBundle)
+}
+
+// writeColumn writes the column of the bundle to the report streamed
+func (d *Data) writeColumn(auditBundle models.AuditBundle) error {
+	col := NewColumn(auditBundle)
+	col.ApplyCSVDetail(d.Flags.CSVDetail)
+	if d.OnColumn != nil {
+		if err := d.OnColumn(*col); err != nil {
+			return err
+		}
+	}
+	return d.stream.Write(*col)
 }
 
-func (d *Data) PrepareReport() Report {
+// PrepareReport returns the report with the bundles audited. It returns ErrNoData when no bundle was found.
+func (d *Data) PrepareReport() (Report, error) {
 	d.fixPackageNameInconsistency()
 
 	var allColumns []Column
 	for _, v := range d.AuditBundle {
 		col := NewColumn(v)
+		col.ApplyCSVDetail(d.Flags.CSVDetail)
 
 		// do not add bundle which has not the label
 		if len(d.Flags.Label) > 0 && !v.FoundLabel {
@@ -54,6 +116,7 @@ func (d *Data) PrepareReport() Report {
 	})
 
 	finalReport := Report{}
+	finalReport.SchemaVersion = SchemaVersion
 	finalReport.Flags = d.Flags
 	finalReport.Columns = allColumns
 	finalReport.IndexImageInspect = d.IndexImageInspect
@@ -62,47 +125,100 @@ func (d *Data) PrepareReport() Report {
 	finalReport.GenerateAt = dt
 
 	if len(allColumns) == 0 {
-		log.Fatal("No data was found for the criteria informed. " +
-			"Please, ensure that you provide valid information.")
+		return finalReport, ErrNoData
 	}
 
-	return finalReport
+	return finalReport, nil
 }
 
 // fix inconsistency in the index db
 // some packages are empty then, we get them by looking for the bundles
 // which are publish with the same registry path
 func (d *Data) fixPackageNameInconsistency() {
+	packages := make(map[string]string)
 	for _, auditBundle := range d.AuditBundle {
-		if auditBundle.PackageName == "" {
-			split := strings.Split(auditBundle.OperatorBundleImagePath, "/")
-			nm := ""
-			for _, v := range split {
-				if strings.Contains(v, "@") {
-					nm = strings.Split(v, "@")[0]
-					break
-				}
-			}
-			for _, bundle := range d.AuditBundle {
-				if strings.Contains(bundle.OperatorBundleImagePath, nm) {
-					auditBundle.PackageName = bundle.PackageName
-				}
-			}
+		if auditBundle.PackageName != "" {
+			packages[auditBundle.OperatorBundleImagePath] = auditBundle.PackageName
+		}
+	}
+	for i := range d.AuditBundle {
+		if d.AuditBundle[i].PackageName == "" {
+			d.AuditBundle[i].PackageName = packageNameFromImagePath(d.AuditBundle[i].OperatorBundleImagePath,
+				packages)
 		}
 	}
 }
 
+// packageNameFromImagePath returns the package name of the bundles which are published with the same
+// registry path of the image informed
+func packageNameFromImagePath(imagePath string, packages map[string]string) string {
+	nm := ""
+	for _, v := range strings.Split(imagePath, "/") {
+		if strings.Contains(v, "@") {
+			nm = strings.Split(v, "@")[0]
+			break
+		}
+	}
+	if nm == "" {
+		return ""
+	}
+	for _, path := range pkg.SortedKeys(packages) {
+		if strings.Contains(path, nm) {
+			return packages[path]
+		}
+	}
+	return ""
+}
+
+// closeStream writes the bundles without package name, fixing it when possible, and closes the report streamed
+func (d *Data) closeStream() error {
+	var err error
+	for _, auditBundle := range d.streamPending {
+		auditBundle.PackageName = packageNameFromImagePath(auditBundle.OperatorBundleImagePath, d.streamPackages)
+		if errWrite := d.writeColumn(auditBundle); errWrite != nil && err == nil {
+			err = fmt.Errorf("unable to add the bundle %s to the report: %s", auditBundle.OperatorBundleName,
+				errWrite)
+		}
+	}
+	d.streamPending = nil
+	if errClose := d.stream.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// AbortStream removes the report streamed without writing it, e.g. when the bundles could not be audited
+func (d *Data) AbortStream() error {
+	if d.stream == nil {
+		return nil
+	}
+	err := d.stream.Abort()
+	d.stream = nil
+	d.streamPending = nil
+	return err
+}
+
 func (d *Data) OutputReport() error {
-	report := d.PrepareReport()
-	switch d.Flags.OutputFormat {
-	case pkg.JSON:
-		if err := report.writeJSON(); err != nil {
-			return err
+	if d.stream != nil {
+		return d.closeStream()
+	}
+
+	formatter, err := GetFormatter(d.Flags.OutputFormat)
+	if err != nil {
+		return err
+	}
+	report, err := d.PrepareReport()
+	if err != nil {
+		return err
+	}
+	if d.OnColumn != nil {
+		for _, col := range report.Columns {
+			if err := d.OnColumn(col); err != nil {
+				return err
+			}
 		}
-	default:
-		return fmt.Errorf("invalid output format : %s", d.Flags.OutputFormat)
 	}
-	return nil
+	return report.write(formatter)
 }
 
 func (d *Data) BuildBundlesQuery() (string, error) {
@@ -16,17 +16,36 @@ package bundles
 
 // BindFlags define the flags used to generate the bundle report
 type BindFlags struct {
-	IndexImage                string `json:"image"`
-	Limit                     int32  `json:"limit"`
-	HeadOnly                  bool   `json:"headOnly"`
-	DisableScorecard          bool   `json:"disableScorecard"`
-	DisableValidators         bool   `json:"disableValidators"`
-	StaticCheckFIPSCompliance bool   `json:"staticCheckFIPSCompliance"`
-	ServerMode                bool   `json:"serverMode"`
-	Label                     string `json:"label"`
-	LabelValue                string `json:"labelValue"`
-	Filter                    string `json:"filter"`
-	OutputPath                string `json:"outputPath"`
-	OutputFormat              string `json:"outputFormat"`
-	ContainerEngine           string `json:"containerEngine"`
+	IndexImage                string   `json:"image"`
+	Limit                     int32    `json:"limit"`
+	HeadOnly                  bool     `json:"headOnly"`
+	DisableScorecard          bool     `json:"disableScorecard"`
+	DisableValidators         bool     `json:"disableValidators"`
+	StaticCheckFIPSCompliance bool     `json:"staticCheckFIPSCompliance"`
+	ServerMode                bool     `json:"serverMode"`
+	Label                     string   `json:"label"`
+	LabelValue                string   `json:"labelValue"`
+	Filter                    string   `json:"filter"`
+	OutputPath                string   `json:"outputPath"`
+	OutputFormat              string   `json:"outputFormat"`
+	CSVDetail                 string   `json:"csvDetail,omitempty"`
+	ContainerEngine           string   `json:"containerEngine"`
+	BundleSizeWarnPercent     float64  `json:"bundleSizeWarnPercent,omitempty"`
+	CheckImages               bool     `json:"checkImages,omitempty"`
+	SignatureKeys             []string `json:"signatureKeys,omitempty"`
+	VulnerabilityDB           string   `json:"vulnerabilityDB,omitempty"`
+	SBOMDir                   string   `json:"sbomDir,omitempty"`
+	GenerateSBOMs             bool     `json:"generateSBOMs,omitempty"`
 }
+
+// Values allowed for the CSVDetail flag which define how much of the CSV is embedded in the report
+const (
+	// CSVDetailFull embeds the whole CSV
+	CSVDetailFull = "full"
+	// CSVDetailSummary embeds the CSV without the data which is not used by the custom reports
+	// such as the icon, the description, the alm-examples and the descriptors of the owned APIs. Note that the APIs
+	// removed used by the CRs of the alm-examples are not checked without them.
+	CSVDetailSummary = "summary"
+	// CSVDetailNone does not embed the CSV. Note that the custom reports cannot be generated from it.
+	CSVDetailNone = "none"
+)
@@ -0,0 +1,240 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/validation"
+)
+
+// Output formats supported by the bundles report
+const (
+	CSV      = "csv"
+	Markdown = "markdown"
+	SARIF    = "sarif"
+	JUnit    = "junit"
+	HTML     = "html"
+)
+
+// Checks done per bundle which are reported by the formatters
+const (
+	checkValidators = "validators"
+	checkScorecard  = "scorecard"
+	checkAudit      = "audit"
+)
+
+// Formatter writes the bundles report in a specific format
+type Formatter interface {
+	// Format writes the report into w
+	Format(w io.Writer, r *Report) error
+	// Extension returns the extension of the file which will be created
+	Extension() string
+}
+
+var formatters = map[string]Formatter{
+	pkg.JSON: jsonFormatter{},
+	CSV:      csvFormatter{},
+	Markdown: markdownFormatter{},
+	SARIF:    sarifFormatter{},
+	JUnit:    junitFormatter{},
+	HTML:     htmlFormatter{},
+}
+
+// GetFormatter returns the formatter for the output format informed
+func GetFormatter(format string) (Formatter, error) {
+	formatter, ok := formatters[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid output format : %s. The available options are: %s",
+			format, strings.Join(OutputFormats(), ", "))
+	}
+	return formatter, nil
+}
+
+// OutputFormats returns the output formats supported by the bundles report
+func OutputFormats() []string {
+	var formats []string
+	for k := range formatters {
+		formats = append(formats, k)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// write creates the report file in the output path with the formatter informed
+func (r *Report) write(formatter Formatter) error {
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, r); err != nil {
+		return err
+	}
+
+	const reportType = "bundles"
+	path := filepath.Join(r.Flags.OutputPath, pkg.GetReportName(r.Flags.IndexImage, reportType,
+		formatter.Extension()))
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// BundleName returns the name of the CSV or the bundle image path when the CSV is not found
+func (c Column) BundleName() string {
+	if c.BundleCSV != nil && len(c.BundleCSV.Name) > 0 {
+		return c.BundleCSV.Name
+	}
+	return c.BundleImagePath
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Extension() string { return pkg.JSON }
+
+func (jsonFormatter) Format(w io.Writer, r *Report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, data, "", "\t"); err != nil {
+		return err
+	}
+	_, err = w.Write(prettyJSON.Bytes())
+	return err
+}
+
+type csvFormatter struct{}
+
+func (csvFormatter) Extension() string { return CSV }
+
+// Format writes one row per bundle. The values with many items are split by new lines in the same cell.
+func (csvFormatter) Format(w io.Writer, r *Report) error {
+	writer := csv.NewWriter(w)
+	header := []string{"Package Name", "Bundle Name", "Version", "Bundle Image Path", "Channels",
+		"Default Channel", "Is Head of Channel", "Is Deprecated", "Max OCP Version", "Bundle Size (compressed)",
+		"Validator Errors", "Validator Warnings", "Scorecard Failing Tests", "Scorecard Errors",
+		"Scorecard Suggestions", "Audit Errors", "Critical Vulnerabilities", "High Vulnerabilities"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range r.Columns {
+		version := ""
+		if c.BundleCSV != nil {
+			version = c.BundleCSV.Spec.Version.String()
+		}
+		size := ""
+		if c.BundleSize != nil {
+			size = validation.FormatBytesInUnit(c.BundleSize.CompressedSize)
+		}
+		// the vulnerabilities are only informed when the report was generated with --vulnerability-db
+		critical, high := "", ""
+		if c.Vulnerabilities != nil {
+			critical = strconv.Itoa(c.Vulnerabilities.Critical)
+			high = strconv.Itoa(c.Vulnerabilities.High)
+		}
+		row := []string{
+			c.PackageName,
+			c.BundleName(),
+			version,
+			c.BundleImagePath,
+			strings.Join(c.Channels, "\n"),
+			c.DefaultChannel,
+			strconv.FormatBool(c.IsHeadOfChannel),
+			strconv.FormatBool(c.IsDeprecated),
+			c.MaxOCPVersion,
+			size,
+			strings.Join(c.ValidatorErrors, "\n"),
+			strings.Join(c.ValidatorWarnings, "\n"),
+			strings.Join(c.ScorecardFailingTests, "\n"),
+			strings.Join(c.ScorecardErrors, "\n"),
+			strings.Join(c.ScorecardSuggestions, "\n"),
+			strings.Join(c.AuditErrors, "\n"),
+			critical,
+			high,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+type markdownFormatter struct{}
+
+func (markdownFormatter) Extension() string { return "md" }
+
+// Format writes a summary table with one row per bundle followed by the details of the bundles with findings
+func (markdownFormatter) Format(w io.Writer, r *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Bundles report\n\n")
+	fmt.Fprintf(&b, "- Image name: %s\n", r.Flags.IndexImage)
+	fmt.Fprintf(&b, "- Image ID: %s\n", r.IndexImageInspect.ID)
+	fmt.Fprintf(&b, "- Image Created at: %s\n", r.IndexImageInspect.Created)
+	fmt.Fprintf(&b, "- Generated at: %s\n\n", r.GenerateAt)
+
+	fmt.Fprintf(&b, "| Package | Bundle | Channels | Head | Deprecated | Validator Errors | Validator Warnings "+
+		"| Scorecard Failing Tests | Audit Errors |\n")
+	fmt.Fprintf(&b, "|---|---|---|---|---|---|---|---|---|\n")
+	for _, c := range r.Columns {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d | %d | %d | %d |\n",
+			escapeMarkdown(c.PackageName),
+			escapeMarkdown(c.BundleName()),
+			escapeMarkdown(strings.Join(c.Channels, ", ")),
+			pkg.GetYesOrNo(c.IsHeadOfChannel),
+			pkg.GetYesOrNo(c.IsDeprecated),
+			len(c.ValidatorErrors),
+			len(c.ValidatorWarnings),
+			len(c.ScorecardFailingTests),
+			len(c.AuditErrors))
+	}
+
+	for _, c := range r.Columns {
+		if len(c.ValidatorErrors)+len(c.ValidatorWarnings)+len(c.ScorecardFailingTests)+len(c.AuditErrors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", escapeMarkdown(c.BundleName()))
+		writeMarkdownList(&b, "Validator Errors", c.ValidatorErrors)
+		writeMarkdownList(&b, "Validator Warnings", c.ValidatorWarnings)
+		writeMarkdownList(&b, "Scorecard Failing Tests", c.ScorecardFailingTests)
+		writeMarkdownList(&b, "Audit Errors", c.AuditErrors)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, v := range items {
+		fmt.Fprintf(b, "- %s\n", escapeMarkdown(v))
+	}
+}
+
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+func TestFormatters(t *testing.T) {
+	report := &Report{
+		Flags: BindFlags{IndexImage: "quay.io/example/index:latest", DisableScorecard: true},
+		Columns: []Column{
+			{
+				PackageName:     "memcached-operator",
+				BundleCSV:       &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "memcached.v0.0.1"}},
+				Channels:        []string{"alpha", "beta"},
+				ValidatorErrors: []string{"Error: Value memcached.v0.0.1: invalid | value"},
+				AuditErrors:     []string{"unable to pull the bundle"},
+			},
+			{
+				PackageName:       "memcached-operator",
+				BundleImagePath:   "quay.io/example/memcached-bundle:v0.0.2",
+				ValidatorWarnings: []string{"Warning: Value memcached.v0.0.2: check it"},
+				Vulnerabilities: &models.VulnerabilitySummary{
+					VulnerabilityCounts: models.VulnerabilityCounts{Critical: 2, High: 3}},
+			},
+		},
+	}
+
+	tests := []struct {
+		format   string
+		contains []string
+	}{
+		{
+			format:   CSV,
+			contains: []string{"Package Name,Bundle Name", "memcached-operator,memcached.v0.0.1", "\"alpha\nbeta\""},
+		},
+		{
+			format: Markdown,
+			contains: []string{"| memcached-operator | memcached.v0.0.1 | alpha, beta | NO | NO | 1 | 0 | 0 | 1 |",
+				"invalid \\| value", "## quay.io/example/memcached-bundle:v0.0.2"},
+		},
+		{
+			format: SARIF,
+			contains: []string{"\"version\": \"2.1.0\"", "\"ruleId\": \"validator-error\"",
+				"\"ruleId\": \"validator-warning\"", "\"ruleId\": \"audit-error\"",
+				"\"fullyQualifiedName\": \"memcached-operator/memcached.v0.0.1\""},
+		},
+		{
+			format: JUnit,
+			contains: []string{"<testsuites name=\"audit quay.io/example/index:latest\" tests=\"6\" failures=\"2\" skipped=\"2\">",
+				"<testcase name=\"validators\" classname=\"memcached.v0.0.1\">",
+				"<skipped message=\"scorecard is disabled\"></skipped>"},
+		},
+		{
+			format: HTML,
+			contains: []string{"\"name\":\"memcached.v0.0.1\"", "\"channels\":[\"alpha\",\"beta\"]",
+				"{\"type\":\"validators\",\"severity\":\"error\",\"message\":\"Error: Value memcached.v0.0.1: invalid | value\"}",
+				"{\"type\":\"audit\",\"severity\":\"error\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, err := GetFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("GetFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, report); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Format() output does not contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	// the HTML report must work offline
+	var buf bytes.Buffer
+	if err := (htmlFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "src=") || strings.Contains(buf.String(), "href=") {
+		t.Errorf("the HTML report should not load any resource")
+	}
+
+	// the vulnerabilities columns are the last ones so that the positions of the previous columns are kept
+	buf.Reset()
+	if err := (csvFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Audit Errors,Critical Vulnerabilities,High Vulnerabilities\n") ||
+		!strings.Contains(buf.String(), ",2,3\n") {
+		t.Errorf("the vulnerabilities should be the last columns, got:\n%s", buf.String())
+	}
+
+	if _, err := GetFormatter("xlsx"); err == nil {
+		t.Errorf("GetFormatter() expected error for invalid format")
+	}
+}
+
+func TestSARIFLocations(t *testing.T) {
+	tests := []struct {
+		name    string
+		column  Column
+		wantURI string
+	}{
+		{
+			name: "should locate the result by the bundle image",
+			column: Column{PackageName: "etcd", BundleImagePath: "quay.io/example/etcd-bundle:v0.9.4",
+				BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "etcd.v0.9.4"}}},
+			wantURI: "quay.io/example/etcd-bundle:v0.9.4",
+		},
+		{
+			name: "should locate the result by the CSV when the bundle image is unknown",
+			column: Column{PackageName: "etcd",
+				BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "etcd.v0.9.4"}}},
+			wantURI: "manifests/etcd.v0.9.4.clusterserviceversion.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.column.AuditErrors = []string{"unable to check"}
+			var buf bytes.Buffer
+			if err := (sarifFormatter{}).Format(&buf, &Report{Columns: []Column{tt.column}}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			var log sarifLog
+			if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+				t.Fatalf("unable to parse the SARIF output: %v", err)
+			}
+			location := log.Runs[0].Results[0].Locations[0]
+			if location.PhysicalLocation.ArtifactLocation.URI != tt.wantURI ||
+				location.LogicalLocations[0].FullyQualifiedName != "etcd/etcd.v0.9.4" {
+				t.Errorf("unexpected location: %+v", location)
+			}
+		})
+	}
+}
+
+func TestSARIFValidatorRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    string
+		wantID string
+	}{
+		{
+			name: "should use the rule of the check",
+			msg: "Warning: Value etcd.v0.9.4: this bundle is using APIs which were deprecated and removed in " +
+				"v1.22. More info: https://kubernetes.io/docs/reference/using-api/deprecation-guide/#v1-22",
+			wantID: "validator/removed-apis",
+		},
+		{
+			name:   "should use the same rule for the checks with more than one message",
+			msg:    "Warning: Value etcd.v0.9.4: bundle uncompressed size exceeded the limit support",
+			wantID: "validator/bundle-size",
+		},
+		{
+			name:   "should use the rule of the check for the compressed size",
+			msg:    "Warning: Value etcd.v0.9.4: nearing limit of 1 MiB bundle compressed size",
+			wantID: "validator/bundle-size",
+		},
+		{
+			name:   "should use the generic rule when the check is unknown",
+			msg:    "Warning: Value etcd.v0.9.4: check it",
+			wantID: ruleValidatorWarning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &Report{Columns: []Column{{PackageName: "etcd", ValidatorWarnings: []string{tt.msg}}}}
+			var buf bytes.Buffer
+			if err := (sarifFormatter{}).Format(&buf, report); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, want := range []string{"\"ruleId\": \"" + tt.wantID + "\"", "\"id\": \"" + tt.wantID + "\""} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Format() output does not contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
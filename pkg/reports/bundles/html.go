@@ -0,0 +1,110 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"embed"
+	"html/template"
+	"io"
+)
+
+//go:embed interactive_template.go.tmpl
+var interactiveTemplate embed.FS
+
+// Types of the findings shown in the interactive HTML report
+const (
+	severityError       = "error"
+	severityWarning     = "warning"
+	severitySuggestion  = "suggestion"
+	severityFailingTest = "failing-test"
+)
+
+// htmlBundle is the data of the bundle embedded in the interactive HTML report. The CSV is not embedded so that the
+// file stays small enough to be attached to tickets.
+type htmlBundle struct {
+	Package         string        `json:"package"`
+	Name            string        `json:"name"`
+	Version         string        `json:"version,omitempty"`
+	Channels        []string      `json:"channels,omitempty"`
+	DefaultChannel  string        `json:"defaultChannel,omitempty"`
+	IsHeadOfChannel bool          `json:"head"`
+	IsFromDefault   bool          `json:"fromDefaultChannel"`
+	IsDeprecated    bool          `json:"deprecated"`
+	MaxOCPVersion   string        `json:"maxOCPVersion,omitempty"`
+	Findings        []htmlFinding `json:"findings,omitempty"`
+}
+
+type htmlFinding struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type htmlReport struct {
+	Image      string       `json:"image"`
+	ImageID    string       `json:"imageID,omitempty"`
+	GenerateAt string       `json:"generateAt"`
+	Bundles    []htmlBundle `json:"bundles"`
+}
+
+type htmlFormatter struct{}
+
+func (htmlFormatter) Extension() string { return HTML }
+
+// Format writes a single HTML file, which works offline, with the data of the report embedded and the scripts to
+// search, sort and filter the findings of the bundles
+func (htmlFormatter) Format(w io.Writer, r *Report) error {
+	data := htmlReport{Image: r.Flags.IndexImage, ImageID: r.IndexImageInspect.ID, GenerateAt: r.GenerateAt,
+		Bundles: make([]htmlBundle, 0, len(r.Columns))}
+	for _, c := range r.Columns {
+		data.Bundles = append(data.Bundles, newHTMLBundle(c))
+	}
+
+	t := template.Must(template.ParseFS(interactiveTemplate, "interactive_template.go.tmpl"))
+	return t.Execute(w, data)
+}
+
+func newHTMLBundle(c Column) htmlBundle {
+	b := htmlBundle{
+		Package:         c.PackageName,
+		Name:            c.BundleName(),
+		Channels:        c.Channels,
+		DefaultChannel:  c.DefaultChannel,
+		IsHeadOfChannel: c.IsHeadOfChannel,
+		IsFromDefault:   c.IsFromDefaultChannel,
+		IsDeprecated:    c.IsDeprecated,
+		MaxOCPVersion:   c.MaxOCPVersion,
+	}
+	if c.BundleCSV != nil {
+		b.Version = c.BundleCSV.Spec.Version.String()
+	}
+
+	for _, f := range []struct {
+		check, severity string
+		messages        []string
+	}{
+		{checkValidators, severityError, c.ValidatorErrors},
+		{checkValidators, severityWarning, c.ValidatorWarnings},
+		{checkScorecard, severityError, c.ScorecardErrors},
+		{checkScorecard, severitySuggestion, c.ScorecardSuggestions},
+		{checkScorecard, severityFailingTest, c.ScorecardFailingTests},
+		{checkAudit, severityError, c.AuditErrors},
+	} {
+		for _, message := range f.messages {
+			b.Findings = append(b.Findings, htmlFinding{Type: f.check, Severity: f.severity, Message: message})
+		}
+	}
+	return b
+}
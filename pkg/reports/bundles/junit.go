@@ -0,0 +1,139 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The types below are the subset of the JUnit XML format which is understood by the CI tools
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFormatter struct{}
+
+func (junitFormatter) Extension() string { return "xml" }
+
+// Format writes one test suite per package with one test case per bundle per check
+// (validators, scorecard and audit)
+func (junitFormatter) Format(w io.Writer, r *Report) error {
+	suites := junitTestSuites{Name: fmt.Sprintf("audit %s", r.Flags.IndexImage)}
+	suitePerPackage := map[string]int{}
+
+	for _, c := range r.Columns {
+		idx, ok := suitePerPackage[c.PackageName]
+		if !ok {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: c.PackageName})
+			idx = len(suites.Suites) - 1
+			suitePerPackage[c.PackageName] = idx
+		}
+		suite := &suites.Suites[idx]
+
+		validators := junitTestCase{Name: checkValidators, ClassName: c.BundleName()}
+		switch {
+		case r.Flags.DisableValidators:
+			validators.Skipped = &junitSkipped{Message: "validators are disabled"}
+		case len(c.ValidatorErrors) > 0:
+			validators.Failure = newJUnitFailure("validator errors found", c.ValidatorErrors)
+		}
+		if len(c.ValidatorWarnings) > 0 {
+			validators.SystemOut = strings.Join(c.ValidatorWarnings, "\n")
+		}
+
+		scorecard := junitTestCase{Name: checkScorecard, ClassName: c.BundleName()}
+		switch {
+		case r.Flags.DisableScorecard:
+			scorecard.Skipped = &junitSkipped{Message: "scorecard is disabled"}
+		case len(c.ScorecardFailingTests) > 0 || len(c.ScorecardErrors) > 0:
+			scorecard.Failure = newJUnitFailure(fmt.Sprintf("scorecard tests failing: %s",
+				strings.Join(c.ScorecardFailingTests, ", ")), c.ScorecardErrors)
+		}
+		if len(c.ScorecardSuggestions) > 0 {
+			scorecard.SystemOut = strings.Join(c.ScorecardSuggestions, "\n")
+		}
+
+		audit := junitTestCase{Name: checkAudit, ClassName: c.BundleName()}
+		if len(c.AuditErrors) > 0 {
+			audit.Failure = newJUnitFailure("audit was unable to check the bundle", c.AuditErrors)
+		}
+
+		for _, tc := range []junitTestCase{validators, scorecard, audit} {
+			suite.TestCases = append(suite.TestCases, tc)
+			suite.Tests++
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+			if tc.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Skipped += s.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newJUnitFailure(message string, details []string) *junitFailure {
+	return &junitFailure{Message: message, Type: "failure", Content: strings.Join(details, "\n")}
+}
@@ -15,24 +15,14 @@
 package bundles
 
 import (
-	"encoding/json"
-
 	"github.com/operator-framework/audit/pkg"
 )
 
+// Report is the bundles report. See the JSON Schema which it follows in the schema directory.
 type Report struct {
-	Columns           []Column
-	Flags             BindFlags
-	IndexImageInspect pkg.DockerInspect
-	GenerateAt        string
-}
-
-func (r *Report) writeJSON() error {
-	data, err := json.Marshal(r)
-	if err != nil {
-		return err
-	}
-
-	const reportType = "bundles"
-	return pkg.WriteJSON(data, r.Flags.IndexImage, r.Flags.OutputPath, reportType)
+	SchemaVersion     string            `json:"schemaVersion"`
+	Columns           []Column          `json:"columns"`
+	Flags             BindFlags         `json:"flags"`
+	IndexImageInspect pkg.DockerInspect `json:"indexImageInspect"`
+	GenerateAt        string            `json:"generateAt"`
 }
@@ -15,7 +15,13 @@
 package bundles
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/models"
 )
 
 func TestBuildQuery(t *testing.T) {
@@ -62,3 +68,75 @@ func TestBuildQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareReport(t *testing.T) {
+	data := Data{Flags: BindFlags{Label: "my-label"}}
+	data.AuditBundle = []models.AuditBundle{{PackageName: "foo", OperatorBundleImagePath: "foo:v0.1.0"}}
+
+	_, err := data.PrepareReport()
+	if !errors.Is(err, ErrNoData) || pkg.ExitCode(err) != pkg.ExitNoData {
+		t.Errorf("expected ErrNoData when no bundle has the label, got %v", err)
+	}
+
+	data.Flags.Label = ""
+	report, err := data.PrepareReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Columns) != 1 {
+		t.Errorf("got %d columns, want 1", len(report.Columns))
+	}
+}
+
+func TestFixPackageNameInconsistency(t *testing.T) {
+	auditBundles := func() []models.AuditBundle {
+		return []models.AuditBundle{
+			{OperatorBundleName: "foo.v0.2.0", OperatorBundleImagePath: "quay.io/example/foo-bundle@sha256:456"},
+			{PackageName: "foo", OperatorBundleName: "foo.v0.1.0",
+				OperatorBundleImagePath: "quay.io/example/foo-bundle@sha256:123"},
+		}
+	}
+
+	data := Data{AuditBundle: auditBundles()}
+	report, err := data.PrepareReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range report.Columns {
+		if col.PackageName != "foo" {
+			t.Errorf("PrepareReport() got package name %q for %s, want foo", col.PackageName, col.BundleImagePath)
+		}
+	}
+
+	streamed := Data{Flags: BindFlags{IndexImage: "quay.io/example/index:latest", OutputPath: t.TempDir(),
+		OutputFormat: pkg.JSON}}
+	if err := streamed.StartStream(); err != nil {
+		t.Fatal(err)
+	}
+	for _, auditBundle := range auditBundles() {
+		if err := streamed.AddAuditBundle(auditBundle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := streamed.OutputReport(); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filepath.Join(streamed.Flags.OutputPath,
+		pkg.GetReportName(streamed.Flags.IndexImage, "bundles", pkg.JSON)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	report, err = DecodeReport(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Columns) != 2 {
+		t.Fatalf("got %d columns streamed, want 2", len(report.Columns))
+	}
+	for _, col := range report.Columns {
+		if col.PackageName != "foo" {
+			t.Errorf("streamed package name %q for %s, want foo", col.PackageName, col.BundleImagePath)
+		}
+	}
+}
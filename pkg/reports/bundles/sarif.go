@@ -0,0 +1,219 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+const sarifVersion = "2.1.0"
+const auditInformationURI = "https://github.com/operator-framework/audit"
+
+// SARIF levels
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+)
+
+// Rules used to report the findings which are not from scorecard tests. The validator errors and warnings
+// which do not match any of the validatorChecks are reported with the generic validator rules.
+const (
+	ruleValidatorError   = "validator-error"
+	ruleValidatorWarning = "validator-warning"
+	ruleAuditError       = "audit-error"
+	ruleScorecardPrefix  = "scorecard/"
+	ruleValidatorPrefix  = "validator/"
+)
+
+// validatorCheck identifies a check of the validators by the text found in its messages
+type validatorCheck struct {
+	id          string
+	description string
+	text        string
+}
+
+// validatorChecks are the checks of the validators which are reported with their own rule
+var validatorChecks = []validatorCheck{
+	{"removed-apis", "Bundle uses APIs which were deprecated and removed", "apis which were deprecated and removed"},
+	{"alm-examples", "Provided APIs should have an example annotation", "should have an example annotation"},
+	{"resource-requests", "Containers should define the resource requests", "unable to find the resource requests"},
+	{"channel-naming", "Channels should follow the recommended naming convention",
+		"not following the recommended naming convention"},
+	{"crd-permissions", "CSV should not request permissions to create CRDs", "permissions to create crd"},
+	{"olm-properties", "Properties should be defined in metadata/properties.yaml", "olm.properties annotation"},
+	{"service-account", "Bundle should not ship the service accounts of the CSV", "invalid service account"},
+	{"crd-description", "Owned CRDs should have a description", "has an empty description"},
+	{"max-openshift-version", "Bundle should inform the olm.maxOpenShiftVersion", "olm.maxopenshiftversion"},
+	{"bundle-size", "Bundle size should not exceed the limit supported by OLM", "compressed size"},
+	{"crd-upgrade", "CRDs should be compatible with the CRDs of the replaced bundle", "upgrade from "},
+}
+
+// validatorRule returns the rule of the check which raised the validator error or warning
+func validatorRule(msg string, ruleID, description string) (string, string) {
+	lower := strings.ToLower(msg)
+	for _, check := range validatorChecks {
+		if strings.Contains(lower, check.text) {
+			return ruleValidatorPrefix + check.id, check.description
+		}
+	}
+	return ruleID, description
+}
+
+// The types below are a subset of the SARIF 2.1.0 specification required to report the findings
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifFormatter struct{}
+
+func (sarifFormatter) Extension() string { return SARIF }
+
+// Format maps the validators errors and warnings, the scorecard failing tests and the audit errors
+// to rules. Each scorecard test and each known validator check is a rule. The results are located by
+// the bundle (package/bundle) and by its image or, when the image is unknown, by the path of its CSV.
+func (sarifFormatter) Format(w io.Writer, r *Report) error {
+	rules := map[string]string{}
+	results := []sarifResult{}
+
+	for _, c := range r.Columns {
+		add := func(ruleID, description, level, msg string) {
+			rules[ruleID] = description
+			results = append(results, sarifResult{
+				RuleID:  ruleID,
+				Level:   level,
+				Message: sarifMessage{Text: msg},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: artifactURI(c)}},
+					LogicalLocations: []sarifLogicalLocation{{
+						Name:               c.BundleName(),
+						FullyQualifiedName: fmt.Sprintf("%s/%s", c.PackageName, c.BundleName()),
+						Kind:               "module",
+					}},
+				}},
+				Properties: map[string]string{
+					"packageName":     c.PackageName,
+					"bundleImagePath": c.BundleImagePath,
+				},
+			})
+		}
+
+		for _, v := range c.ValidatorErrors {
+			ruleID, description := validatorRule(v, ruleValidatorError, "Bundle validator check failed")
+			add(ruleID, description, sarifError, v)
+		}
+		for _, v := range c.ValidatorWarnings {
+			ruleID, description := validatorRule(v, ruleValidatorWarning,
+				"Bundle validator check raised a warning")
+			add(ruleID, description, sarifWarning, v)
+		}
+		for _, v := range c.ScorecardFailingTests {
+			add(ruleScorecardPrefix+v, fmt.Sprintf("Scorecard test %s", v), sarifError,
+				fmt.Sprintf("the scorecard test %s failed for the bundle %s", v, c.BundleName()))
+		}
+		for _, v := range c.AuditErrors {
+			add(ruleAuditError, "Audit was unable to check the bundle", sarifError, v)
+		}
+	}
+
+	driver := sarifDriver{Name: "audit", InformationURI: auditInformationURI, Rules: []sarifRule{}}
+	for _, id := range sortedRuleIDs(rules) {
+		driver.Rules = append(driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: rules[id]}})
+	}
+
+	sarifReport := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(sarifReport)
+}
+
+// artifactURI returns the bundle image path or, when it is unknown, the path of the CSV in the bundle
+func artifactURI(c Column) string {
+	if len(c.BundleImagePath) > 0 {
+		return c.BundleImagePath
+	}
+	return fmt.Sprintf("manifests/%s.clusterserviceversion.yaml", c.BundleName())
+}
+
+func sortedRuleIDs(rules map[string]string) []string {
+	var ids []string
+	for k := range rules {
+		ids = append(ids, k)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"bufio"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// SchemaVersion is the version of the schema followed by the bundles reports generated
+const SchemaVersion = "v2"
+
+// SchemaVersionV1 is the version of the reports generated before the schema was versioned. These reports have no
+// schemaVersion field and use the Go names of the fields as the top-level keys (e.g. Columns, IndexImageInspect).
+const SchemaVersionV1 = "v1"
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+// SchemaFile returns the path of the JSON Schema of the current version in the repository
+func SchemaFile() string {
+	return fmt.Sprintf("pkg/reports/bundles/schema/bundles_report_%s.json", SchemaVersion)
+}
+
+// Schema returns the JSON Schema of the current version of the bundles report
+func Schema() ([]byte, error) {
+	return schemaFS.ReadFile(fmt.Sprintf("schema/bundles_report_%s.json", SchemaVersion))
+}
+
+// migration defines how the data of a schema version is migrated to the next one
+type migration struct {
+	next   string
+	column func(*Column)
+	report func(*Report)
+}
+
+// migrations are applied in chain from the version of the report until the current one
+var migrations = map[string]migration{
+	// The keys of the v1 reports are decoded as is since the keys are matched case-insensitively.
+	// e.g. the key Columns is decoded into columns and the key Type of the propertiesFromDB into type.
+	SchemaVersionV1: {next: SchemaVersion},
+}
+
+// migrateColumn applies to the column the migrations from the version informed until the current one
+func migrateColumn(version string, col *Column) error {
+	for version != SchemaVersion {
+		m, ok := migrations[version]
+		if !ok {
+			return fmt.Errorf("unsupported schema version %q. The supported version is %s", version, SchemaVersion)
+		}
+		if m.column != nil {
+			m.column(col)
+		}
+		version = m.next
+	}
+	return nil
+}
+
+// migrateReport applies to the report the migrations from its version until the current one
+func migrateReport(report *Report) error {
+	if len(report.SchemaVersion) == 0 {
+		report.SchemaVersion = SchemaVersionV1
+	}
+	for report.SchemaVersion != SchemaVersion {
+		m, ok := migrations[report.SchemaVersion]
+		if !ok {
+			return fmt.Errorf("unsupported schema version %q. The supported version is %s",
+				report.SchemaVersion, SchemaVersion)
+		}
+		if m.report != nil {
+			m.report(report)
+		}
+		report.SchemaVersion = m.next
+	}
+	return nil
+}
+
+// ValidateReport checks the JSON bundles report against the JSON Schema of the current version. The columns
+// are decoded and checked one by one so that the whole report is not kept in memory. It returns the issues
+// found and an error when the report cannot be read.
+func ValidateReport(r io.Reader) ([]string, error) {
+	data, err := Schema()
+	if err != nil {
+		return nil, err
+	}
+	rootSchema := spec.Schema{}
+	if err := json.Unmarshal(data, &rootSchema); err != nil {
+		return nil, fmt.Errorf("unable to parse the schema : %s", err)
+	}
+
+	// the columns are checked one by one against the schema of its items
+	columnsSchema, ok := rootSchema.Properties["columns"]
+	if !ok || columnsSchema.Items == nil || columnsSchema.Items.Schema == nil {
+		return nil, fmt.Errorf("invalid schema: the items of the columns are not defined")
+	}
+	columnValidator := validate.NewSchemaValidator(columnsSchema.Items.Schema, nil, "", strfmt.Default)
+	headerSchema := rootSchema
+	headerSchema.Properties = map[string]spec.Schema{}
+	for k, v := range rootSchema.Properties {
+		headerSchema.Properties[k] = v
+	}
+	headerSchema.Properties["columns"] = *spec.ArrayProperty(nil)
+	headerValidator := validate.NewSchemaValidator(&headerSchema, nil, "", strfmt.Default)
+
+	var issues []string
+	legacy := false
+	header := map[string]interface{}{}
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid bundles report: unexpected token %v", token)
+		}
+		if !strings.EqualFold(key, "columns") {
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			header[key] = value
+			continue
+		}
+
+		// the reports generated before the schema was versioned use the Go names of the fields as keys
+		if key != "columns" {
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return nil, err
+			}
+			legacy = true
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, err
+		}
+		for i := 0; decoder.More(); i++ {
+			var col interface{}
+			if err := decoder.Decode(&col); err != nil {
+				return nil, err
+			}
+			for _, e := range columnValidator.Validate(col).Errors {
+				issues = append(issues, fmt.Sprintf("columns[%d]: %s", i, e))
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return nil, err
+		}
+		header[key] = []interface{}{}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+
+	if _, ok := header["schemaVersion"]; !ok && legacy {
+		issues = append(issues, fmt.Sprintf("the report has no schemaVersion and uses the keys of the %s "+
+			"reports. It can be loaded but its data is not checked. Re-generate it to follow the schema %s",
+			SchemaVersionV1, SchemaVersion))
+		return issues, nil
+	}
+	for _, e := range headerValidator.Validate(header).Errors {
+		issues = append(issues, e.Error())
+	}
+	return issues, nil
+}
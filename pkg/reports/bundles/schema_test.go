@@ -0,0 +1,120 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/validation"
+)
+
+func TestValidateReport(t *testing.T) {
+	report := &Report{
+		SchemaVersion: SchemaVersion,
+		Flags:         BindFlags{IndexImage: "quay.io/example/index:latest", OutputFormat: pkg.JSON},
+		GenerateAt:    "2021-04-22",
+		Columns: []Column{{
+			PackageName:      "memcached-operator",
+			Channels:         []string{"alpha"},
+			BundleCSV:        &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "memcached.v0.0.1"}},
+			PropertiesFromDB: []pkg.PropertiesAnnotation{{Type: "olm.maxOpenShiftVersion", Value: "4.8"}},
+			BundleSize: &validation.BundleSize{Size: 10, CompressedSize: 5, MaxSize: 100, UsedPercent: 5,
+				Manifests: []validation.ManifestSize{{Name: "csv.yaml", Size: 10, CompressedSize: 5}}},
+			ImageReferences: []models.ImageReference{{Image: "quay.io/example/memcached:v0.0.1",
+				Status: models.ImageExists, Digest: "sha256:1234", Size: 10}},
+			Vulnerabilities: &models.VulnerabilitySummary{
+				VulnerabilityCounts: models.VulnerabilityCounts{High: 1},
+				Images: []models.ImageVulnerabilities{{Image: "quay.io/example/memcached:v0.0.1",
+					SBOMFormat: "cyclonedx", Packages: 2, VulnerabilityCounts: models.VulnerabilityCounts{High: 1},
+					Vulnerabilities: []models.Vulnerability{{ID: "GHSA-1234", Package: "golang.org/x/net",
+						Version: "0.1.0", Severity: "HIGH", FixedIn: "0.7.0"}}}}},
+		}},
+	}
+	var valid bytes.Buffer
+	if err := (jsonFormatter{}).Format(&valid, report); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		report     string
+		wantIssues []string
+	}{
+		{
+			name:   "should pass when the report follows the schema",
+			report: valid.String(),
+		},
+		{
+			name:       "should fail when the column is not valid",
+			report:     strings.Replace(valid.String(), `"isHeadOfChannel": false`, `"isHeadOfChannel": "no"`, 1),
+			wantIssues: []string{"columns[0]: isHeadOfChannel"},
+		},
+		{
+			name:       "should fail when the schema version is not supported",
+			report:     strings.Replace(valid.String(), `"schemaVersion": "v2"`, `"schemaVersion": "v9"`, 1),
+			wantIssues: []string{"schemaVersion"},
+		},
+		{
+			name:       "should fail when the report has no schema version",
+			report:     `{"Columns": [{"packageName": "foo"}], "Flags": {}, "GenerateAt": "2021-04-22"}`,
+			wantIssues: []string{"has no schemaVersion"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := ValidateReport(strings.NewReader(tt.report))
+			if err != nil {
+				t.Fatalf("ValidateReport() error = %v", err)
+			}
+			if len(issues) != len(tt.wantIssues) {
+				t.Fatalf("ValidateReport() issues = %v, want %v", issues, tt.wantIssues)
+			}
+			for i, want := range tt.wantIssues {
+				if !strings.Contains(issues[i], want) {
+					t.Errorf("ValidateReport() issue = %q, want it to contain %q", issues[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeReportMigration(t *testing.T) {
+	legacy := `{
+		"Columns": [{"packageName": "foo", "propertiesFromDB": [{"Type": "olm.maxOpenShiftVersion", "Value": "4.8"}]}],
+		"Flags": {"image": "quay.io/example/index:latest"},
+		"IndexImageInspect": {"ID": "sha256:123"},
+		"GenerateAt": "2021-04-22"
+	}`
+	report, err := DecodeReport(strings.NewReader(legacy), nil)
+	if err != nil {
+		t.Fatalf("DecodeReport() error = %v", err)
+	}
+	if report.SchemaVersion != SchemaVersion || len(report.Columns) != 1 ||
+		report.Columns[0].PropertiesFromDB[0].Type != "olm.maxOpenShiftVersion" ||
+		report.IndexImageInspect.ID != "sha256:123" || report.Flags.IndexImage != "quay.io/example/index:latest" {
+		t.Errorf("DecodeReport() got = %+v", report)
+	}
+
+	if _, err := DecodeReport(strings.NewReader(`{"schemaVersion": "v9", "columns": []}`), nil); err == nil {
+		t.Errorf("DecodeReport() expected error for unsupported schema version")
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/operator-framework/audit/pkg"
+)
+
+// StreamWriter writes the JSON bundles report to the disk column by column as the bundles are audited so that
+// the whole report does not need to be kept in memory. Note that the columns are written in the order which
+// they are added and not sorted by the package name. The report is written to a temporary file which is only
+// renamed to the report path when it is closed, so that a partial report never replaces the report.
+type StreamWriter struct {
+	mutex   sync.Mutex
+	path    string
+	file    *os.File
+	writer  *bufio.Writer
+	columns int
+	report  Report
+}
+
+// NewStreamWriter creates the temporary report file in the output path and writes the beginning of the report
+func NewStreamWriter(flags BindFlags, inspect pkg.DockerInspect, generateAt string) (*StreamWriter, error) {
+	const reportType = "bundles"
+	path := filepath.Join(flags.OutputPath, pkg.GetReportName(flags.IndexImage, reportType, pkg.JSON))
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StreamWriter{
+		path:   path,
+		file:   file,
+		writer: bufio.NewWriter(file),
+		report: Report{SchemaVersion: SchemaVersion, Flags: flags, IndexImageInspect: inspect,
+			GenerateAt: generateAt},
+	}
+	// the schemaVersion is written first so that the decoders know the version before decoding the columns
+	if _, err := fmt.Fprintf(s.writer, "{\n\t\"schemaVersion\": %q,\n\t\"columns\": [", SchemaVersion); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write adds the column to the report
+func (s *StreamWriter) Write(col Column) error {
+	data, err := json.MarshalIndent(col, "\t\t", "\t")
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	separator := "\n\t\t"
+	if s.columns > 0 {
+		separator = ",\n\t\t"
+	}
+	if _, err := s.writer.WriteString(separator); err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(data); err != nil {
+		return err
+	}
+	s.columns++
+	return nil
+}
+
+// Close writes the end of the report, closes the file and moves it to the report path. It returns ErrNoData
+// when no column was written.
+func (s *StreamWriter) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.writeEnd()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(s.file.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(s.file.Name())
+		return err
+	}
+
+	if s.columns == 0 {
+		return ErrNoData
+	}
+	return nil
+}
+
+// Abort closes and removes the temporary file without writing the report, e.g. when the audit failed
+func (s *StreamWriter) Abort() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.file.Close()
+	if removeErr := os.Remove(s.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+func (s *StreamWriter) writeEnd() error {
+	if s.columns > 0 {
+		if _, err := s.writer.WriteString("\n\t"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.WriteString("]"); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"flags", s.report.Flags},
+		{"indexImageInspect", s.report.IndexImageInspect},
+		{"generateAt", s.report.GenerateAt},
+	}
+	for _, field := range fields {
+		data, err := json.MarshalIndent(field.value, "\t", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(s.writer, ",\n\t%q: %s", field.name, data); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.writer.WriteString("\n}"); err != nil {
+		return err
+	}
+	return s.writer.Flush()
+}
+
+// DecodeReport reads the JSON bundles report incrementally. The func informed is called for each column decoded
+// and the columns are not kept in the report returned. When the func informed is nil then, all columns are added
+// to the report. The reports of older schema versions are migrated to the current one.
+func DecodeReport(r io.Reader, onColumn func(Column) error) (Report, error) {
+	var report Report
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return report, err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return report, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return report, fmt.Errorf("invalid bundles report: unexpected token %v", token)
+		}
+
+		switch {
+		case key == "schemaVersion":
+			err = decoder.Decode(&report.SchemaVersion)
+		case strings.EqualFold(key, "Columns"):
+			if err := decodeColumns(decoder, &report, onColumn); err != nil {
+				return report, err
+			}
+		case strings.EqualFold(key, "Flags"):
+			err = decoder.Decode(&report.Flags)
+		case strings.EqualFold(key, "IndexImageInspect"):
+			err = decoder.Decode(&report.IndexImageInspect)
+		case strings.EqualFold(key, "GenerateAt"):
+			err = decoder.Decode(&report.GenerateAt)
+		default:
+			var ignored json.RawMessage
+			err = decoder.Decode(&ignored)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return report, err
+	}
+	return report, migrateReport(&report)
+}
+
+func decodeColumns(decoder *json.Decoder, report *Report, onColumn func(Column) error) error {
+	// the columns are null when the report has no data
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("invalid bundles report: expected an array of columns but found %v", token)
+	}
+
+	// the reports without the schemaVersion before the columns are from the version 1
+	version := report.SchemaVersion
+	if len(version) == 0 {
+		version = SchemaVersionV1
+	}
+
+	for decoder.More() {
+		var col Column
+		if err := decoder.Decode(&col); err != nil {
+			return err
+		}
+		if err := migrateColumn(version, &col); err != nil {
+			return err
+		}
+		if onColumn == nil {
+			report.Columns = append(report.Columns, col)
+			continue
+		}
+		if err := onColumn(col); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("invalid bundles report: expected %v but found %v", expected, token)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+func TestStreamWriter(t *testing.T) {
+	flags := BindFlags{IndexImage: "quay.io/example/index:latest", OutputPath: t.TempDir()}
+	inspect := pkg.DockerInspect{ID: "sha256:123"}
+
+	stream, err := NewStreamWriter(flags, inspect, "2021-04-22")
+	if err != nil {
+		t.Fatalf("NewStreamWriter() error = %v", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if err := stream.Write(Column{PackageName: name}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(flags.OutputPath, pkg.GetReportName(flags.IndexImage, "bundles", pkg.JSON)))
+	if err != nil {
+		t.Fatalf("unable to read the report: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("the report streamed is not a valid JSON: %v\n%s", err, data)
+	}
+	if len(report.Columns) != 2 || report.Columns[1].PackageName != "bar" || report.IndexImageInspect.ID != "sha256:123" ||
+		report.Flags.IndexImage != flags.IndexImage || report.GenerateAt != "2021-04-22" {
+		t.Errorf("unexpected report streamed: %+v", report)
+	}
+
+	empty, err := NewStreamWriter(flags, inspect, "2021-04-22")
+	if err != nil {
+		t.Fatalf("NewStreamWriter() error = %v", err)
+	}
+	if err := empty.Close(); err == nil {
+		t.Errorf("Close() expected error when no column was written")
+	}
+}
+
+func TestStreamWriterAbort(t *testing.T) {
+	flags := BindFlags{IndexImage: "quay.io/example/index:latest", OutputPath: t.TempDir()}
+	path := filepath.Join(flags.OutputPath, pkg.GetReportName(flags.IndexImage, "bundles", pkg.JSON))
+	if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Flags: flags}
+	data.Flags.OutputFormat = pkg.JSON
+	if err := data.StartStream(); err != nil {
+		t.Fatalf("StartStream() error = %v", err)
+	}
+	if err := data.AddAuditBundle(models.AuditBundle{PackageName: "foo"}); err != nil {
+		t.Fatalf("AddAuditBundle() error = %v", err)
+	}
+	if err := data.AbortStream(); err != nil {
+		t.Fatalf("AbortStream() error = %v", err)
+	}
+
+	// the previous report is kept and the partial one is removed
+	if content, err := os.ReadFile(path); err != nil || string(content) != "{}" {
+		t.Errorf("the previous report should be kept, got %q (%v)", content, err)
+	}
+	if entries, err := os.ReadDir(flags.OutputPath); err != nil || len(entries) != 1 {
+		t.Errorf("the partial report should be removed, got %v (%v)", entries, err)
+	}
+}
+
+func TestDecodeReport(t *testing.T) {
+	report := Report{
+		Columns:    []Column{{PackageName: "foo"}, {PackageName: "bar"}},
+		Flags:      BindFlags{IndexImage: "quay.io/example/index:latest"},
+		GenerateAt: "2021-04-22",
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DecodeReport(strings.NewReader(string(data)), nil)
+	if err != nil {
+		t.Fatalf("DecodeReport() error = %v", err)
+	}
+	if len(got.Columns) != 2 || got.Flags.IndexImage != report.Flags.IndexImage || got.GenerateAt != report.GenerateAt {
+		t.Errorf("DecodeReport() got = %+v", got)
+	}
+
+	var names []string
+	got, err = DecodeReport(strings.NewReader(string(data)), func(col Column) error {
+		names = append(names, col.PackageName)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeReport() error = %v", err)
+	}
+	if len(got.Columns) != 0 || strings.Join(names, ",") != "foo,bar" {
+		t.Errorf("DecodeReport() with func got columns = %d and names = %v", len(got.Columns), names)
+	}
+
+	if _, err := DecodeReport(strings.NewReader("[]"), nil); err == nil {
+		t.Errorf("DecodeReport() expected error for invalid report")
+	}
+}
+
+func TestApplyCSVDetail(t *testing.T) {
+	newColumn := func() Column {
+		return Column{BundleCSV: &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo.v0.0.1",
+				Annotations: map[string]string{"alm-examples": "[]", "capabilities": "Basic Install"}},
+			Spec: v1alpha1.ClusterServiceVersionSpec{Description: "long description",
+				Icon: []v1alpha1.Icon{{Data: "data", MediaType: "image/png"}}},
+		}}
+	}
+
+	full := newColumn()
+	full.ApplyCSVDetail(CSVDetailFull)
+	if full.BundleCSV == nil || len(full.BundleCSV.Spec.Icon) != 1 {
+		t.Errorf("ApplyCSVDetail(full) should keep the CSV")
+	}
+
+	summary := newColumn()
+	summary.ApplyCSVDetail(CSVDetailSummary)
+	if summary.BundleCSV == nil || len(summary.BundleCSV.Spec.Icon) != 0 || summary.BundleCSV.Spec.Description != "" ||
+		summary.BundleCSV.Annotations["alm-examples"] != "" || summary.BundleCSV.Annotations["capabilities"] == "" {
+		t.Errorf("ApplyCSVDetail(summary) got = %+v", summary.BundleCSV)
+	}
+
+	none := newColumn()
+	none.ApplyCSVDetail(CSVDetailNone)
+	if none.BundleCSV != nil {
+		t.Errorf("ApplyCSVDetail(none) should drop the CSV")
+	}
+}
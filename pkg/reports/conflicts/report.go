@@ -0,0 +1,256 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conflicts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg"
+)
+
+// CRD defines the APIs provided via CustomResourceDefinitions
+const CRD = "CustomResourceDefinition"
+
+// APIService defines the APIs provided via APIServices
+const APIService = "APIService"
+
+// API defines an API owned or required by a bundle
+type API struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Type    string `json:"type"`
+}
+
+// GroupKind returns the key used to check the ownership of the API regardless its version
+func (a API) GroupKind() string {
+	return fmt.Sprintf("%s/%s", a.Group, a.Kind)
+}
+
+func (a API) String() string {
+	return fmt.Sprintf("%s/%s, Kind=%s", a.Group, a.Version, a.Kind)
+}
+
+// HeadBundle defines the APIs owned and required by a bundle which is head of a channel of the package
+type HeadBundle struct {
+	PackageName string
+	BundleName  string
+	Channel     string
+	Owned       []API
+	Required    []API
+}
+
+// NewHeadBundle returns the APIs owned and required by the bundle from its CSV definitions
+func NewHeadBundle(packageName, bundleName, channel string, crds v1alpha1.CustomResourceDefinitions,
+	apiServices v1alpha1.APIServiceDefinitions) HeadBundle {
+	head := HeadBundle{PackageName: packageName, BundleName: bundleName, Channel: channel}
+	for _, v := range crds.Owned {
+		head.Owned = append(head.Owned, API{Group: groupFromCRDName(v.Name), Version: v.Version, Kind: v.Kind,
+			Type: CRD})
+	}
+	for _, v := range crds.Required {
+		head.Required = append(head.Required, API{Group: groupFromCRDName(v.Name), Version: v.Version,
+			Kind: v.Kind, Type: CRD})
+	}
+	for _, v := range apiServices.Owned {
+		head.Owned = append(head.Owned, API{Group: v.Group, Version: v.Version, Kind: v.Kind, Type: APIService})
+	}
+	for _, v := range apiServices.Required {
+		head.Required = append(head.Required, API{Group: v.Group, Version: v.Version, Kind: v.Kind,
+			Type: APIService})
+	}
+	return head
+}
+
+// groupFromCRDName returns the group of the CRD name. e.g. memcacheds.cache.example.com => cache.example.com
+func groupFromCRDName(name string) string {
+	split := strings.SplitN(name, ".", 2)
+	if len(split) < 2 {
+		return ""
+	}
+	return split[1]
+}
+
+// Owner defines a package which owns an API and what bundles and versions
+type Owner struct {
+	PackageName string   `json:"packageName"`
+	Types       []string `json:"types"`
+	Versions    []string `json:"versions"`
+	Bundles     []string `json:"bundles"`
+	Channels    []string `json:"channels"`
+}
+
+// Conflict defines the packages which own the same API
+type Conflict struct {
+	Group    string  `json:"group"`
+	Kind     string  `json:"kind"`
+	Packages []Owner `json:"packages"`
+}
+
+// IncompatibleRequirement defines an API required by a package which is owned by another package
+// that does not provide the version required
+type IncompatibleRequirement struct {
+	PackageName      string   `json:"packageName"`
+	BundleName       string   `json:"bundleName"`
+	Required         API      `json:"required"`
+	OwnerPackages    []string `json:"ownerPackages"`
+	VersionsProvided []string `json:"versionsProvided"`
+}
+
+type Report struct {
+	Flags                    BindFlags                 `json:"flags"`
+	IndexImageInspect        pkg.DockerInspect         `json:"indexImageInspect"`
+	GenerateAt               string                    `json:"generateAt"`
+	Conflicts                []Conflict                `json:"conflicts,omitempty"`
+	IncompatibleRequirements []IncompatibleRequirement `json:"incompatibleRequirements,omitempty"`
+}
+
+// NewReport returns the conflicts found between the APIs owned and required by the head bundles informed.
+// The heads of all packages of the catalog should be informed, since the packages are checked against each
+// other, and only the results which involve the packages like the filter informed via the flags are returned.
+func NewReport(flags BindFlags, inspect pkg.DockerInspect, heads []HeadBundle) *Report {
+	report := Report{Flags: flags, IndexImageInspect: inspect}
+	report.GenerateAt = time.Now().Format("2006-01-02")
+
+	owners := buildOwnerMap(heads)
+	for _, groupKind := range pkg.SortedKeys(owners) {
+		ownersOfAPI := owners[groupKind]
+		if len(ownersOfAPI) < 2 {
+			continue
+		}
+		ownerPkgs := pkg.SortedKeys(ownersOfAPI)
+		if !matchesFilter(flags.Filter, ownerPkgs...) {
+			continue
+		}
+		split := strings.SplitN(groupKind, "/", 2)
+		conflict := Conflict{Group: split[0], Kind: split[1]}
+		for _, pkgName := range ownerPkgs {
+			conflict.Packages = append(conflict.Packages, *ownersOfAPI[pkgName])
+		}
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+
+	// the same bundle can be the head of many channels
+	checked := make(map[string]bool)
+	for _, head := range heads {
+		for _, required := range head.Required {
+			ownersOfAPI := owners[required.GroupKind()]
+			// if the package owns the API itself or nobody owns it then, it is not in the scope of this check
+			if len(ownersOfAPI) == 0 || ownersOfAPI[head.PackageName] != nil {
+				continue
+			}
+			key := fmt.Sprintf("%s/%s", head.BundleName, required.String())
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+			var ownerPkgs []string
+			var versions []string
+			compatible := false
+			for _, pkgName := range pkg.SortedKeys(ownersOfAPI) {
+				ownerPkgs = append(ownerPkgs, pkgName)
+				versions = append(versions, ownersOfAPI[pkgName].Versions...)
+				for _, v := range ownersOfAPI[pkgName].Versions {
+					if v == required.Version {
+						compatible = true
+					}
+				}
+			}
+			if compatible || !matchesFilter(flags.Filter, append([]string{head.PackageName}, ownerPkgs...)...) {
+				continue
+			}
+			report.IncompatibleRequirements = append(report.IncompatibleRequirements, IncompatibleRequirement{
+				PackageName:      head.PackageName,
+				BundleName:       head.BundleName,
+				Required:         required,
+				OwnerPackages:    ownerPkgs,
+				VersionsProvided: pkg.GetUniqueValues(versions),
+			})
+		}
+	}
+
+	sort.Slice(report.IncompatibleRequirements[:], func(i, j int) bool {
+		if report.IncompatibleRequirements[i].PackageName == report.IncompatibleRequirements[j].PackageName {
+			return report.IncompatibleRequirements[i].BundleName < report.IncompatibleRequirements[j].BundleName
+		}
+		return report.IncompatibleRequirements[i].PackageName < report.IncompatibleRequirements[j].PackageName
+	})
+
+	return &report
+}
+
+// matchesFilter returns true when no filter is informed or when any of the packages is like *filter*
+func matchesFilter(filter string, packages ...string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, name := range packages {
+		if strings.Contains(name, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildOwnerMap returns a map of group/kind with the packages which own the API
+func buildOwnerMap(heads []HeadBundle) map[string]map[string]*Owner {
+	owners := make(map[string]map[string]*Owner)
+	for _, head := range heads {
+		for _, api := range head.Owned {
+			if owners[api.GroupKind()] == nil {
+				owners[api.GroupKind()] = make(map[string]*Owner)
+			}
+			owner := owners[api.GroupKind()][head.PackageName]
+			if owner == nil {
+				owner = &Owner{PackageName: head.PackageName}
+				owners[api.GroupKind()][head.PackageName] = owner
+			}
+			owner.Types = pkg.GetUniqueValues(append(owner.Types, api.Type))
+			owner.Versions = pkg.GetUniqueValues(append(owner.Versions, api.Version))
+			owner.Bundles = pkg.GetUniqueValues(append(owner.Bundles, head.BundleName))
+			owner.Channels = pkg.GetUniqueValues(append(owner.Channels, head.Channel))
+		}
+	}
+	return owners
+}
+
+func (r *Report) writeJSON() error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	const reportType = "conflicts"
+	return pkg.WriteJSON(data, r.Flags.IndexImage, r.Flags.OutputPath, reportType)
+}
+
+// OutputReport writes the report in the format informed via the flags
+func (r *Report) OutputReport() error {
+	switch r.Flags.OutputFormat {
+	case pkg.JSON:
+		if err := r.writeJSON(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid output format : %s", r.Flags.OutputFormat)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conflicts
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg"
+)
+
+func newHead(packageName, bundleName, channel string, owned, required []string) HeadBundle {
+	crds := v1alpha1.CustomResourceDefinitions{}
+	for _, version := range owned {
+		crds.Owned = append(crds.Owned, v1alpha1.CRDDescription{Name: "memcacheds.cache.example.com",
+			Version: version, Kind: "Memcached"})
+	}
+	for _, version := range required {
+		crds.Required = append(crds.Required, v1alpha1.CRDDescription{Name: "memcacheds.cache.example.com",
+			Version: version, Kind: "Memcached"})
+	}
+	return NewHeadBundle(packageName, bundleName, channel, crds, v1alpha1.APIServiceDefinitions{})
+}
+
+func TestBuildOwnerMap(t *testing.T) {
+	heads := []HeadBundle{
+		newHead("memcached", "memcached.v0.0.2", "stable", []string{"v1"}, nil),
+		newHead("memcached", "memcached.v0.0.2", "alpha", []string{"v1"}, nil),
+		newHead("memcached", "memcached.v0.0.3", "beta", []string{"v1beta1"}, nil),
+		newHead("other", "other.v1.0.0", "stable", []string{"v1"}, nil),
+		newHead("consumer", "consumer.v1.0.0", "stable", nil, []string{"v1"}),
+	}
+
+	owners := buildOwnerMap(heads)
+	if !reflect.DeepEqual(pkg.SortedKeys(owners), []string{"cache.example.com/Memcached"}) {
+		t.Fatalf("unexpected APIs: %v", pkg.SortedKeys(owners))
+	}
+	ownersOfAPI := owners["cache.example.com/Memcached"]
+	if !reflect.DeepEqual(pkg.SortedKeys(ownersOfAPI), []string{"memcached", "other"}) {
+		t.Fatalf("unexpected owners: %v", pkg.SortedKeys(ownersOfAPI))
+	}
+	want := Owner{PackageName: "memcached", Types: []string{CRD}, Versions: []string{"v1", "v1beta1"},
+		Bundles: []string{"memcached.v0.0.2", "memcached.v0.0.3"}, Channels: []string{"stable", "alpha", "beta"}}
+	if !reflect.DeepEqual(*ownersOfAPI["memcached"], want) {
+		t.Errorf("buildOwnerMap() = %+v, want %+v", *ownersOfAPI["memcached"], want)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	heads := []HeadBundle{
+		newHead("memcached", "memcached.v0.0.2", "stable", []string{"v1"}, nil),
+		newHead("other", "other.v1.0.0", "stable", []string{"v1"}, nil),
+		newHead("consumer", "consumer.v1.0.0", "stable", nil, []string{"v1alpha1"}),
+		newHead("compatible", "compatible.v1.0.0", "stable", nil, []string{"v1"}),
+	}
+
+	tests := []struct {
+		name             string
+		filter           string
+		wantConflicts    int
+		wantIncompatible []string
+	}{
+		{
+			name:             "should report the conflicts and the incompatible requirements",
+			wantConflicts:    1,
+			wantIncompatible: []string{"consumer"},
+		},
+		{
+			name:             "should report the conflicts of the package filtered with the packages filtered out",
+			filter:           "other",
+			wantConflicts:    1,
+			wantIncompatible: []string{"consumer"},
+		},
+		{
+			name:             "should report the incompatible requirements of the package filtered",
+			filter:           "consumer",
+			wantIncompatible: []string{"consumer"},
+		},
+		{
+			name:   "should not report the results which do not involve the package filtered",
+			filter: "compatible",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewReport(BindFlags{Filter: tt.filter}, pkg.DockerInspect{}, heads)
+			if len(report.Conflicts) != tt.wantConflicts {
+				t.Fatalf("NewReport() conflicts = %+v, want %d", report.Conflicts, tt.wantConflicts)
+			}
+			if tt.wantConflicts > 0 && (len(report.Conflicts[0].Packages) != 2 ||
+				report.Conflicts[0].Packages[0].PackageName != "memcached") {
+				t.Errorf("NewReport() conflict = %+v", report.Conflicts[0])
+			}
+			var gotIncompatible []string
+			for _, v := range report.IncompatibleRequirements {
+				gotIncompatible = append(gotIncompatible, v.PackageName)
+				if !reflect.DeepEqual(v.OwnerPackages, []string{"memcached", "other"}) ||
+					!reflect.DeepEqual(v.VersionsProvided, []string{"v1"}) {
+					t.Errorf("NewReport() incompatible requirement = %+v", v)
+				}
+			}
+			if !reflect.DeepEqual(gotIncompatible, tt.wantIncompatible) {
+				t.Errorf("NewReport() incompatible requirements = %v, want %v", gotIncompatible, tt.wantIncompatible)
+			}
+		})
+	}
+}
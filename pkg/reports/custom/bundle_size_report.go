@@ -0,0 +1,223 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this File except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/validation"
+)
+
+// qtdBiggestManifests is the number of manifests which will be shown as the biggest ones per bundle
+const qtdBiggestManifests = 3
+
+type BundleSizeBundle struct {
+	Name             string   `json:"name"`
+	Version          string   `json:"version"`
+	Channels         []string `json:"channels"`
+	IsHeadOfChannel  bool     `json:"isHeadOfChannel"`
+	Size             string   `json:"size"`
+	CompressedSize   string   `json:"compressedSize"`
+	UsedPercent      string   `json:"usedPercent"`
+	Growth           string   `json:"growth"`
+	Color            string   `json:"-"`
+	BiggestManifests []string `json:"biggestManifests"`
+	compressedSize   int64
+	usedPercent      float64
+}
+
+type BundleSizePackage struct {
+	Name          string             `json:"name"`
+	Bundles       []BundleSizeBundle `json:"bundles"`
+	LatestSize    string             `json:"latestSize"`
+	LatestPercent string             `json:"latestPercent"`
+	Growth        string             `json:"growth"`
+	Color         string             `json:"-"`
+	latestPercent float64
+	hasOverLimit  bool
+	hasNearLimit  bool
+}
+
+type BundleSizeReport struct {
+	ImageName               string              `json:"imageName"`
+	ImageID                 string              `json:"imageID"`
+	ImageHash               string              `json:"imageHash"`
+	ImageBuild              string              `json:"imageBuild"`
+	GeneratedAt             string              `json:"generatedAt"`
+	WarnPercent             string              `json:"warnPercent"`
+	MaxSize                 string              `json:"maxSize"`
+	OverLimit               []BundleSizePackage `json:"overLimit"`
+	NearLimit               []BundleSizePackage `json:"nearLimit"`
+	OK                      []BundleSizePackage `json:"ok"`
+	BundlesWithoutSizeCheck []string            `json:"bundlesWithoutSizeCheck"`
+}
+
+// NewBundleSizeReport returns the structure to render the bundle size custom dashboard with the size
+// of each bundle and its trend per package across the bundles versions
+// nolint:dupl
+func NewBundleSizeReport(bundlesReport bundles.Report, optionalValues map[string]string,
+	filter string) (*BundleSizeReport, error) {
+	sizeReport := BundleSizeReport{}
+	sizeReport.ImageName = bundlesReport.Flags.IndexImage
+	sizeReport.ImageID = bundlesReport.IndexImageInspect.ID
+	sizeReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	sizeReport.GeneratedAt = bundlesReport.GenerateAt
+
+	// the threshold used by the validator when the bundles report was generated is used by default
+	warnPercent := validation.DefaultWarnPercent
+	if bundlesReport.Flags.BundleSizeWarnPercent > 0 {
+		warnPercent = bundlesReport.Flags.BundleSizeWarnPercent
+	}
+	if value, ok := optionalValues[validation.WarnPercentKey]; ok {
+		parsed, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			return nil, fmt.Errorf("invalid value for %s (%s). It should be a number between 1 and 100",
+				validation.WarnPercentKey, value)
+		}
+		warnPercent = parsed
+	}
+	sizeReport.WarnPercent = fmt.Sprintf("%.0f%%", warnPercent)
+
+	mapPackagesWithBundles := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if len(v.PackageName) == 0 || v.BundleCSV == nil {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithBundles[v.PackageName] = append(mapPackagesWithBundles[v.PackageName], v)
+	}
+
+	for name, bundlesOfPkg := range mapPackagesWithBundles {
+		pkgSize := newBundleSizePackage(name, bundlesOfPkg, warnPercent)
+		for _, b := range bundlesOfPkg {
+			if b.BundleSize == nil {
+				sizeReport.BundlesWithoutSizeCheck = append(sizeReport.BundlesWithoutSizeCheck, b.BundleCSV.Name)
+			}
+			if len(sizeReport.MaxSize) == 0 && b.BundleSize != nil {
+				sizeReport.MaxSize = validation.FormatBytesInUnit(b.BundleSize.MaxSize)
+			}
+		}
+		if len(pkgSize.Bundles) == 0 {
+			continue
+		}
+
+		switch {
+		case pkgSize.hasOverLimit:
+			sizeReport.OverLimit = append(sizeReport.OverLimit, pkgSize)
+		case pkgSize.hasNearLimit:
+			sizeReport.NearLimit = append(sizeReport.NearLimit, pkgSize)
+		default:
+			sizeReport.OK = append(sizeReport.OK, pkgSize)
+		}
+	}
+
+	sizeReport.sort()
+	return &sizeReport, nil
+}
+
+func (r *BundleSizeReport) sort() {
+	for _, list := range [][]BundleSizePackage{r.OverLimit, r.NearLimit, r.OK} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].latestPercent > list[j].latestPercent
+		})
+	}
+	sort.Strings(r.BundlesWithoutSizeCheck)
+}
+
+// newBundleSizePackage returns the bundles of the package ordered by version with the size of each one
+// and how much it grows from the previous version
+func newBundleSizePackage(name string, bundlesOfPkg []bundles.Column, warnPercent float64) BundleSizePackage {
+	pkgSize := BundleSizePackage{Name: name, Color: GREEN}
+
+	sort.Slice(bundlesOfPkg[:], func(i, j int) bool {
+		return bundlesOfPkg[i].BundleCSV.Spec.Version.Version.LT(bundlesOfPkg[j].BundleCSV.Spec.Version.Version)
+	})
+
+	var previous *BundleSizeBundle
+	for _, b := range bundlesOfPkg {
+		if b.BundleSize == nil {
+			continue
+		}
+
+		bundleSize := BundleSizeBundle{
+			Name:             b.BundleCSV.Name,
+			Version:          b.BundleCSV.Spec.Version.String(),
+			Channels:         b.Channels,
+			IsHeadOfChannel:  b.IsHeadOfChannel,
+			Size:             validation.FormatBytesInUnit(b.BundleSize.Size),
+			CompressedSize:   validation.FormatBytesInUnit(b.BundleSize.CompressedSize),
+			UsedPercent:      fmt.Sprintf("%.1f%%", b.BundleSize.UsedPercent),
+			BiggestManifests: b.BundleSize.BiggestManifests(qtdBiggestManifests),
+			Color:            GREEN,
+			compressedSize:   b.BundleSize.CompressedSize,
+			usedPercent:      b.BundleSize.UsedPercent,
+		}
+
+		switch {
+		case b.BundleSize.IsOverLimit():
+			bundleSize.Color = RED
+			pkgSize.hasOverLimit = true
+		case b.BundleSize.IsNearLimit(warnPercent):
+			bundleSize.Color = ORANGE
+			pkgSize.hasNearLimit = true
+		}
+
+		if previous != nil {
+			bundleSize.Growth = formatGrowth(previous.compressedSize, bundleSize.compressedSize)
+		}
+
+		pkgSize.Bundles = append(pkgSize.Bundles, bundleSize)
+		previous = &pkgSize.Bundles[len(pkgSize.Bundles)-1]
+	}
+
+	if len(pkgSize.Bundles) == 0 {
+		return pkgSize
+	}
+
+	first := pkgSize.Bundles[0]
+	latest := pkgSize.Bundles[len(pkgSize.Bundles)-1]
+	pkgSize.latestPercent = latest.usedPercent
+	pkgSize.LatestSize = latest.CompressedSize
+	pkgSize.LatestPercent = latest.UsedPercent
+	pkgSize.Color = latest.Color
+	if len(pkgSize.Bundles) > 1 {
+		pkgSize.Growth = formatGrowth(first.compressedSize, latest.compressedSize)
+	}
+
+	return pkgSize
+}
+
+// formatGrowth returns the difference between the sizes informed. e.g. +12.0 kB (+3.5%)
+func formatGrowth(from, to int64) string {
+	diff := to - from
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+		diff = -diff
+	}
+	percent := 0.0
+	if from > 0 {
+		percent = float64(to-from) * 100 / float64(from)
+	}
+	return fmt.Sprintf("%s%s (%+.1f%%)", sign, validation.FormatBytesInUnit(diff), percent)
+}
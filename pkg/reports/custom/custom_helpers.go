@@ -15,25 +15,35 @@
 package custom
 
 import (
-	"encoding/json"
+	"os"
 	"strings"
 
 	semverv4 "github.com/blang/semver/v4"
-	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/reports/bundles"
 )
 
-// ParseBundlesJSONReport parse the JSON result from the audit-tool index bundle report and return its structure
+// ParseBundlesJSONReport parse the JSON result from the audit-tool index bundle report and return its structure.
+// The report is decoded incrementally and the bundles of the packages which do not match the filter are not kept.
 func ParseBundlesJSONReport() (bundles.Report, error) {
-	byteValue, err := pkg.ReadFile(Flags.File)
+	file, err := os.Open(Flags.File)
 	if err != nil {
 		return bundles.Report{}, err
 	}
-	var bundlesReport bundles.Report
-	if err = json.Unmarshal(byteValue, &bundlesReport); err != nil {
+	defer file.Close()
+
+	var columns []bundles.Column
+	bundlesReport, err := bundles.DecodeReport(file, func(col bundles.Column) error {
+		if len(Flags.Filter) > 0 && !strings.Contains(col.PackageName, Flags.Filter) {
+			return nil
+		}
+		columns = append(columns, col)
+		return nil
+	})
+	if err != nil {
 		return bundles.Report{}, err
 	}
-	return bundlesReport, err
+	bundlesReport.Columns = columns
+	return bundlesReport, nil
 }
 
 // ParseBundlesJSONReport parse the JSON result from the audit-tool index bundle report and return its structure
@@ -45,12 +55,13 @@ func ParseMultiBundlesJSONReport() ([]bundles.Report, error) {
 		if len(file) == 0 {
 			continue
 		}
-		byteValue, err := pkg.ReadFile(file)
+		f, err := os.Open(file)
 		if err != nil {
 			return all, err
 		}
-		var bundlesReport bundles.Report
-		if err = json.Unmarshal(byteValue, &bundlesReport); err != nil {
+		bundlesReport, err := bundles.DecodeReport(f, nil)
+		f.Close()
+		if err != nil {
 			return all, err
 		}
 		all = append(all, bundlesReport)
@@ -16,6 +16,7 @@ package custom
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -23,14 +24,19 @@ import (
 	"github.com/operator-framework/audit/pkg/reports/bundles"
 )
 
+// removedInVersion matches the k8s version in the results of the validators about the APIs removed, e.g.
+// "this bundle is using APIs which were deprecated and removed in v1.22. ..."
+var removedInVersion = regexp.MustCompile(`deprecated and removed in v(\d+\.\d+)`)
+
 type BundleDeprecate struct {
-	BundleData        bundles.Column
-	DeprecateAPIsMsgs []string
-	ApisRemoved1_22   []string
-	ApisRemoved1_25   []string
-	ApisRemoved1_26   []string
-	Permissions1_25   []string
-	Permissions1_26   []string
+	BundleData        bundles.Column `json:"-"`
+	DeprecateAPIsMsgs []string       `json:"deprecateAPIsMsgs"`
+	// APIsRemoved are the APIs used which are removed per k8s version
+	APIsRemoved map[string][]string `json:"apisRemoved"`
+	// Permissions are the permissions requested for the APIs removed per k8s version
+	Permissions map[string][]string `json:"permissions"`
+	// Findings are the APIs removed found in the bundle with the manifest where they were found
+	Findings []RemovedAPIFinding `json:"findings,omitempty"`
 }
 
 // (Green) Complying
@@ -59,47 +65,18 @@ func MapPkgsComplyingWithDeprecateAPI(
 }
 
 func hasNotMigratedAPIFor(bundlesPerPkg []BundleDeprecate, k8sVersion string) bool {
-	foundNotMigrated := false
 	for _, v := range bundlesPerPkg {
-		switch k8sVersion {
-		case "1.26":
-			if len(v.ApisRemoved1_26) > 0 && !v.BundleData.IsDeprecated {
-				foundNotMigrated = true
-				break
-			}
-		case "1.25":
-			if len(v.ApisRemoved1_25) > 0 && !v.BundleData.IsDeprecated {
-				foundNotMigrated = true
-				break
-			}
-		default:
-			if len(v.ApisRemoved1_22) > 0 && !v.BundleData.IsDeprecated {
-				foundNotMigrated = true
-				break
-			}
+		if len(v.RemovedAPIsFor(k8sVersion)) > 0 && !v.BundleData.IsDeprecated {
+			return true
 		}
 	}
-	return foundNotMigrated
+	return false
 }
 
 func hasHeadOfChannelMigratedAPIFor(bundlesPerPkg []BundleDeprecate, k8sversion string) bool {
 	for _, v := range bundlesPerPkg {
-		switch k8sversion {
-		case k8s126:
-			if (v.ApisRemoved1_26 == nil || len(v.ApisRemoved1_26) < 1) &&
-				v.BundleData.IsHeadOfChannel && !v.BundleData.IsDeprecated {
-				return true
-			}
-		case k8s125:
-			if (v.ApisRemoved1_25 == nil || len(v.ApisRemoved1_25) < 1) &&
-				v.BundleData.IsHeadOfChannel && !v.BundleData.IsDeprecated {
-				return true
-			}
-		default:
-			if (v.ApisRemoved1_22 == nil || len(v.ApisRemoved1_22) < 1) &&
-				v.BundleData.IsHeadOfChannel && !v.BundleData.IsDeprecated {
-				return true
-			}
+		if len(v.RemovedAPIsFor(k8sversion)) == 0 && v.BundleData.IsHeadOfChannel && !v.BundleData.IsDeprecated {
+			return true
 		}
 	}
 	return false
@@ -114,95 +91,89 @@ func (bd *BundleDeprecate) AddDeprecateDataFromValidators() {
 	}
 }
 
-func (bd *BundleDeprecate) AddPotentialWarning() {
+// RemovedAPIsFor returns the APIs removed in the k8s version informed which are used by the bundle
+func (bd *BundleDeprecate) RemovedAPIsFor(k8sVersion string) []string {
+	return bd.APIsRemoved[k8sVersion]
+}
 
+// AddPotentialWarning adds the permissions requested for the APIs removed in the k8s versions of the table
+// which have the permissions checked
+func (bd *BundleDeprecate) AddPotentialWarning() {
 	if bd == nil || bd.BundleData.BundleCSV == nil {
 		return
 	}
 
-	// We need looking for clusterPermissions and permissions
-	apis125 := map[string][]string{
-		"batch":            {"cronjobs"},
-		"discovery.k8s.io": {"endpointslices"},
-		"events.k8s.io":    {"events"},
-		"autoscaling":      {"horizontalpodautoscalers"},
-		"policy":           {"poddisruptionbudgets", "podsecuritypolicies"},
-		"node.k8s.io":      {"runtimeclasses"},
-	}
-
-	apis126 := map[string][]string{
-		"flowcontrol.apiserver.k8s.io": {"flowschemas", "prioritylevelconfigurations"},
-		"autoscaling":                  {"horizontalpodautoscalers"},
-	}
-
-	for _, perm := range bd.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.Permissions {
-		bd.addFromRules(perm, apis125, apis126)
-	}
-
-	for _, perm := range bd.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions {
-		bd.addFromRules(perm, apis125, apis126)
+	strategy := bd.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec
+	for _, removals := range removedAPIs.Removals {
+		if !removals.CheckPermissions {
+			continue
+		}
+		for _, perm := range strategy.Permissions {
+			bd.addFromRules(perm, removals, fmt.Sprintf("permissions of the service account %s", perm.ServiceAccountName))
+		}
+		for _, perm := range strategy.ClusterPermissions {
+			bd.addFromRules(perm, removals,
+				fmt.Sprintf("clusterPermissions of the service account %s", perm.ServiceAccountName))
+		}
 	}
 }
 
-func (bd *BundleDeprecate) addFromRules(perm v1alpha1.StrategyDeploymentPermissions,
-	apis125 map[string][]string, apis126 map[string][]string) {
-	if perm.Rules == nil {
-		return
-	}
-	for apiFromMap, resourcesFromMap := range apis125 {
+func (bd *BundleDeprecate) addFromRules(perm v1alpha1.StrategyDeploymentPermissions, removals APIRemovals,
+	source string) {
+	for _, removed := range removals.APIs {
 		for _, rule := range perm.Rules {
 			for _, api := range rule.APIGroups {
-				if strings.EqualFold(api, apiFromMap) {
-					for _, res := range rule.Resources {
-						for _, resFromMap := range resourcesFromMap {
-							if strings.EqualFold(resFromMap, res) {
-								bd.Permissions1_25 = append(bd.Permissions1_25,
-									fmt.Sprintf("(apiGroups/resources): %s/%s", api, res))
-							}
-							if strings.ToLower(res) == "*" || strings.ToLower(res) == "[*]" {
-								bd.Permissions1_25 = append(bd.Permissions1_25,
-									fmt.Sprintf("(All from apiGroups): %s/%s", api, res))
-							}
-						}
+				if api != "*" && !strings.EqualFold(api, removed.Group) {
+					continue
+				}
+				for _, res := range rule.Resources {
+					permission := ""
+					if strings.EqualFold(removed.Resource, res) {
+						permission = fmt.Sprintf("(apiGroups/resources): %s/%s", api, res)
+					}
+					if strings.ToLower(res) == "*" || strings.ToLower(res) == "[*]" {
+						permission = fmt.Sprintf("(All from apiGroups): %s/%s", api, res)
+					}
+					if len(permission) == 0 {
+						continue
+					}
+					// the same permission matches all APIs removed of the group when it uses the wildcards
+					if !bd.addFinding(RemovedAPIFinding{
+						K8sVersion: removals.K8sVersion,
+						API:        fmt.Sprintf("%s/%s", api, res),
+						Source:     source,
+						Potential:  true,
+					}) {
+						continue
 					}
+					bd.addPermission(removals.K8sVersion, fmt.Sprintf("%s in %s", permission, source))
 				}
 			}
 		}
 	}
+}
 
-	for apiFromMap, resourcesFromMap := range apis126 {
-		for _, rule := range perm.Rules {
-			for _, api := range rule.APIGroups {
-				if strings.EqualFold(api, apiFromMap) {
-					for _, res := range rule.Resources {
-						for _, resFromMap := range resourcesFromMap {
-							if strings.EqualFold(resFromMap, res) {
-								bd.Permissions1_26 = append(bd.Permissions1_26,
-									fmt.Sprintf("(apiGroups/resources): %s/%s", api, res))
-							}
-							if strings.ToLower(res) == "*" || strings.ToLower(res) == "[*]" {
-								bd.Permissions1_26 = append(bd.Permissions1_26,
-									fmt.Sprintf("(All from apiGroups): %s/%s", api, res))
-							}
-						}
-					}
-				}
-			}
-		}
+func (bd *BundleDeprecate) addPermission(k8sVersion, permission string) {
+	if bd.Permissions == nil {
+		bd.Permissions = map[string][]string{}
 	}
+	bd.Permissions[k8sVersion] = append(bd.Permissions[k8sVersion], permission)
 }
 
+// setDeprecateMsg keeps the results of the validators about the APIs removed. They are only used to find the
+// APIs removed when the manifests of the bundle are not in the report (e.g. reports generated by older versions)
+// since the validators only check the k8s versions 1.22, 1.25 and 1.26.
 func (bd *BundleDeprecate) setDeprecateMsg(result string) {
 	if strings.Contains(result, "this bundle is using APIs which were deprecated") {
 		bd.DeprecateAPIsMsgs = append(bd.DeprecateAPIsMsgs, result)
-		if strings.Contains(result, "1.22") {
-			bd.ApisRemoved1_22 = append(bd.ApisRemoved1_22, result)
+		if bd.BundleData.Manifests != nil {
+			return
 		}
-		if strings.Contains(result, "1.25") {
-			bd.ApisRemoved1_22 = append(bd.ApisRemoved1_25, result)
-		}
-		if strings.Contains(result, "1.26") {
-			bd.ApisRemoved1_22 = append(bd.ApisRemoved1_26, result)
+		if match := removedInVersion.FindStringSubmatch(result); match != nil {
+			if bd.APIsRemoved == nil {
+				bd.APIsRemoved = map[string][]string{}
+			}
+			bd.APIsRemoved[match[1]] = append(bd.APIsRemoved[match[1]], result)
 		}
 	}
 }
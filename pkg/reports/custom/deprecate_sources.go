@@ -0,0 +1,226 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/models"
+)
+
+// RemovedAPIFinding defines an API removed in a k8s version which was found in the bundle and the manifest
+// where it was found so that the authors know what should be fixed
+type RemovedAPIFinding struct {
+	K8sVersion string `json:"k8sVersion"`
+	API        string `json:"api"`
+	Source     string `json:"source"`
+	// Potential is true when only permissions to the API were found which does not mean that it is used
+	Potential  bool   `json:"potential,omitempty"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+func (f RemovedAPIFinding) String() string {
+	msg := fmt.Sprintf("%s is used in %s", f.API, f.Source)
+	if f.Potential {
+		msg = fmt.Sprintf("permissions for %s are requested in %s", f.API, f.Source)
+	}
+	if len(f.ReplacedBy) > 0 {
+		msg = fmt.Sprintf("%s (migrate to %s)", msg, f.ReplacedBy)
+	}
+	return msg
+}
+
+// FindingsFor returns the findings of APIs removed in the k8s version informed
+func (bd *BundleDeprecate) FindingsFor(k8sVersion string) []RemovedAPIFinding {
+	var findings []RemovedAPIFinding
+	for _, f := range bd.Findings {
+		if f.K8sVersion == k8sVersion {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// apiReference is an API used by a manifest of the CSV
+type apiReference struct {
+	group    string
+	version  string
+	kind     string
+	resource string
+	source   string
+}
+
+// AddRemovedAPIsFromBundle adds the APIs removed which are used in the manifests shipped in the bundle and in the
+// CSV: the CRs of the alm-examples, the rules of the webhookdefinitions, the apiservicedefinitions and the
+// nativeAPIs. Each API found is added as not migrated for its k8s version.
+func (bd *BundleDeprecate) AddRemovedAPIsFromBundle() {
+	if bd == nil {
+		return
+	}
+
+	refs := manifestsReferences(bd.BundleData.Manifests)
+	if csv := bd.BundleData.BundleCSV; csv != nil {
+		refs = append(refs, almExamplesReferences(csv)...)
+		refs = append(refs, webhookReferences(csv)...)
+		refs = append(refs, apiServiceReferences(csv)...)
+		refs = append(refs, nativeAPIsReferences(csv)...)
+	}
+
+	for _, removals := range removedAPIs.Removals {
+		for _, removed := range removals.APIs {
+			for _, ref := range refs {
+				if !ref.matches(removed) {
+					continue
+				}
+				finding := RemovedAPIFinding{
+					K8sVersion: removals.K8sVersion,
+					API:        removed.String(),
+					Source:     ref.source,
+					ReplacedBy: removed.ReplacedBy,
+				}
+				if bd.addFinding(finding) {
+					if bd.APIsRemoved == nil {
+						bd.APIsRemoved = map[string][]string{}
+					}
+					bd.APIsRemoved[removals.K8sVersion] = append(bd.APIsRemoved[removals.K8sVersion], finding.String())
+				}
+			}
+		}
+	}
+}
+
+// addFinding adds the finding informed and returns false when it was already added
+func (bd *BundleDeprecate) addFinding(finding RemovedAPIFinding) bool {
+	for _, f := range bd.Findings {
+		if f == finding {
+			return false
+		}
+	}
+	bd.Findings = append(bd.Findings, finding)
+	return true
+}
+
+// matches returns true when the reference is for the API removed. Wildcards are not considered since
+// they do not mean that the API removed is used.
+func (r apiReference) matches(removed RemovedAPI) bool {
+	if !strings.EqualFold(r.group, removed.Group) || r.version != removed.Version {
+		return false
+	}
+	if len(r.kind) > 0 && strings.EqualFold(r.kind, removed.Kind) {
+		return true
+	}
+	return len(r.resource) > 0 && strings.EqualFold(r.resource, removed.Resource)
+}
+
+// manifestsReferences returns the APIs of the manifests shipped in the bundle
+func manifestsReferences(manifests []models.ManifestReference) []apiReference {
+	var refs []apiReference
+	for _, manifest := range manifests {
+		group, version := splitAPIVersion(manifest.APIVersion)
+		refs = append(refs, apiReference{
+			group:   group,
+			version: version,
+			kind:    manifest.Kind,
+			source:  fmt.Sprintf("manifests (%s %s)", manifest.Kind, manifest.Name),
+		})
+	}
+	return refs
+}
+
+// almExamplesReferences returns the APIs of the CRs in the alm-examples annotation. When the annotation
+// is not a valid JSON array then, no references are returned since it is checked by the validators.
+func almExamplesReferences(csv *v1alpha1.ClusterServiceVersion) []apiReference {
+	examples := csv.Annotations["alm-examples"]
+	if len(strings.TrimSpace(examples)) == 0 {
+		return nil
+	}
+
+	var crs []struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(examples), &crs); err != nil {
+		return nil
+	}
+
+	var refs []apiReference
+	for _, cr := range crs {
+		group, version := splitAPIVersion(cr.APIVersion)
+		refs = append(refs, apiReference{
+			group:   group,
+			version: version,
+			kind:    cr.Kind,
+			source:  fmt.Sprintf("alm-examples (%s %s)", cr.Kind, cr.Metadata.Name),
+		})
+	}
+	return refs
+}
+
+func webhookReferences(csv *v1alpha1.ClusterServiceVersion) []apiReference {
+	var refs []apiReference
+	for _, webhook := range csv.Spec.WebhookDefinitions {
+		source := fmt.Sprintf("webhookdefinitions (%s)", webhook.GenerateName)
+		for _, rule := range webhook.Rules {
+			for _, group := range rule.APIGroups {
+				for _, version := range rule.APIVersions {
+					for _, res := range rule.Resources {
+						// ignore the subresources, e.g. cronjobs/status
+						res = strings.Split(res, "/")[0]
+						refs = append(refs, apiReference{group: group, version: version, resource: res, source: source})
+					}
+				}
+			}
+		}
+	}
+	return refs
+}
+
+func apiServiceReferences(csv *v1alpha1.ClusterServiceVersion) []apiReference {
+	var refs []apiReference
+	for _, desc := range csv.Spec.APIServiceDefinitions.Owned {
+		refs = append(refs, apiReference{group: desc.Group, version: desc.Version, kind: desc.Kind,
+			source: fmt.Sprintf("apiservicedefinitions.owned (%s)", desc.Name)})
+	}
+	for _, desc := range csv.Spec.APIServiceDefinitions.Required {
+		refs = append(refs, apiReference{group: desc.Group, version: desc.Version, kind: desc.Kind,
+			source: fmt.Sprintf("apiservicedefinitions.required (%s)", desc.Name)})
+	}
+	return refs
+}
+
+func nativeAPIsReferences(csv *v1alpha1.ClusterServiceVersion) []apiReference {
+	var refs []apiReference
+	for _, gvk := range csv.Spec.NativeAPIs {
+		refs = append(refs, apiReference{group: gvk.Group, version: gvk.Version, kind: gvk.Kind,
+			source: "nativeAPIs"})
+	}
+	return refs
+}
+
+// splitAPIVersion returns the group and version of the apiVersion informed, e.g. batch/v1beta1
+// or v1 for the core group
+func splitAPIVersion(apiVersion string) (string, string) {
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i], apiVersion[i+1:]
+	}
+	return "", apiVersion
+}
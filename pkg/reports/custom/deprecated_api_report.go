@@ -26,75 +26,92 @@ import (
 const OCPLabel = "com.redhat.openshift.versions"
 
 type PotentialImpacted struct {
-	Name    string
-	Founds  []string
-	Bundles []string
+	Name    string   `json:"name"`
+	Founds  []string `json:"founds"`
+	Bundles []string `json:"bundles"`
 }
 
 type Migrated struct {
-	Name            string
-	Kinds           []string
-	Bundles         []string
-	Channels        []string
-	BundlesMigrated []string
-	AllBundles      []BundleDeprecate
+	Name            string            `json:"name"`
+	Kinds           []string          `json:"kinds"`
+	Bundles         []string          `json:"bundles"`
+	Channels        []string          `json:"channels"`
+	BundlesMigrated []string          `json:"bundlesMigrated"`
+	AllBundles      []BundleDeprecate `json:"allBundles"`
 }
 
 type NotMigrated struct {
-	Name            string
-	Kinds           []string
-	Channels        []string
-	Bundles         []string
-	BundlesMigrated []string
-	AllBundles      []BundleDeprecate
+	Name            string            `json:"name"`
+	Kinds           []string          `json:"kinds"`
+	Channels        []string          `json:"channels"`
+	Bundles         []string          `json:"bundles"`
+	BundlesMigrated []string          `json:"bundlesMigrated"`
+	AllBundles      []BundleDeprecate `json:"allBundles"`
 }
 
 type APIDashReport struct {
-	ImageName         string
-	ImageID           string
-	ImageHash         string
-	ImageBuild        string
-	OCPVersion        string
-	K8SVersion        string
-	Migrated          []Migrated
-	NotMigrated       []NotMigrated
-	PotentialImpacted []PotentialImpacted
-	GeneratedAt       string
+	ImageName   string       `json:"imageName"`
+	ImageID     string       `json:"imageID"`
+	ImageHash   string       `json:"imageHash"`
+	ImageBuild  string       `json:"imageBuild"`
+	OCPVersion  string       `json:"ocpVersion"`
+	K8SVersion  string       `json:"k8sVersion"`
+	RemovedAPIs []RemovedAPI `json:"removedAPIs"`
+	// CheckPermissions is true when the packages which request permissions for the APIs removed are checked
+	CheckPermissions bool `json:"checkPermissions"`
+	// NotChecked are the sources of the CSV (--csv-detail) and the manifests (reports of older versions) which
+	// were not checked since they were not kept in the bundles report
+	NotChecked        []string            `json:"notChecked,omitempty"`
+	Migrated          []Migrated          `json:"migrated"`
+	NotMigrated       []NotMigrated       `json:"notMigrated"`
+	PotentialImpacted []PotentialImpacted `json:"potentialImpacted"`
+	GeneratedAt       string              `json:"generatedAt"`
 }
 
-const ocp413 = "4.13"
-const k8s126 = "1.26"
-const ocp412 = "4.12"
-const k8s125 = "1.25"
+// sourcesNotChecked returns the sources of the CSV which are not in the bundles report generated with the
+// csv detail informed
+func sourcesNotChecked(csvDetail string) []string {
+	switch csvDetail {
+	case bundles.CSVDetailSummary:
+		return []string{"alm-examples"}
+	case bundles.CSVDetailNone:
+		return []string{"alm-examples", "webhookdefinitions", "apiservicedefinitions", "nativeAPIs", "permissions"}
+	}
+	return nil
+}
+
+// K8sVersionKey defines the key of the optional values which can be used by its consumers
+// to inform what is the K8S version that should be used to do the tests against.
+const K8sVersionKey = "k8s-version"
 
-// NewAPIDashReport returns the structure to render the Deprecate API custom dashboard
+// NewAPIDashReport returns the structure to render the Deprecate API custom dashboard. The APIs removed in the
+// k8s version informed via the optional values are looked up in the table of the APIs removed. It returns an error
+// when the k8s version informed is invalid.
 // nolint:dupl
-func NewAPIDashReport(bundlesReport bundles.Report, optionalValues map[string]string, filterPkg string) *APIDashReport {
+func NewAPIDashReport(bundlesReport bundles.Report, optionalValues map[string]string,
+	filterPkg string) (*APIDashReport, error) {
 	apiDash := APIDashReport{}
 	apiDash.ImageName = bundlesReport.Flags.IndexImage
 	apiDash.ImageID = bundlesReport.IndexImageInspect.ID
 	apiDash.ImageBuild = bundlesReport.IndexImageInspect.Created
 	apiDash.GeneratedAt = bundlesReport.GenerateAt
 
-	// k8sVersionKey defines the key which can be used by its consumers
-	// to inform what is the K8S version that should be used to do the tests against.
-	const k8sVersionKey = "k8s-version"
-
-	k8sVersion := optionalValues[k8sVersionKey]
-
-	switch k8sVersion {
-	case k8s126:
-		apiDash.OCPVersion = ocp413
-		apiDash.K8SVersion = k8s126
-	case k8s125:
-		apiDash.OCPVersion = ocp412
-		apiDash.K8SVersion = k8s125
-	default:
-		apiDash.OCPVersion = "4.9"
-		apiDash.K8SVersion = "1.22"
+	k8sVersion := DefaultK8sVersion
+	if version, ok := optionalValues[K8sVersionKey]; ok {
+		var err error
+		if k8sVersion, err = NormalizeK8sVersion(version); err != nil {
+			return nil, err
+		}
 	}
+	removals := RemovedAPIsFor(k8sVersion)
+	apiDash.K8SVersion = removals.K8sVersion
+	apiDash.OCPVersion = removals.OCPVersion
+	apiDash.RemovedAPIs = removals.APIs
+	apiDash.CheckPermissions = removals.CheckPermissions
+	apiDash.NotChecked = sourcesNotChecked(bundlesReport.Flags.CSVDetail)
 
 	var allBundles []BundleDeprecate
+	manifestsNotChecked := false
 	for _, v := range bundlesReport.Columns {
 		// filter by the name
 		if len(filterPkg) > 0 {
@@ -102,11 +119,17 @@ func NewAPIDashReport(bundlesReport bundles.Report, optionalValues map[string]st
 				continue
 			}
 		}
+		// the validators only inform the APIs removed in 1.22, 1.25 and 1.26 when the manifests are not kept
+		manifestsNotChecked = manifestsNotChecked || v.Manifests == nil
 		bd := BundleDeprecate{BundleData: v}
 		bd.AddDeprecateDataFromValidators()
 		bd.AddPotentialWarning()
+		bd.AddRemovedAPIsFromBundle()
 		allBundles = append(allBundles, bd)
 	}
+	if manifestsNotChecked {
+		apiDash.NotChecked = append(apiDash.NotChecked, "manifests")
+	}
 
 	mapPackagesWithBundles := MapBundlesPerPackage(allBundles)
 	migrated := MapPkgsComplyingWithDeprecateAPI(mapPackagesWithBundles, apiDash.K8SVersion)
@@ -141,100 +164,73 @@ func NewAPIDashReport(bundlesReport bundles.Report, optionalValues map[string]st
 		})
 	}
 
-	// Calculate the potential impacted by
-	impactedPkgs := map[string]string{}
-
-	//todo: we need clean up this code
-	if apiDash.OCPVersion == ocp412 || apiDash.OCPVersion == ocp413 {
-		for k, bundles := range mapPackagesWithBundles {
-			var apis []string
-			var foundBundles []string
-			for _, b := range bundles {
-
-				// Ignore the following cases
-				if b.BundleData.BundleCSV == nil || len(b.BundleData.PackageName) == 0 || b.BundleData.IsDeprecated {
-					continue
-				}
-
-				switch apiDash.OCPVersion {
-				case ocp412:
-
-					// Ignore when the max ocp version == these values
-					if b.BundleData.MaxOCPVersion == "4.11" {
-						continue
-					}
-
-					// Ignore if OCP label is < 4.12 or 4.13
-					ocpLabel := b.BundleData.BundleImageLabels[OCPLabel]
-					if len(ocpLabel) > 0 {
-						if contains, _ := pkg.RangeContainsVersion(ocpLabel, ocp412, true); !contains {
-							continue
-						}
-					}
+	if apiDash.CheckPermissions {
+		apiDash.PotentialImpacted = potentialImpacted(mapPackagesWithBundles, apiDash.K8SVersion, apiDash.OCPVersion)
+	}
 
-					if len(b.Permissions1_25) == 0 {
-						continue
-					}
+	return &apiDash, nil
 
-					apis = append(apis, b.Permissions1_25...)
-					foundBundles = append(foundBundles, buildBundleStringPotential(b.BundleData, pkg.GetUniqueValues(apis)))
-				case ocp413:
+}
 
-					// Ignore when the max ocp version == these values
-					if b.BundleData.MaxOCPVersion == ocp412 {
-						continue
-					}
+// potentialImpacted returns the packages which request permissions for the APIs removed in the k8s version.
+// The bundles which cannot be installed on the OCP version are ignored.
+func potentialImpacted(mapPackagesWithBundles map[string][]BundleDeprecate,
+	k8sVersion, ocpVersion string) []PotentialImpacted {
+	var impacted []PotentialImpacted
+	for k, bundles := range mapPackagesWithBundles {
+		var apis []string
+		var foundBundles []string
+		for _, b := range bundles {
+
+			// Ignore the following cases
+			if b.BundleData.BundleCSV == nil || len(b.BundleData.PackageName) == 0 || b.BundleData.IsDeprecated {
+				continue
+			}
 
-					// Ignore if OCP label is < 4.12 or 4.13
-					ocpLabel := b.BundleData.BundleImageLabels[OCPLabel]
-					if len(ocpLabel) > 0 {
-						if contains, _ := pkg.RangeContainsVersion(ocpLabel, ocp413, true); !contains {
-							continue
-						}
-					}
+			if len(ocpVersion) > 0 {
+				// Ignore when the max ocp version is lower than the OCP version
+				if len(b.BundleData.MaxOCPVersion) > 0 &&
+					compareK8sVersions(b.BundleData.MaxOCPVersion, ocpVersion) < 0 {
+					continue
+				}
 
-					if len(b.Permissions1_26) == 0 {
+				// Ignore if OCP label does not contain the OCP version
+				ocpLabel := b.BundleData.BundleImageLabels[OCPLabel]
+				if len(ocpLabel) > 0 {
+					if contains, _ := pkg.RangeContainsVersion(ocpLabel, ocpVersion, true); !contains {
 						continue
 					}
-
-					apis = append(apis, b.Permissions1_26...)
-					foundBundles = append(foundBundles, buildBundleStringPotential(b.BundleData, pkg.GetUniqueValues(apis)))
 				}
+			}
 
+			if len(b.Permissions[k8sVersion]) == 0 {
+				continue
 			}
 
-			if len(foundBundles) > 0 {
-				sort.Slice(foundBundles[:], func(i, j int) bool {
-					return foundBundles[i] < foundBundles[j]
-				})
+			apis = append(apis, b.Permissions[k8sVersion]...)
+			foundBundles = append(foundBundles, buildBundleStringPotential(b.BundleData, pkg.GetUniqueValues(apis)))
+		}
 
-				impactedPkgs[k] = "found"
-				apiDash.PotentialImpacted = append(apiDash.PotentialImpacted, PotentialImpacted{
-					Name:    k,
-					Founds:  pkg.GetUniqueValues(apis),
-					Bundles: foundBundles,
-				})
+		if len(foundBundles) > 0 {
+			sort.Slice(foundBundles[:], func(i, j int) bool {
+				return foundBundles[i] < foundBundles[j]
+			})
 
-			}
+			impacted = append(impacted, PotentialImpacted{
+				Name:    k,
+				Founds:  pkg.GetUniqueValues(apis),
+				Bundles: foundBundles,
+			})
 		}
 	}
-
-	return &apiDash
-
+	return impacted
 }
 
 func GetReportValues(bundles []BundleDeprecate, k8sVersion string) ([]string, []string, []string, []string) {
 	var msg []string
 	var channels []string
 	for _, b := range bundles {
-		switch k8sVersion {
-		case k8s126:
-			msg = append(msg, b.ApisRemoved1_26...)
-		case k8s125:
-			msg = append(msg, b.ApisRemoved1_25...)
-		default:
-			msg = append(msg, b.ApisRemoved1_22...)
-		}
+		msg = append(msg, b.RemovedAPIsFor(k8sVersion)...)
 	}
 	for _, b := range bundles {
 		channels = append(channels, b.BundleData.Channels...)
@@ -246,25 +242,10 @@ func GetReportValues(bundles []BundleDeprecate, k8sVersion string) ([]string, []
 			continue
 		}
 
-		switch k8sVersion {
-		case "1.26":
-			if len(b.ApisRemoved1_26) > 0 {
-				bundlesNotMigrated = append(bundlesNotMigrated, buildBundleString(b.BundleData))
-			} else {
-				bundlesMigrated = append(bundlesMigrated, buildBundleString(b.BundleData))
-			}
-		case "1.25":
-			if len(b.ApisRemoved1_25) > 0 {
-				bundlesNotMigrated = append(bundlesNotMigrated, buildBundleString(b.BundleData))
-			} else {
-				bundlesMigrated = append(bundlesMigrated, buildBundleString(b.BundleData))
-			}
-		default:
-			if len(b.ApisRemoved1_22) > 0 {
-				bundlesNotMigrated = append(bundlesNotMigrated, buildBundleString(b.BundleData))
-			} else {
-				bundlesMigrated = append(bundlesMigrated, buildBundleString(b.BundleData))
-			}
+		if len(b.RemovedAPIsFor(k8sVersion)) > 0 {
+			bundlesNotMigrated = append(bundlesNotMigrated, buildBundleString(b.BundleData))
+		} else {
+			bundlesMigrated = append(bundlesMigrated, buildBundleString(b.BundleData))
 		}
 	}
 
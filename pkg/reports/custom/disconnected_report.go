@@ -0,0 +1,284 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Results of the check of the images for the disconnected installs
+const (
+	DisconnectedReady    = "READY"
+	DisconnectedWarnings = "CHECK THE WARNINGS"
+	DisconnectedNotReady = "NOT READY"
+)
+
+// DisconnectedImage defines an image referenced in the CSV and where it was found
+type DisconnectedImage struct {
+	Image   string   `json:"image"`
+	Sources []string `json:"sources"`
+	// InRelatedImages is false when the image is used but not informed in the spec.relatedImages
+	InRelatedImages bool `json:"inRelatedImages"`
+	PinnedByDigest  bool `json:"pinnedByDigest"`
+	// Unused is true when the image is only informed in the spec.relatedImages
+	Unused bool   `json:"unused"`
+	Color  string `json:"-"`
+}
+
+type DisconnectedBundle struct {
+	Name               string              `json:"name"`
+	BundleImage        string              `json:"bundleImage"`
+	Channels           []string            `json:"channels"`
+	IsFromDefault      bool                `json:"isFromDefault"`
+	ClaimsDisconnected bool                `json:"claimsDisconnected"`
+	Result             string              `json:"result"`
+	Color              string              `json:"-"`
+	Images             []DisconnectedImage `json:"images"`
+	Errors             []string            `json:"errors"`
+	Warnings           []string            `json:"warnings"`
+}
+
+type DisconnectedPackage struct {
+	Name    string               `json:"name"`
+	Result  string               `json:"result"`
+	Color   string               `json:"-"`
+	Bundles []DisconnectedBundle `json:"bundles"`
+	// MirrorList are all images which need to be mirrored to install the heads of the channels
+	MirrorList []string `json:"mirrorList"`
+	// AdditionalImages are the images of the MirrorList which are not informed in the spec.relatedImages, so that
+	// they are not mirrored by oc-mirror unless they are added to the additionalImages of the ImageSetConfiguration
+	AdditionalImages []string `json:"additionalImages"`
+}
+
+type DisconnectedReport struct {
+	ImageName   string                `json:"imageName"`
+	ImageID     string                `json:"imageID"`
+	ImageHash   string                `json:"imageHash"`
+	ImageBuild  string                `json:"imageBuild"`
+	GeneratedAt string                `json:"generatedAt"`
+	Ready       []DisconnectedPackage `json:"ready"`
+	Warnings    []DisconnectedPackage `json:"warnings"`
+	NotReady    []DisconnectedPackage `json:"notReady"`
+	// NotAvailable are the packages which cannot be checked since the CSVs were not kept in the bundles report
+	NotAvailable []DisconnectedPackage `json:"notAvailable,omitempty"`
+	// NotChecked are the sources of the images which were not kept in the bundles report (--csv-detail)
+	NotChecked []string `json:"notChecked,omitempty"`
+}
+
+// NewDisconnectedReport returns the structure to render the disconnected custom dashboard which checks if the
+// images used by the head of the channels are informed in the spec.relatedImages and pinned by digest. The heads
+// are NOT AVAILABLE when the report was generated with --csv-detail=none and the images of the alm-examples are
+// not checked with --csv-detail=summary.
+// nolint:dupl
+func NewDisconnectedReport(bundlesReport bundles.Report, filter string) *DisconnectedReport {
+	disconnectedReport := DisconnectedReport{}
+	disconnectedReport.ImageName = bundlesReport.Flags.IndexImage
+	disconnectedReport.ImageID = bundlesReport.IndexImageInspect.ID
+	disconnectedReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	disconnectedReport.GeneratedAt = bundlesReport.GenerateAt
+
+	csvDetail := bundlesReport.Flags.CSVDetail
+	if csvDetail == bundles.CSVDetailSummary {
+		disconnectedReport.NotChecked = []string{pkg.ImageSourceALMExamples}
+	}
+
+	mapPackagesWithHeads := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if !v.IsHeadOfChannel || v.IsDeprecated || len(v.PackageName) == 0 {
+			continue
+		}
+		if v.BundleCSV == nil && csvDetail != bundles.CSVDetailNone {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithHeads[v.PackageName] = append(mapPackagesWithHeads[v.PackageName], v)
+	}
+
+	for name, heads := range mapPackagesWithHeads {
+		pkgDisconnected := newDisconnectedPackage(name, heads, csvDetail)
+		switch pkgDisconnected.Result {
+		case DisconnectedReady:
+			disconnectedReport.Ready = append(disconnectedReport.Ready, pkgDisconnected)
+		case DisconnectedWarnings:
+			disconnectedReport.Warnings = append(disconnectedReport.Warnings, pkgDisconnected)
+		case NOT_AVAILABLE:
+			disconnectedReport.NotAvailable = append(disconnectedReport.NotAvailable, pkgDisconnected)
+		default:
+			disconnectedReport.NotReady = append(disconnectedReport.NotReady, pkgDisconnected)
+		}
+	}
+
+	for _, list := range [][]DisconnectedPackage{disconnectedReport.Ready, disconnectedReport.Warnings,
+		disconnectedReport.NotReady, disconnectedReport.NotAvailable} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &disconnectedReport
+}
+
+// newDisconnectedPackage returns the package with the worst result found in the heads of its channels and the
+// images which need to be mirrored for them
+func newDisconnectedPackage(name string, heads []bundles.Column, csvDetail string) DisconnectedPackage {
+	pkgDisconnected := DisconnectedPackage{Name: name, Result: DisconnectedReady}
+
+	sort.Slice(heads[:], func(i, j int) bool {
+		return heads[i].BundleName() < heads[j].BundleName()
+	})
+
+	order := map[string]int{DisconnectedReady: 0, NOT_AVAILABLE: 1, DisconnectedWarnings: 2, DisconnectedNotReady: 3}
+	for _, head := range heads {
+		bundleDisconnected := newDisconnectedBundle(head, csvDetail)
+		if order[bundleDisconnected.Result] > order[pkgDisconnected.Result] {
+			pkgDisconnected.Result = bundleDisconnected.Result
+		}
+		pkgDisconnected.Bundles = append(pkgDisconnected.Bundles, bundleDisconnected)
+
+		if len(bundleDisconnected.BundleImage) > 0 {
+			pkgDisconnected.MirrorList = append(pkgDisconnected.MirrorList, bundleDisconnected.BundleImage)
+		}
+		for _, image := range bundleDisconnected.Images {
+			pkgDisconnected.MirrorList = append(pkgDisconnected.MirrorList, image.Image)
+			if !image.InRelatedImages {
+				pkgDisconnected.AdditionalImages = append(pkgDisconnected.AdditionalImages, image.Image)
+			}
+		}
+	}
+	pkgDisconnected.MirrorList = pkg.GetUniqueValues(pkgDisconnected.MirrorList)
+	pkgDisconnected.AdditionalImages = pkg.GetUniqueValues(pkgDisconnected.AdditionalImages)
+	sort.Strings(pkgDisconnected.MirrorList)
+	sort.Strings(pkgDisconnected.AdditionalImages)
+	pkgDisconnected.Color = colorForDisconnectedResult(pkgDisconnected.Result)
+	return pkgDisconnected
+}
+
+// newDisconnectedBundle compares the images referenced by the CSV with its spec.relatedImages. The images which
+// are not informed or not pinned by digest are errors when the Operator claims to support the disconnected mode
+// and warnings otherwise. The bundle is NOT AVAILABLE when its CSV was not kept in the bundles report, and the
+// images informed only in the spec.relatedImages are not reported as unused when the alm-examples were not kept.
+func newDisconnectedBundle(head bundles.Column, csvDetail string) DisconnectedBundle {
+	csv := head.BundleCSV
+	bundleDisconnected := DisconnectedBundle{
+		Name:          head.BundleName(),
+		BundleImage:   head.BundleImagePath,
+		Channels:      head.Channels,
+		IsFromDefault: head.IsFromDefaultChannel,
+	}
+	if csv == nil {
+		bundleDisconnected.Result = NOT_AVAILABLE
+		bundleDisconnected.Color = BLACK
+		bundleDisconnected.Warnings = append(bundleDisconnected.Warnings,
+			fmt.Sprintf("the CSV was not kept in the bundles report (--csv-detail=%s)", csvDetail))
+		return bundleDisconnected
+	}
+	bundleDisconnected.ClaimsDisconnected = pkg.IsClaimingFeature(csv.Annotations, pkg.FeatureDisconnected)
+
+	images, err := imagesReferencedByCSV(csv, csvDetail != bundles.CSVDetailSummary)
+	if err != nil {
+		bundleDisconnected.Warnings = append(bundleDisconnected.Warnings,
+			fmt.Sprintf("unable to check the images of the alm-examples: %s", err))
+	}
+
+	var issues []string
+	for _, image := range images {
+		if !image.InRelatedImages {
+			issues = append(issues, fmt.Sprintf("the image %s used in the %s is not informed in the "+
+				"spec.relatedImages", image.Image, strings.Join(image.Sources, ", ")))
+		}
+		if !image.PinnedByDigest {
+			issues = append(issues, fmt.Sprintf("the image %s is not pinned by digest", image.Image))
+		}
+		if image.Unused {
+			bundleDisconnected.Warnings = append(bundleDisconnected.Warnings,
+				fmt.Sprintf("the image %s is informed in the spec.relatedImages but it is not used by the "+
+					"deployments, their env vars (%s*) nor the alm-examples", image.Image, pkg.RelatedImageEnvPrefix))
+		}
+	}
+	bundleDisconnected.Images = images
+
+	if bundleDisconnected.ClaimsDisconnected {
+		bundleDisconnected.Errors = append(bundleDisconnected.Errors, issues...)
+	} else {
+		bundleDisconnected.Warnings = append(bundleDisconnected.Warnings, issues...)
+		bundleDisconnected.Warnings = append(bundleDisconnected.Warnings,
+			fmt.Sprintf("does not claim to support the %s mode", pkg.FeatureDisconnected))
+	}
+
+	switch {
+	case len(bundleDisconnected.Errors) > 0:
+		bundleDisconnected.Result = DisconnectedNotReady
+	case len(bundleDisconnected.Warnings) > 0:
+		bundleDisconnected.Result = DisconnectedWarnings
+	default:
+		bundleDisconnected.Result = DisconnectedReady
+	}
+	bundleDisconnected.Color = colorForDisconnectedResult(bundleDisconnected.Result)
+	return bundleDisconnected
+}
+
+// imagesReferencedByCSV returns the images referenced by the CSV (see pkg.ImagesFromCSV) sorted by the name. The
+// images are only flagged as unused when the alm-examples are available to be checked.
+func imagesReferencedByCSV(csv *v1alpha1.ClusterServiceVersion, withALMExamples bool) ([]DisconnectedImage, error) {
+	sources, err := pkg.ImagesFromCSV(csv)
+
+	var images []DisconnectedImage
+	for image, from := range sources {
+		inRelated := contains(from, pkg.ImageSourceRelatedImages)
+		result := DisconnectedImage{
+			Image:           image,
+			Sources:         from,
+			InRelatedImages: inRelated,
+			PinnedByDigest:  pkg.IsPinnedByDigest(image),
+			Unused:          withALMExamples && inRelated && len(from) == 1,
+			Color:           GREEN,
+		}
+		switch {
+		case !result.InRelatedImages || !result.PinnedByDigest:
+			result.Color = RED
+		case result.Unused:
+			result.Color = ORANGE
+		}
+		images = append(images, result)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Image < images[j].Image
+	})
+	return images, err
+}
+
+func colorForDisconnectedResult(result string) string {
+	switch result {
+	case DisconnectedReady:
+		return GREEN
+	case DisconnectedWarnings:
+		return ORANGE
+	case NOT_AVAILABLE:
+		return BLACK
+	default:
+		return RED
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+const (
+	disconnectedManager = "quay.io/example/foo@sha256:1111"
+	disconnectedInit    = "quay.io/example/init@sha256:2222"
+	disconnectedOperand = "quay.io/example/operand@sha256:3333"
+	disconnectedSample  = "quay.io/example/sample:v1"
+	disconnectedUnused  = "quay.io/example/unused@sha256:4444"
+)
+
+// disconnectedHead is the head of channel checked by the tests, which use a copy of its CSV with the annotation
+// features.operators.openshift.io/disconnected and the spec.relatedImages of each case
+var disconnectedHead = bundles.Column{PackageName: "foo", BundleImagePath: "quay.io/example/foo-bundle@sha256:0",
+	IsHeadOfChannel: true, Channels: []string{"stable"},
+	BundleCSV: &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0", Annotations: map[string]string{
+			"alm-examples": `[{"apiVersion": "example.com/v1", "kind": "Foo", "spec": {"replicas": 1, ` +
+				`"sidecar": {"sidecarImage": "` + disconnectedSample + `"}, "description": "a/b"}}]`,
+		}},
+		Spec: v1alpha1.ClusterServiceVersionSpec{
+			InstallStrategy: v1alpha1.NamedInstallStrategy{StrategySpec: v1alpha1.StrategyDetailsDeployment{
+				DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{{
+					Name: "foo-controller-manager",
+					Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{{Name: "init", Image: disconnectedInit}},
+						Containers: []corev1.Container{{Name: "manager", Image: disconnectedManager,
+							Env: []corev1.EnvVar{{Name: "RELATED_IMAGE_OPERAND", Value: disconnectedOperand},
+								{Name: "OTHER", Value: "quay.io/example/other:v1"}}}},
+					}}},
+				}},
+			}},
+		},
+	},
+}
+
+func TestDisconnectedBundle(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     bool
+		related    []string
+		csvDetail  string
+		wantResult string
+		// wantImages are the images with their sources, the ones flagged as unused are suffixed by (unused)
+		wantImages   []string
+		wantErrors   int
+		wantWarnings int
+	}{
+		{
+			name:    "should not be ready when it claims disconnected and an image is not pinned by digest",
+			claims:  true,
+			related: []string{disconnectedManager, disconnectedInit, disconnectedOperand, disconnectedSample},
+			wantImages: []string{disconnectedManager + " container,relatedImages",
+				disconnectedInit + " initContainer,relatedImages", disconnectedOperand + " env,relatedImages",
+				disconnectedSample + " alm-examples,relatedImages"},
+			wantResult: DisconnectedNotReady,
+			wantErrors: 1,
+		},
+		{
+			name:    "should warn when it does not claim disconnected",
+			related: []string{disconnectedManager, disconnectedUnused},
+			wantImages: []string{disconnectedManager + " container,relatedImages", disconnectedInit + " initContainer",
+				disconnectedOperand + " env", disconnectedSample + " alm-examples",
+				disconnectedUnused + " relatedImages (unused)"},
+			wantResult:   DisconnectedWarnings,
+			wantWarnings: 6,
+		},
+		{
+			name:      "should not flag the unused images when the alm-examples were not kept",
+			related:   []string{disconnectedManager, disconnectedUnused},
+			csvDetail: bundles.CSVDetailSummary,
+			wantImages: []string{disconnectedManager + " container,relatedImages", disconnectedInit + " initContainer",
+				disconnectedOperand + " env", disconnectedUnused + " relatedImages"},
+			wantResult:   DisconnectedWarnings,
+			wantWarnings: 3,
+		},
+		{
+			name:         "should not be available when the CSV was not kept",
+			claims:       true,
+			related:      []string{disconnectedManager},
+			csvDetail:    bundles.CSVDetailNone,
+			wantResult:   NOT_AVAILABLE,
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column := disconnectedHead
+			column.BundleCSV = disconnectedHead.BundleCSV.DeepCopy()
+			column.BundleCSV.Annotations[pkg.FeatureAnnotation(pkg.FeatureDisconnected)] = strconv.FormatBool(tt.claims)
+			for _, image := range tt.related {
+				column.BundleCSV.Spec.RelatedImages = append(column.BundleCSV.Spec.RelatedImages,
+					v1alpha1.RelatedImage{Image: image})
+			}
+			column.ApplyCSVDetail(tt.csvDetail)
+
+			b := newDisconnectedBundle(column, tt.csvDetail)
+			var images []string
+			for _, image := range b.Images {
+				images = append(images, image.Image+" "+strings.Join(image.Sources, ","))
+				if image.Unused {
+					images[len(images)-1] += " (unused)"
+				}
+			}
+			if b.Result != tt.wantResult || len(b.Errors) != tt.wantErrors || len(b.Warnings) != tt.wantWarnings ||
+				strings.Join(images, "|") != strings.Join(tt.wantImages, "|") {
+				t.Errorf("unexpected result: %+v", b)
+			}
+		})
+	}
+}
+
+func TestNewDisconnectedReport(t *testing.T) {
+	column := disconnectedHead
+	column.BundleCSV = disconnectedHead.BundleCSV.DeepCopy()
+	column.BundleCSV.Annotations[pkg.FeatureAnnotation(pkg.FeatureDisconnected)] = "true"
+	column.BundleCSV.Spec.RelatedImages = []v1alpha1.RelatedImage{{Image: disconnectedManager}}
+
+	report := NewDisconnectedReport(bundles.Report{Columns: []bundles.Column{column}}, "")
+	if len(report.NotReady) != 1 || len(report.NotChecked) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	p := report.NotReady[0]
+	if len(p.MirrorList) != 5 || strings.Join(p.AdditionalImages, ",") != strings.Join([]string{disconnectedInit,
+		disconnectedOperand, disconnectedSample}, ",") {
+		t.Errorf("unexpected package: %+v", p)
+	}
+
+	summary := column
+	summary.ApplyCSVDetail(bundles.CSVDetailSummary)
+	report = NewDisconnectedReport(bundles.Report{Flags: bundles.BindFlags{CSVDetail: bundles.CSVDetailSummary},
+		Columns: []bundles.Column{summary}}, "")
+	if strings.Join(report.NotChecked, ",") != pkg.ImageSourceALMExamples {
+		t.Errorf("unexpected sources not checked: %v", report.NotChecked)
+	}
+
+	column.ApplyCSVDetail(bundles.CSVDetailNone)
+	report = NewDisconnectedReport(bundles.Report{Flags: bundles.BindFlags{CSVDetail: bundles.CSVDetailNone},
+		Columns: []bundles.Column{column}}, "")
+	if len(report.NotAvailable) != 1 || len(report.Ready)+len(report.Warnings)+len(report.NotReady) != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestIsPinnedByDigest(t *testing.T) {
+	for image, want := range map[string]bool{
+		disconnectedManager:               true,
+		"quay.io/example/foo:v1@sha256:1": true,
+		"localhost:5000/example/foo:v1":   false,
+		"quay.io/example/foo":             false,
+	} {
+		if got := pkg.IsPinnedByDigest(image); got != want {
+			t.Errorf("IsPinnedByDigest(%s) = %v, want %v", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Results of the check of the feature annotations
+const (
+	FeaturesComply    = "COMPLY"
+	FeaturesWarnings  = "CHECK THE WARNINGS"
+	FeaturesNotComply = "NOT COMPLY"
+)
+
+// Status of the value of each feature annotation
+const (
+	FeatureValid   = "VALID"
+	FeatureMissing = "MISSING"
+	FeatureInvalid = "INVALID"
+)
+
+// proxyEnvVars are the env vars used to configure the cluster-wide proxy which are injected by OLM
+var proxyEnvVars = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
+
+// FeatureAnnotationResult is the value of a feature annotation and if it is valid
+type FeatureAnnotationResult struct {
+	Feature string `json:"feature"`
+	Value   string `json:"value,omitempty"`
+	Status  string `json:"status"`
+	Color   string `json:"-"`
+}
+
+type FeaturesBundle struct {
+	Name           string                    `json:"name"`
+	Channels       []string                  `json:"channels"`
+	IsFromDefault  bool                      `json:"isFromDefault"`
+	Result         string                    `json:"result"`
+	Color          string                    `json:"-"`
+	Features       []FeatureAnnotationResult `json:"features"`
+	LegacyFeatures []string                  `json:"legacyFeatures,omitempty"`
+	Errors         []string                  `json:"errors"`
+	Warnings       []string                  `json:"warnings"`
+}
+
+type FeaturesPackage struct {
+	Name    string           `json:"name"`
+	Result  string           `json:"result"`
+	Color   string           `json:"-"`
+	Bundles []FeaturesBundle `json:"bundles"`
+}
+
+type FeaturesReport struct {
+	ImageName   string            `json:"imageName"`
+	ImageID     string            `json:"imageID"`
+	ImageHash   string            `json:"imageHash"`
+	ImageBuild  string            `json:"imageBuild"`
+	GeneratedAt string            `json:"generatedAt"`
+	Comply      []FeaturesPackage `json:"comply"`
+	Warnings    []FeaturesPackage `json:"warnings"`
+	NotComply   []FeaturesPackage `json:"notComply"`
+}
+
+// NewFeaturesReport returns the structure to render the features custom dashboard with the compliance of the
+// head of the channels with the OpenShift feature annotations (features.operators.openshift.io/*)
+// nolint:dupl
+func NewFeaturesReport(bundlesReport bundles.Report, filter string) *FeaturesReport {
+	featuresReport := FeaturesReport{}
+	featuresReport.ImageName = bundlesReport.Flags.IndexImage
+	featuresReport.ImageID = bundlesReport.IndexImageInspect.ID
+	featuresReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	featuresReport.GeneratedAt = bundlesReport.GenerateAt
+
+	mapPackagesWithHeads := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if !v.IsHeadOfChannel || v.IsDeprecated || len(v.PackageName) == 0 || v.BundleCSV == nil {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithHeads[v.PackageName] = append(mapPackagesWithHeads[v.PackageName], v)
+	}
+
+	for name, heads := range mapPackagesWithHeads {
+		pkgFeatures := newFeaturesPackage(name, heads)
+		switch pkgFeatures.Result {
+		case FeaturesComply:
+			featuresReport.Comply = append(featuresReport.Comply, pkgFeatures)
+		case FeaturesWarnings:
+			featuresReport.Warnings = append(featuresReport.Warnings, pkgFeatures)
+		default:
+			featuresReport.NotComply = append(featuresReport.NotComply, pkgFeatures)
+		}
+	}
+
+	for _, list := range [][]FeaturesPackage{featuresReport.Comply, featuresReport.Warnings,
+		featuresReport.NotComply} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &featuresReport
+}
+
+// newFeaturesPackage returns the package with the worst result found in the heads of its channels
+func newFeaturesPackage(name string, heads []bundles.Column) FeaturesPackage {
+	pkgFeatures := FeaturesPackage{Name: name, Result: FeaturesComply}
+
+	sort.Slice(heads[:], func(i, j int) bool {
+		return heads[i].BundleCSV.Name < heads[j].BundleCSV.Name
+	})
+
+	order := map[string]int{FeaturesComply: 0, FeaturesWarnings: 1, FeaturesNotComply: 2}
+	for _, head := range heads {
+		bundleFeatures := newFeaturesBundle(head)
+		if order[bundleFeatures.Result] > order[pkgFeatures.Result] {
+			pkgFeatures.Result = bundleFeatures.Result
+		}
+		pkgFeatures.Bundles = append(pkgFeatures.Bundles, bundleFeatures)
+	}
+	pkgFeatures.Color = colorForFeaturesResult(pkgFeatures.Result)
+	return pkgFeatures
+}
+
+func newFeaturesBundle(head bundles.Column) FeaturesBundle {
+	bundleFeatures := FeaturesBundle{
+		Name:          head.BundleCSV.Name,
+		Channels:      head.Channels,
+		IsFromDefault: head.IsFromDefaultChannel,
+	}
+	annotations := head.BundleCSV.Annotations
+
+	var missing []string
+	for _, feature := range pkg.Features() {
+		result := FeatureAnnotationResult{Feature: feature, Status: FeatureValid, Color: GREEN}
+		value, found := annotations[pkg.FeatureAnnotation(feature)]
+		result.Value = value
+		switch {
+		case !found:
+			result.Status = FeatureMissing
+			result.Color = RED
+			missing = append(missing, pkg.FeatureAnnotation(feature))
+		case value != "true" && value != "false":
+			result.Status = FeatureInvalid
+			result.Color = RED
+			bundleFeatures.Errors = append(bundleFeatures.Errors,
+				fmt.Sprintf("the annotation %s has the value %q but it should be the string \"true\" or \"false\"",
+					pkg.FeatureAnnotation(feature), value))
+		}
+		bundleFeatures.Features = append(bundleFeatures.Features, result)
+	}
+
+	for key := range annotations {
+		feature := strings.TrimPrefix(key, pkg.FeaturesAnnotationPrefix)
+		if feature != key && !contains(pkg.Features(), feature) {
+			bundleFeatures.Warnings = append(bundleFeatures.Warnings,
+				fmt.Sprintf("the annotation %s is not a known feature", key))
+		}
+	}
+
+	bundleFeatures.checkLegacyAnnotation(annotations, len(missing) == len(pkg.Features()))
+	if len(missing) > 0 {
+		bundleFeatures.Errors = append(bundleFeatures.Errors,
+			fmt.Sprintf("missing the annotation(s): %s", strings.Join(missing, ", ")))
+	}
+
+	bundleFeatures.checkProxyAware(head.BundleCSV)
+	bundleFeatures.checkDisconnected(head.BundleCSV)
+
+	sort.Strings(bundleFeatures.Warnings)
+	switch {
+	case len(bundleFeatures.Errors) > 0:
+		bundleFeatures.Result = FeaturesNotComply
+	case len(bundleFeatures.Warnings) > 0:
+		bundleFeatures.Result = FeaturesWarnings
+	default:
+		bundleFeatures.Result = FeaturesComply
+	}
+	bundleFeatures.Color = colorForFeaturesResult(bundleFeatures.Result)
+	return bundleFeatures
+}
+
+// checkLegacyAnnotation checks the legacy annotation operators.openshift.io/infrastructure-features, which was
+// replaced by the feature annotations, and if it is consistent with them
+func (b *FeaturesBundle) checkLegacyAnnotation(annotations map[string]string, legacyOnly bool) {
+	if _, found := annotations[pkg.InfrastructureAnnotation]; !found {
+		return
+	}
+
+	features, unknown, err := pkg.LegacyFeatures(annotations)
+	if err != nil {
+		b.Errors = append(b.Errors, fmt.Sprintf("the annotation %s should be a JSON list: %s",
+			pkg.InfrastructureAnnotation, err))
+		return
+	}
+	b.LegacyFeatures = features
+
+	if legacyOnly {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("only the legacy annotation %s is used. "+
+			"Use the annotations %s<feature> instead", pkg.InfrastructureAnnotation, pkg.FeaturesAnnotationPrefix))
+	} else {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("the legacy annotation %s is deprecated and can be removed",
+			pkg.InfrastructureAnnotation))
+	}
+	for _, v := range unknown {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("the legacy annotation %s has the unknown value %q",
+			pkg.InfrastructureAnnotation, v))
+	}
+	for _, feature := range features {
+		if annotations[pkg.FeatureAnnotation(feature)] == "false" {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("the legacy annotation %s informs %s but the annotation "+
+				"%s is \"false\"", pkg.InfrastructureAnnotation, feature, pkg.FeatureAnnotation(feature)))
+		}
+	}
+}
+
+// checkProxyAware checks if the deployments handle the proxy env vars when the Operator claims to be proxy-aware.
+// OLM injects them in the deployments of the Operator, which should propagate them to its operands.
+func (b *FeaturesBundle) checkProxyAware(csv *v1alpha1.ClusterServiceVersion) {
+	handled := false
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			for _, env := range c.Env {
+				if contains(proxyEnvVars, strings.ToUpper(env.Name)) {
+					handled = true
+				}
+			}
+		}
+	}
+
+	claiming := pkg.IsClaimingFeature(csv.Annotations, pkg.FeatureProxyAware)
+	if claiming && !handled {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("claims to be %s but no container of the deployments handles "+
+			"the env vars %s", pkg.FeatureProxyAware, strings.Join(proxyEnvVars, ", ")))
+	}
+	if !claiming && handled {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("the deployments handle the proxy env vars but it does not "+
+			"claim to be %s", pkg.FeatureProxyAware))
+	}
+}
+
+// checkDisconnected checks if the images are informed in the relatedImages and pinned by digest when the
+// Operator claims to support the disconnected mode since they need to be mirrored
+func (b *FeaturesBundle) checkDisconnected(csv *v1alpha1.ClusterServiceVersion) {
+	if !pkg.IsClaimingFeature(csv.Annotations, pkg.FeatureDisconnected) {
+		return
+	}
+
+	if len(csv.Spec.RelatedImages) == 0 {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("claims to support the %s mode but spec.relatedImages "+
+			"is not informed", pkg.FeatureDisconnected))
+	}
+
+	var images []string
+	for _, related := range csv.Spec.RelatedImages {
+		images = append(images, related.Image)
+	}
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			images = append(images, c.Image)
+		}
+	}
+	for _, image := range pkg.GetUniqueValues(images) {
+		if !pkg.IsPinnedByDigest(image) {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("claims to support the %s mode but the image %s "+
+				"is not pinned by digest", pkg.FeatureDisconnected, image))
+		}
+	}
+}
+
+func colorForFeaturesResult(result string) string {
+	switch result {
+	case FeaturesComply:
+		return GREEN
+	case FeaturesWarnings:
+		return ORANGE
+	default:
+		return RED
+	}
+}
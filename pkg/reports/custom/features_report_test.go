@@ -0,0 +1,208 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func allFeatures(value string) map[string]string {
+	annotations := map[string]string{}
+	for _, feature := range pkg.Features() {
+		annotations[pkg.FeatureAnnotation(feature)] = value
+	}
+	return annotations
+}
+
+// withAnnotations returns the feature annotations with the values informed, the empty ones are removed
+func withAnnotations(annotations map[string]string, values map[string]string) map[string]string {
+	for key, value := range values {
+		if len(value) == 0 {
+			delete(annotations, key)
+			continue
+		}
+		annotations[key] = value
+	}
+	return annotations
+}
+
+func TestFeaturesBundle(t *testing.T) {
+	head := bundles.Column{PackageName: "foo", IsHeadOfChannel: true, Channels: []string{"stable"},
+		BundleCSV: &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0"},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				InstallStrategy: v1alpha1.NamedInstallStrategy{StrategySpec: v1alpha1.StrategyDetailsDeployment{
+					DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{{
+						Name: "foo-controller-manager",
+						Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "manager", Image: "quay.io/example/foo@sha256:1234"}},
+						}}},
+					}},
+				}},
+				RelatedImages: []v1alpha1.RelatedImage{{Name: "foo", Image: "quay.io/example/foo@sha256:1234"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		env         []string
+		// image replaces the image of the manager, which is not informed in the spec.relatedImages
+		image        string
+		wantResult   string
+		wantErrors   []string
+		wantWarnings []string
+		wantLegacy   int
+	}{
+		{
+			name:        "should comply when all features are informed",
+			annotations: allFeatures("false"),
+			wantResult:  FeaturesComply,
+		},
+		{
+			name: "should not comply with invalid and missing annotations",
+			annotations: withAnnotations(allFeatures("false"), map[string]string{
+				pkg.FeatureAnnotation(pkg.FeatureCSI): "True", pkg.FeatureAnnotation(pkg.FeatureCNI): ""}),
+			wantResult: FeaturesNotComply,
+			wantErrors: []string{"should be the string \"true\" or \"false\"", "missing the annotation(s)"},
+		},
+		{
+			name:        "should warn when only the legacy annotation is used",
+			annotations: map[string]string{pkg.InfrastructureAnnotation: `["Disconnected", "Proxy-aware", "fips", "other"]`},
+			wantResult:  FeaturesNotComply,
+			wantErrors:  []string{"missing the annotation(s)"},
+			wantWarnings: []string{"claims to be proxy-aware", "only the legacy annotation",
+				"unknown value \"other\""},
+			wantLegacy: 3,
+		},
+		{
+			name:         "should fail when the legacy annotation is not a JSON list",
+			annotations:  withAnnotations(allFeatures("false"), map[string]string{pkg.InfrastructureAnnotation: `disconnected`}),
+			wantResult:   FeaturesNotComply,
+			wantErrors:   []string{"JSON list"},
+			wantWarnings: []string{},
+		},
+		{
+			name: "should warn when the legacy annotation is not consistent with the features",
+			annotations: withAnnotations(allFeatures("false"),
+				map[string]string{pkg.InfrastructureAnnotation: `["disconnected"]`}),
+			wantResult:   FeaturesWarnings,
+			wantWarnings: []string{"informs disconnected but", "is deprecated and can be removed"},
+			wantLegacy:   1,
+		},
+		{
+			name: "should comply when the features claimed are consistent with the CSV",
+			annotations: withAnnotations(allFeatures("false"), map[string]string{
+				pkg.FeatureAnnotation(pkg.FeatureProxyAware):   "true",
+				pkg.FeatureAnnotation(pkg.FeatureDisconnected): "true"}),
+			env:        []string{"HTTP_PROXY"},
+			wantResult: FeaturesComply,
+		},
+		{
+			name: "should warn when the features claimed are not consistent with the CSV",
+			annotations: withAnnotations(allFeatures("false"), map[string]string{
+				pkg.FeatureAnnotation(pkg.FeatureProxyAware):   "true",
+				pkg.FeatureAnnotation(pkg.FeatureDisconnected): "true"}),
+			image:      "quay.io/example/foo:v1.0.0",
+			wantResult: FeaturesWarnings,
+			wantWarnings: []string{"no container of the deployments handles the env vars",
+				"spec.relatedImages is not informed", "is not pinned by digest"},
+		},
+		{
+			name:         "should warn when the proxy env vars are used without claiming to be proxy-aware",
+			annotations:  allFeatures("false"),
+			env:          []string{"no_proxy"},
+			wantResult:   FeaturesWarnings,
+			wantWarnings: []string{"does not claim to be proxy-aware"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column := head
+			column.BundleCSV = head.BundleCSV.DeepCopy()
+			column.BundleCSV.Annotations = tt.annotations
+			manager := &column.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.
+				Containers[0]
+			for _, name := range tt.env {
+				manager.Env = append(manager.Env, corev1.EnvVar{Name: name})
+			}
+			if len(tt.image) > 0 {
+				manager.Image = tt.image
+				column.BundleCSV.Spec.RelatedImages = nil
+			}
+
+			b := newFeaturesBundle(column)
+			if b.Result != tt.wantResult || len(b.Features) != len(pkg.Features()) ||
+				len(b.LegacyFeatures) != tt.wantLegacy {
+				t.Errorf("unexpected result: %+v", b)
+			}
+			if !containsAll(b.Errors, tt.wantErrors) {
+				t.Errorf("got errors %v, want %v", b.Errors, tt.wantErrors)
+			}
+			if !containsAll(b.Warnings, tt.wantWarnings) {
+				t.Errorf("got warnings %v, want %v", b.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+// containsAll returns true when each value contains the substring of the same position
+func containsAll(values []string, substrings []string) bool {
+	if len(values) != len(substrings) {
+		return false
+	}
+	for i := range values {
+		if !strings.Contains(values[i], substrings[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIsClaimingLegacyFeature(t *testing.T) {
+	legacy := map[string]string{pkg.InfrastructureAnnotation: `["Disconnected", "Proxy-aware", "fips", "other"]`}
+	if !pkg.IsClaimingFeature(legacy, pkg.FeatureFIPSCompliant) || pkg.IsClaimingFeature(legacy, pkg.FeatureCSI) {
+		t.Errorf("unexpected features claimed via the legacy annotation")
+	}
+}
+
+func TestNewFeaturesReport(t *testing.T) {
+	var columns []bundles.Column
+	for name, annotations := range map[string]map[string]string{
+		"foo": allFeatures("false"),
+		"bar": withAnnotations(allFeatures("false"), map[string]string{pkg.FeaturesAnnotationPrefix + "unknown": "true"}),
+		"baz": {},
+	} {
+		columns = append(columns, bundles.Column{PackageName: name, IsHeadOfChannel: true,
+			BundleCSV: &v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: name + ".v1.0.0", Annotations: annotations}}})
+	}
+
+	report := NewFeaturesReport(bundles.Report{Columns: columns}, "")
+	if len(report.Comply) != 1 || len(report.Warnings) != 1 || len(report.NotComply) != 1 ||
+		report.NotComply[0].Color != RED {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -19,9 +19,13 @@ type BindFlags struct {
 	Files           string            `json:"files,omitempty"`
 	File            string            `json:"file,omitempty"`
 	OutputPath      string            `json:"outputPath,omitempty"`
+	OutputFormat    string            `json:"outputFormat,omitempty"`
 	Filter          string            `json:"filter,omitempty"`
 	ContainerEngine string            `json:"containerEngine,omitempty"`
 	OptionalValues  map[string]string `json:"optionalValues,omitempty"`
+	RemovedAPIsFile string            `json:"removedAPIsFile,omitempty"`
+	QAProfileFile   string            `json:"qaProfileFile,omitempty"`
+	MultiarchScope  string            `json:"multiarchScope,omitempty"`
 }
 
 var Flags = BindFlags{}
@@ -0,0 +1,191 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/validation"
+)
+
+// Results of the check of the images referenced by the bundles
+const (
+	ImagesValid    = "VALID"
+	ImagesWarnings = "CHECK THE WARNINGS"
+	ImagesInvalid  = "INVALID"
+)
+
+// ImageReferenceResult is an image referenced by the bundle with the data to render it
+type ImageReferenceResult struct {
+	models.ImageReference
+	SizeFormatted string `json:"-"`
+	Color         string `json:"-"`
+}
+
+type ImagesBundle struct {
+	Name            string                 `json:"name"`
+	Channels        []string               `json:"channels"`
+	IsHeadOfChannel bool                   `json:"isHeadOfChannel"`
+	Result          string                 `json:"result"`
+	Color           string                 `json:"-"`
+	Images          []ImageReferenceResult `json:"images"`
+	Errors          []string               `json:"errors"`
+	Warnings        []string               `json:"warnings"`
+}
+
+type ImagesPackage struct {
+	Name    string         `json:"name"`
+	Result  string         `json:"result"`
+	Color   string         `json:"-"`
+	Bundles []ImagesBundle `json:"bundles"`
+}
+
+type ImagesReport struct {
+	ImageName   string `json:"imageName"`
+	ImageID     string `json:"imageID"`
+	ImageHash   string `json:"imageHash"`
+	ImageBuild  string `json:"imageBuild"`
+	GeneratedAt string `json:"generatedAt"`
+	// ImagesChecked is false when the bundles report was not generated with --check-images
+	ImagesChecked      bool            `json:"imagesChecked"`
+	SignaturesVerified bool            `json:"signaturesVerified"`
+	Valid              []ImagesPackage `json:"valid"`
+	Warnings           []ImagesPackage `json:"warnings"`
+	Invalid            []ImagesPackage `json:"invalid"`
+}
+
+// NewImagesReport returns the structure to render the images custom dashboard with the images referenced by the
+// bundles which were checked in their registries (see the flag --check-images of audit-tool index bundles)
+// nolint:dupl
+func NewImagesReport(bundlesReport bundles.Report, filter string) *ImagesReport {
+	imagesReport := ImagesReport{}
+	imagesReport.ImageName = bundlesReport.Flags.IndexImage
+	imagesReport.ImageID = bundlesReport.IndexImageInspect.ID
+	imagesReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	imagesReport.GeneratedAt = bundlesReport.GenerateAt
+	imagesReport.ImagesChecked = bundlesReport.Flags.CheckImages
+	imagesReport.SignaturesVerified = len(bundlesReport.Flags.SignatureKeys) > 0
+
+	mapPackagesWithBundles := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if len(v.PackageName) == 0 || len(v.ImageReferences) == 0 {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithBundles[v.PackageName] = append(mapPackagesWithBundles[v.PackageName], v)
+	}
+
+	for name, bundles := range mapPackagesWithBundles {
+		pkgImages := newImagesPackage(name, bundles)
+		switch pkgImages.Result {
+		case ImagesValid:
+			imagesReport.Valid = append(imagesReport.Valid, pkgImages)
+		case ImagesWarnings:
+			imagesReport.Warnings = append(imagesReport.Warnings, pkgImages)
+		default:
+			imagesReport.Invalid = append(imagesReport.Invalid, pkgImages)
+		}
+	}
+
+	for _, list := range [][]ImagesPackage{imagesReport.Valid, imagesReport.Warnings, imagesReport.Invalid} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &imagesReport
+}
+
+// newImagesPackage returns the package with the worst result found in its bundles
+func newImagesPackage(name string, columns []bundles.Column) ImagesPackage {
+	pkgImages := ImagesPackage{Name: name, Result: ImagesValid}
+
+	sort.Slice(columns[:], func(i, j int) bool {
+		return columns[i].BundleName() < columns[j].BundleName()
+	})
+
+	order := map[string]int{ImagesValid: 0, ImagesWarnings: 1, ImagesInvalid: 2}
+	for _, column := range columns {
+		bundleImages := newImagesBundle(column)
+		if order[bundleImages.Result] > order[pkgImages.Result] {
+			pkgImages.Result = bundleImages.Result
+		}
+		pkgImages.Bundles = append(pkgImages.Bundles, bundleImages)
+	}
+	pkgImages.Color = colorForImagesResult(pkgImages.Result)
+	return pkgImages
+}
+
+// newImagesBundle returns the bundle with errors for the images which do not exist or whose signature was not
+// verified and with warnings for the images which could not be checked
+func newImagesBundle(column bundles.Column) ImagesBundle {
+	bundleImages := ImagesBundle{
+		Name:            column.BundleName(),
+		Channels:        column.Channels,
+		IsHeadOfChannel: column.IsHeadOfChannel,
+	}
+
+	for _, ref := range column.ImageReferences {
+		result := ImageReferenceResult{ImageReference: ref, Color: GREEN}
+		if ref.Size > 0 {
+			result.SizeFormatted = validation.FormatBytesInUnit(ref.Size)
+		}
+		switch {
+		case ref.Status == models.ImageNotFound:
+			result.Color = RED
+			bundleImages.Errors = append(bundleImages.Errors,
+				fmt.Sprintf("the image %s was not found in its registry", ref.Image))
+		case ref.Status != models.ImageExists:
+			result.Color = ORANGE
+			bundleImages.Warnings = append(bundleImages.Warnings,
+				fmt.Sprintf("unable to check the image %s in its registry: %s", ref.Image, ref.Error))
+		case ref.Signature == models.SignatureNotVerified:
+			result.Color = RED
+			bundleImages.Errors = append(bundleImages.Errors,
+				fmt.Sprintf("the signature of the image %s is not valid for any of the keys", ref.Image))
+		}
+		bundleImages.Images = append(bundleImages.Images, result)
+	}
+
+	switch {
+	case len(bundleImages.Errors) > 0:
+		bundleImages.Result = ImagesInvalid
+	case len(bundleImages.Warnings) > 0:
+		bundleImages.Result = ImagesWarnings
+	default:
+		bundleImages.Result = ImagesValid
+	}
+	bundleImages.Color = colorForImagesResult(bundleImages.Result)
+	return bundleImages
+}
+
+func colorForImagesResult(result string) string {
+	switch result {
+	case ImagesValid:
+		return GREEN
+	case ImagesWarnings:
+		return ORANGE
+	default:
+		return RED
+	}
+}
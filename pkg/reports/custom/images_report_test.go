@@ -0,0 +1,97 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func TestImagesBundle(t *testing.T) {
+	column := bundles.Column{PackageName: "foo", IsHeadOfChannel: true, Channels: []string{"stable"},
+		BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0"}}}
+	exists := models.ImageReference{Image: "quay.io/example/foo:v1", Status: models.ImageExists, Size: 1500}
+
+	tests := []struct {
+		name         string
+		refs         []models.ImageReference
+		wantResult   string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name:       "should be valid when all images exist",
+			refs:       []models.ImageReference{exists},
+			wantResult: ImagesValid,
+		},
+		{
+			name: "should warn when an image cannot be checked",
+			refs: []models.ImageReference{exists, {Image: "private.io/example/bar:v1", Status: models.ImageUnknown,
+				Error: "unauthorized"}},
+			wantResult:   ImagesWarnings,
+			wantWarnings: []string{"unable to check the image private.io/example/bar:v1 in its registry: unauthorized"},
+		},
+		{
+			name:       "should be invalid when an image was not found",
+			refs:       []models.ImageReference{exists, {Image: "quay.io/example/bar:v1", Status: models.ImageNotFound}},
+			wantResult: ImagesInvalid,
+			wantErrors: []string{"the image quay.io/example/bar:v1 was not found in its registry"},
+		},
+		{
+			name: "should be invalid when the signature of an image is not verified",
+			refs: []models.ImageReference{{Image: "quay.io/example/bar:v1", Status: models.ImageExists,
+				Signature: models.SignatureNotVerified}},
+			wantResult: ImagesInvalid,
+			wantErrors: []string{"the signature of the image quay.io/example/bar:v1 is not valid for any of the keys"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column.ImageReferences = tt.refs
+			b := newImagesBundle(column)
+			if b.Result != tt.wantResult || !containsAll(b.Errors, tt.wantErrors) ||
+				!containsAll(b.Warnings, tt.wantWarnings) {
+				t.Errorf("unexpected result: %+v", b)
+			}
+			if b.Images[0].Image == exists.Image && b.Images[0].SizeFormatted != "1.5 kB" {
+				t.Errorf("unexpected size: %+v", b.Images[0])
+			}
+		})
+	}
+}
+
+func TestNewImagesReport(t *testing.T) {
+	report := bundles.Report{Flags: bundles.BindFlags{CheckImages: true}}
+	for name, status := range map[string]string{"valid": models.ImageExists, "unknown": models.ImageUnknown,
+		"deleted": models.ImageNotFound, "unchecked": ""} {
+		column := bundles.Column{PackageName: name,
+			BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: name + ".v1.0.0"}}}
+		if len(status) > 0 {
+			column.ImageReferences = []models.ImageReference{{Image: "quay.io/example/foo:v1", Status: status}}
+		}
+		report.Columns = append(report.Columns, column)
+	}
+
+	result := NewImagesReport(report, "")
+	if !result.ImagesChecked || len(result.Valid) != 1 || len(result.Warnings) != 1 || len(result.Invalid) != 1 ||
+		result.Invalid[0].Name != "deleted" {
+		t.Errorf("unexpected report: %+v", result)
+	}
+}
@@ -16,48 +16,174 @@ package custom
 
 import (
 	"fmt"
+	"hash/fnv"
 
 	log "github.com/sirupsen/logrus"
 
 	"sort"
 	"strings"
 
-	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
-	validator "github.com/operator-framework/api/pkg/validation"
-	"github.com/operator-framework/api/pkg/validation/errors"
+	corev1 "k8s.io/api/core/v1"
 
+	"github.com/operator-framework/audit/pkg"
 	"github.com/operator-framework/audit/pkg/reports/bundles"
 )
 
+// Kinds of the images referenced in the CSV
+const (
+	// ImageKindManager is the image of the manager of the Operator
+	ImageKindManager = "manager"
+	// ImageKindContainer is the image of the other containers of the deployments, e.g. kube-rbac-proxy
+	ImageKindContainer = "container"
+	// ImageKindRelated is an image of the spec.relatedImages which is not used by the deployments, e.g. operands
+	ImageKindRelated = "related"
+)
+
+// Scopes of the bundles checked by the multiarch report
+const (
+	// MultiarchScopeDefaultHead checks only the heads of the default channels which are not deprecated
+	MultiarchScopeDefaultHead = "default-head"
+	// MultiarchScopeAllHeads checks the heads of all channels, including the deprecated ones
+	MultiarchScopeAllHeads = "all-heads"
+	// MultiarchScopeAllBundles checks all bundles of all channels
+	MultiarchScopeAllBundles = "all-bundles"
+)
+
+// MultiarchScopes returns the scopes of the bundles which can be checked by the multiarch report
+func MultiarchScopes() []string {
+	return []string{MultiarchScopeDefaultHead, MultiarchScopeAllHeads, MultiarchScopeAllBundles}
+}
+
+// ValidateMultiarchScope returns an error when the scope informed via the flags is not valid
+func ValidateMultiarchScope() error {
+	if contains(MultiarchScopes(), Flags.MultiarchScope) {
+		return nil
+	}
+	return fmt.Errorf("invalid value informed via the --scope flag :%v. "+
+		"The available options are: %s", Flags.MultiarchScope, strings.Join(MultiarchScopes(), ", "))
+}
+
+// Values of the cells of the platform matrix
+const (
+	platformProvided    = "yes"
+	platformNotProvided = "no"
+	platformUnknown     = "?"
+)
+
+// inspectManifest returns the manifest list of the image. It is a variable so that it can be replaced in the tests.
+var inspectManifest = pkg.RunDockerManifestInspect
+
 type MultipleArchitecturesBundleReport struct {
-	BundleData          bundles.Column
-	InfraLabelsUsed     []string
-	AllArchFound        map[string]string
-	AllOsFound          map[string]string
-	Errors              []string
-	Warnings            []string
-	ManagerImage        []string
-	Images              []string
-	HasMultiArchSupport bool
-	ForHideButton       string
+	BundleData          bundles.Column    `json:"-"`
+	InfraLabelsUsed     []string          `json:"infraLabelsUsed"`
+	AllArchFound        map[string]string `json:"allArchFound"`
+	AllOsFound          map[string]string `json:"allOsFound"`
+	Errors              []string          `json:"errors"`
+	Warnings            []string          `json:"warnings"`
+	ManagerImage        []string          `json:"managerImage"`
+	Images              []string          `json:"images"`
+	HasMultiArchSupport bool              `json:"hasMultiArchSupport"`
+	// PlatformArchs are the archs declared via the labels or provided by any image (columns of the matrix)
+	PlatformArchs []string `json:"platformArchs"`
+	// ImagePlatforms are the platforms provided by each image referenced in the CSV (rows of the matrix)
+	ImagePlatforms []ImagePlatforms `json:"imagePlatforms"`
+	ForHideButton  string           `json:"-"`
+}
+
+// ImagePlatforms defines the platforms (os/arch) provided by an image referenced in the CSV
+type ImagePlatforms struct {
+	Image string `json:"image"`
+	Kind  string `json:"kind"`
+	// Platforms are empty when the image is not a manifest list or it could not be inspected
+	Platforms    []string `json:"platforms"`
+	IsList       bool     `json:"isList"`
+	MissingArchs []string `json:"missingArchs,omitempty"`
+	MissingOS    []string `json:"missingOS,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	// Matrix informs if the image provides the PlatformArchs of the bundle
+	Matrix []string `json:"-"`
+}
+
+// manifestInspector inspects the manifest lists of the images and keeps the results since the same images are
+// usually referenced by more than one bundle
+type manifestInspector struct {
+	containerTool string
+	cache         map[string]inspectResult
+}
+
+type inspectResult struct {
+	platforms []platform
+	isList    bool
+	err       error
+}
+
+func newManifestInspector(containerTool string) *manifestInspector {
+	return &manifestInspector{containerTool: containerTool, cache: map[string]inspectResult{}}
+}
+
+// inspect returns the platforms of the manifest list of the image. The platforms are empty when the image is
+// not a manifest list.
+func (m *manifestInspector) inspect(image string) inspectResult {
+	if result, ok := m.cache[image]; ok {
+		return result
+	}
+	log.Infof("inspecting the manifest of the image %s", image)
+	result := inspectResult{}
+	manifest, err := inspectManifest(image, m.containerTool)
+	if err != nil {
+		result.err = err
+	}
+	for _, data := range manifest.ManifestData {
+		// the attestation manifests (e.g. SBOMs) are added to the list with the platform unknown/unknown
+		if data.Platform.OS == "unknown" || len(data.Platform.Architecture) == 0 {
+			continue
+		}
+		result.isList = true
+		result.platforms = append(result.platforms,
+			platform{Architecture: data.Platform.Architecture, OS: data.Platform.OS})
+	}
+	m.cache[image] = result
+	return result
 }
 
 type MultipleArchitecturesPackageReport struct {
-	Name    string
-	Bundles []MultipleArchitecturesBundleReport
+	Name    string                              `json:"name"`
+	Bundles []MultipleArchitecturesBundleReport `json:"bundles"`
+	// Channels shows the archs supported by the versions checked of each channel of the package
+	Channels []MultiarchChannelReport `json:"channels"`
+}
+
+// MultiarchChannelReport defines the archs supported by the versions of a channel, sorted by the version
+type MultiarchChannelReport struct {
+	Name      string                   `json:"name"`
+	IsDefault bool                     `json:"isDefault"`
+	Versions  []MultiarchVersionReport `json:"versions"`
+}
+
+// MultiarchVersionReport defines the archs supported by a bundle and the archs gained or lost since the
+// previous version of the channel
+type MultiarchVersionReport struct {
+	Bundle          string   `json:"bundle"`
+	Version         string   `json:"version"`
+	IsHeadOfChannel bool     `json:"isHeadOfChannel"`
+	IsDeprecated    bool     `json:"isDeprecated"`
+	Archs           []string `json:"archs"`
+	Added           []string `json:"added,omitempty"`
+	Removed         []string `json:"removed,omitempty"`
 }
 
 type MultipleArchitecturesReport struct {
-	ImageName             string
-	ImageID               string
-	ImageHash             string
-	ImageBuild            string
-	GeneratedAt           string
-	Unsupported           []MultipleArchitecturesPackageReport
-	Supported             []MultipleArchitecturesPackageReport
-	SupportedWithErrors   []MultipleArchitecturesPackageReport
-	SupportedWithWarnings []MultipleArchitecturesPackageReport
+	ImageName             string                               `json:"imageName"`
+	ImageID               string                               `json:"imageID"`
+	ImageHash             string                               `json:"imageHash"`
+	ImageBuild            string                               `json:"imageBuild"`
+	GeneratedAt           string                               `json:"generatedAt"`
+	Scope                 string                               `json:"scope"`
+	Unsupported           []MultipleArchitecturesPackageReport `json:"unsupported"`
+	Supported             []MultipleArchitecturesPackageReport `json:"supported"`
+	SupportedWithErrors   []MultipleArchitecturesPackageReport `json:"supportedWithErrors"`
+	SupportedWithWarnings []MultipleArchitecturesPackageReport `json:"supportedWithWarnings"`
 }
 
 // platform store the Architecture and OS supported by the image
@@ -66,20 +192,22 @@ type platform struct {
 	OS           string `json:"os"`
 }
 
+// NewMultipleArchitecturesReport checks the bundles of the scope (see MultiarchScopes)
 // nolint:dupl
 func NewMultipleArchitecturesReport(bundlesReport bundles.Report, filter,
-	containerTool string) *MultipleArchitecturesReport {
+	containerTool, scope string) *MultipleArchitecturesReport {
 	multiArch := MultipleArchitecturesReport{}
 	multiArch.ImageName = bundlesReport.Flags.IndexImage
 	multiArch.ImageID = bundlesReport.IndexImageInspect.ID
 	multiArch.ImageBuild = bundlesReport.IndexImageInspect.Created
 	multiArch.GeneratedAt = bundlesReport.GenerateAt
+	multiArch.Scope = scope
 
-	log.Info("checking the head of channels...")
-	headOfChannelPerPackage := mapHeadOfChannelsPerPackage(bundlesReport.Columns)
+	log.Infof("checking the bundles of the scope %s...", scope)
 	mapPackagesWithMultiArchData := make(map[string][]MultipleArchitecturesBundleReport)
+	inspector := newManifestInspector(containerTool)
 
-	for _, bundle := range headOfChannelPerPackage {
+	for _, bundle := range bundlesInScope(bundlesReport.Columns, scope) {
 
 		// filter by the name
 		if len(filter) > 0 {
@@ -92,17 +220,7 @@ func NewMultipleArchitecturesReport(bundlesReport bundles.Report, filter,
 		mb := MultipleArchitecturesBundleReport{BundleData: bundle}
 
 		log.Infof("gathering data per bundle and performing the checks")
-		manifestBundle := &manifests.Bundle{Name: mb.BundleData.PackageName, CSV: mb.BundleData.BundleCSV}
-		multiArchValidator := validator.MultipleArchitecturesValidator.Validate(
-			manifestBundle,
-			map[string]string{"container-tools": containerTool},
-		)
-		if len(multiArchValidator) == 0 {
-			log.Fatal("No objects could be validated")
-		}
-
-		log.Infof("processing the results to render the data in the report")
-		mb.prepareDataPerBundle(multiArchValidator)
+		mb.prepareDataPerBundle(inspector)
 
 		// Add to the map the result per bundle to generate the report
 		mapPackagesWithMultiArchData[mb.BundleData.PackageName] =
@@ -155,24 +273,21 @@ func (multiArch *MultipleArchitecturesReport) categorize(
 			}
 		}
 
+		report := MultipleArchitecturesPackageReport{Name: pkg, Bundles: bundles, Channels: archsPerChannel(bundles)}
 		if hasSupportWarnings {
-			multiArch.SupportedWithWarnings = append(multiArch.SupportedWithWarnings,
-				MultipleArchitecturesPackageReport{Name: pkg, Bundles: bundles})
+			multiArch.SupportedWithWarnings = append(multiArch.SupportedWithWarnings, report)
 		} else if hasSupportErrors {
-			multiArch.SupportedWithErrors = append(multiArch.SupportedWithErrors,
-				MultipleArchitecturesPackageReport{Name: pkg, Bundles: bundles})
+			multiArch.SupportedWithErrors = append(multiArch.SupportedWithErrors, report)
 		} else if hasSupportOK {
-			multiArch.Supported = append(multiArch.Supported,
-				MultipleArchitecturesPackageReport{Name: pkg, Bundles: bundles})
+			multiArch.Supported = append(multiArch.Supported, report)
 		} else {
-			multiArch.Unsupported = append(multiArch.Unsupported,
-				MultipleArchitecturesPackageReport{Name: pkg, Bundles: bundles})
+			multiArch.Unsupported = append(multiArch.Unsupported, report)
 		}
 	}
 }
 
-// Build report data from CSV
-func (mb *MultipleArchitecturesBundleReport) prepareDataPerBundle(multiArchValidator []errors.ManifestResult) {
+// Build report data from CSV and the manifest lists of its images
+func (mb *MultipleArchitecturesBundleReport) prepareDataPerBundle(inspector *manifestInspector) {
 	// Inspect CSV arch labels
 	infraCSVArchLabels := []string{}
 	for k, v := range mb.BundleData.BundleCSV.ObjectMeta.Labels {
@@ -193,8 +308,13 @@ func (mb *MultipleArchitecturesBundleReport) prepareDataPerBundle(multiArchValid
 	mb.InfraLabelsUsed = append(mb.InfraLabelsUsed, infraCSVOSLabels...)
 	mb.InfraLabelsUsed = append(mb.InfraLabelsUsed, infraCSVArchLabels...)
 
-	// Gather images to be displayed in the report
+	// Gather images to be displayed in the report with the platforms of their manifest lists
 	managerImages, allOtherImages := loadImagesFromCSV(*mb.BundleData.BundleCSV)
+	for _, images := range []map[string][]platform{managerImages, allOtherImages} {
+		for image := range images {
+			images[image] = inspector.inspect(image).platforms
+		}
+	}
 
 	// Look up any remaining platforms from CSV
 	mb.AllArchFound = mb.gatherPlatformsFromCSV(infraCSVArchLabels, operatorFrameworkArchLabel, "amd64",
@@ -205,22 +325,28 @@ func (mb *MultipleArchitecturesBundleReport) prepareDataPerBundle(multiArchValid
 	mb.prepareImagesForReport(managerImages, allOtherImages)
 
 	mb.checkIfHasMultiArch()
-	for _, result := range multiArchValidator {
-		for _, warning := range result.Warnings {
-			mb.Warnings = append(mb.Warnings, warning.Error())
-		}
+	mb.checkImagePlatforms(inspector, managerImages, allOtherImages,
+		declaredValues(infraCSVArchLabels, operatorFrameworkArchLabel, "amd64"),
+		declaredValues(infraCSVOSLabels, operatorFrameworkOSLabel, "linux"))
+	mb.checkNodeAffinity(inspector)
 
-		for _, err := range result.Errors {
-			mb.Errors = append(mb.Errors, err.Error())
+	// It is used to create the functions to show/hide the errors, warnings and images
+	mb.ForHideButton = hideButtonID(mb.BundleData)
+}
+
+// hideButtonID returns an id for the HTML elements of the bundle. Only removing the characters which cannot be
+// used in the id from the name of the CSV is not enough since e.g. foo.v1.0 and foo-v1.0 would have the same
+// id, so that the hash of the bundle is added to it.
+func hideButtonID(bundle bundles.Column) string {
+	var name strings.Builder
+	for _, r := range bundle.BundleName() {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			name.WriteRune(r)
 		}
 	}
-
-	// It is used to create the functions to show/hide the errors, warnings and images
-	namehidden := mb.BundleData.BundleCSV.Name
-	namehidden = strings.Replace(namehidden, "_", "", -1)
-	namehidden = strings.Replace(namehidden, ".", "", -1)
-	namehidden = strings.Replace(namehidden, "-", "", -1)
-	mb.ForHideButton = namehidden
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(bundle.PackageName + "/" + bundle.BundleImagePath + "/" + bundle.BundleName()))
+	return fmt.Sprintf("%s-%x", name.String(), hash.Sum32())
 }
 
 // prepareImagesForReport will ensure that
@@ -281,8 +407,8 @@ const operatorFrameworkArchLabel = "operatorframework.io/arch."
 const operatorFrameworkOSLabel = "operatorframework.io/os."
 
 // loadImagesFromCSV will add all allOtherImages found in the CSV
-// it will be looking for all containers allOtherImages and what is defined
-// via the spec.RELATE_IMAGE (required for disconnect support)
+// it will be looking for all images of the containers, init containers, RELATED_IMAGE_* env vars,
+// alm-examples and what is defined via the spec.relatedImages (required for disconnect support)
 func loadImagesFromCSV(csv v1alpha1.ClusterServiceVersion) (map[string][]platform, map[string][]platform) {
 	// We need to try looking for the manager image so that we can
 	// be more assertive in the guess to warning the Operator
@@ -311,22 +437,15 @@ func loadImagesFromCSV(csv v1alpha1.ClusterServiceVersion) (map[string][]platfor
 		}
 	}
 
+	// the images which cannot be parsed from the alm-examples are checked by the validators
+	images, _ := pkg.ImagesFromCSV(&csv)
 	var allOtherImages = make(map[string][]platform)
-	if csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs != nil {
-		for _, v := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
-			for _, c := range v.Spec.Template.Spec.Containers {
-				// If not be from manager the add
-				if len(managerImages[c.Image]) == 0 {
-					allOtherImages[c.Image] = append(allOtherImages[c.Image], platform{})
-				}
-			}
+	for image := range images {
+		if len(managerImages[image]) == 0 {
+			allOtherImages[image] = append(allOtherImages[image], platform{})
 		}
 	}
 
-	for _, v := range csv.Spec.RelatedImages {
-		allOtherImages[v.Image] = append(allOtherImages[v.Image], platform{})
-	}
-
 	return managerImages, allOtherImages
 }
 
@@ -337,15 +456,99 @@ func extractValueFromOFLabel(v string, prefix string) string {
 	return label
 }
 
-// MapBundlesPerPackage returns map with all bundles found per pkg name
-func mapHeadOfChannelsPerPackage(bundlesReport []bundles.Column) map[string]bundles.Column {
-	mapPackagesWithBundles := make(map[string]bundles.Column)
+// bundlesInScope returns the bundles checked for the scope. Reports generated by older versions of the tool can
+// have the same bundle more than once (one per channel), so only the first one is kept.
+func bundlesInScope(bundlesReport []bundles.Column, scope string) []bundles.Column {
+	var result []bundles.Column
+	found := map[string]bool{}
 	for _, v := range bundlesReport {
-		if v.IsHeadOfChannel && !v.IsDeprecated && len(v.PackageName) > 0 && v.IsFromDefaultChannel {
-			mapPackagesWithBundles[v.PackageName] = v
+		if len(v.PackageName) == 0 || v.BundleCSV == nil {
+			continue
+		}
+		switch scope {
+		case MultiarchScopeAllHeads:
+			if !v.IsHeadOfChannel {
+				continue
+			}
+		case MultiarchScopeAllBundles:
+		default:
+			if !v.IsHeadOfChannel || v.IsDeprecated || !v.IsFromDefaultChannel {
+				continue
+			}
 		}
+		key := v.PackageName + "/" + v.BundleName()
+		if found[key] {
+			continue
+		}
+		found[key] = true
+		result = append(result, v)
 	}
-	return mapPackagesWithBundles
+	return result
+}
+
+// archsPerChannel returns the archs supported by the bundles of each channel sorted by the version, so that it
+// is possible to see when a package gained or lost an arch
+func archsPerChannel(bundles []MultipleArchitecturesBundleReport) []MultiarchChannelReport {
+	perChannel := map[string][]MultipleArchitecturesBundleReport{}
+	defaultChannel := ""
+	for _, b := range bundles {
+		for _, channel := range b.BundleData.Channels {
+			perChannel[channel] = append(perChannel[channel], b)
+		}
+		if len(b.BundleData.DefaultChannel) > 0 {
+			defaultChannel = b.BundleData.DefaultChannel
+		}
+	}
+
+	var channels []MultiarchChannelReport
+	for name, channelBundles := range perChannel {
+		//nolint: scopelint
+		sort.SliceStable(channelBundles, func(i, j int) bool {
+			return channelBundles[i].BundleData.BundleCSV.Spec.Version.LT(
+				channelBundles[j].BundleData.BundleCSV.Spec.Version.Version)
+		})
+
+		channel := MultiarchChannelReport{Name: name, IsDefault: name == defaultChannel}
+		var previous []string
+		for i, b := range channelBundles {
+			version := MultiarchVersionReport{
+				Bundle:          b.BundleData.BundleName(),
+				Version:         b.BundleData.BundleCSV.Spec.Version.String(),
+				IsHeadOfChannel: b.BundleData.IsHeadOfChannel,
+				IsDeprecated:    b.BundleData.IsDeprecated,
+			}
+			for arch := range b.AllArchFound {
+				version.Archs = append(version.Archs, arch)
+			}
+			sort.Strings(version.Archs)
+			if i > 0 {
+				version.Added = valuesNotIn(version.Archs, previous)
+				version.Removed = valuesNotIn(previous, version.Archs)
+			}
+			previous = version.Archs
+			channel.Versions = append(channel.Versions, version)
+		}
+		channels = append(channels, channel)
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].IsDefault != channels[j].IsDefault {
+			return channels[i].IsDefault
+		}
+		return channels[i].Name < channels[j].Name
+	})
+	return channels
+}
+
+// valuesNotIn returns the values which are not in the other ones
+func valuesNotIn(values, other []string) []string {
+	var result []string
+	for _, v := range values {
+		if !contains(other, v) {
+			result = append(result, v)
+		}
+	}
+	return result
 }
 
 func (mb *MultipleArchitecturesBundleReport) gatherPlatformsFromCSV(
@@ -368,14 +571,255 @@ func (mb *MultipleArchitecturesBundleReport) gatherPlatformsFromCSV(
 		platforms[defaultValue] = defaultValue
 	}
 
-	// Get all ARCH from the provided manager images
+	// Get all values from the provided manager images
 	for _, imageData := range managerImages {
 		for _, platform := range imageData {
 			if len(extractor(platform)) > 0 {
-				mb.AllArchFound[extractor(platform)] = extractor(platform)
+				platforms[extractor(platform)] = extractor(platform)
 			}
 		}
 	}
 
 	return platforms
 }
+
+// declaredValues returns the values declared via the labels, e.g. amd64 for operatorframework.io/arch.amd64.
+// If a CSV does not include the labels, then it is treated as if it has the label of the default value.
+func declaredValues(infraCSVLabels []string, operatorFrameworkLabel string, defaultValue string) []string {
+	var values []string
+	for _, v := range infraCSVLabels {
+		values = append(values, extractValueFromOFLabel(v, operatorFrameworkLabel))
+	}
+	if len(values) == 0 {
+		values = append(values, defaultValue)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// checkImagePlatforms builds the platform matrix of the images and checks if they provide the archs and OSs
+// declared via the labels of the CSV. The manager images which provide archs that are not declared are
+// also reported since the labels are used to show the Operator on OperatorHub for the clusters of these archs.
+func (mb *MultipleArchitecturesBundleReport) checkImagePlatforms(inspector *manifestInspector,
+	managerImages map[string][]platform, allOtherImages map[string][]platform, archs, oss []string) {
+	related := map[string]bool{}
+	for _, v := range mb.BundleData.BundleCSV.Spec.RelatedImages {
+		related[v.Image] = true
+	}
+
+	columns := map[string]bool{}
+	for _, arch := range archs {
+		columns[arch] = true
+	}
+
+	var images []ImagePlatforms
+	for _, group := range []struct {
+		kind   string
+		images map[string][]platform
+	}{{ImageKindManager, managerImages}, {ImageKindContainer, allOtherImages}} {
+		var names []string
+		for image := range group.images {
+			names = append(names, image)
+		}
+		sort.Strings(names)
+
+		for _, image := range names {
+			// the manager image is often also listed in the relatedImages
+			if _, isManager := managerImages[image]; isManager && group.kind != ImageKindManager {
+				continue
+			}
+			result := inspector.inspect(image)
+			ip := ImagePlatforms{Image: image, Kind: group.kind, IsList: result.isList}
+			if group.kind == ImageKindContainer && related[image] && !mb.isDeploymentImage(image) {
+				ip.Kind = ImageKindRelated
+			}
+			if result.err != nil {
+				ip.Error = result.err.Error()
+				mb.Warnings = append(mb.Warnings, fmt.Sprintf("unable to inspect the manifest of the image %s: %s",
+					image, result.err))
+			}
+			for _, p := range result.platforms {
+				ip.Platforms = append(ip.Platforms, fmt.Sprintf("%s/%s", p.OS, p.Architecture))
+				columns[p.Architecture] = true
+			}
+			mb.checkImagePlatform(&ip, result, archs, oss)
+			images = append(images, ip)
+		}
+	}
+
+	for arch := range columns {
+		mb.PlatformArchs = append(mb.PlatformArchs, arch)
+	}
+	sort.Strings(mb.PlatformArchs)
+
+	for i := range images {
+		result := inspector.inspect(images[i].Image)
+		for _, arch := range mb.PlatformArchs {
+			cell := platformNotProvided
+			switch {
+			case !result.isList:
+				cell = platformUnknown
+			case providesArch(result.platforms, arch):
+				cell = platformProvided
+			}
+			images[i].Matrix = append(images[i].Matrix, cell)
+		}
+	}
+	mb.ImagePlatforms = images
+}
+
+// checkImagePlatform checks if the image provides the archs and OSs declared
+func (mb *MultipleArchitecturesBundleReport) checkImagePlatform(ip *ImagePlatforms, result inspectResult,
+	archs, oss []string) {
+	if result.err != nil {
+		return
+	}
+	if !result.isList {
+		// only one platform is provided, which is unknown without pulling the image
+		if len(archs) > 1 || len(oss) > 1 {
+			mb.Errors = append(mb.Errors, fmt.Sprintf("the %s image %s is not a manifest list, so it does not "+
+				"provide all platforms declared via the labels of the CSV (arch: %s, os: %s)",
+				ip.Kind, ip.Image, strings.Join(archs, ", "), strings.Join(oss, ", ")))
+		}
+		return
+	}
+
+	for _, arch := range archs {
+		if !providesArch(result.platforms, arch) {
+			ip.MissingArchs = append(ip.MissingArchs, arch)
+		}
+	}
+	for _, os := range oss {
+		if !providesOS(result.platforms, os) {
+			ip.MissingOS = append(ip.MissingOS, os)
+		}
+	}
+	if len(ip.MissingArchs) > 0 {
+		mb.Errors = append(mb.Errors, fmt.Sprintf("the %s image %s does not provide the arch(s) %s declared "+
+			"via the labels %s<arch>", ip.Kind, ip.Image, strings.Join(ip.MissingArchs, ", "),
+			operatorFrameworkArchLabel))
+	}
+	if len(ip.MissingOS) > 0 {
+		mb.Errors = append(mb.Errors, fmt.Sprintf("the %s image %s does not provide the OS(s) %s declared "+
+			"via the labels %s<os>", ip.Kind, ip.Image, strings.Join(ip.MissingOS, ", "), operatorFrameworkOSLabel))
+	}
+
+	if ip.Kind != ImageKindManager {
+		return
+	}
+	var notDeclared []string
+	for _, p := range result.platforms {
+		if !contains(archs, p.Architecture) && !contains(notDeclared, p.Architecture) {
+			notDeclared = append(notDeclared, p.Architecture)
+		}
+	}
+	if len(notDeclared) > 0 {
+		sort.Strings(notDeclared)
+		mb.Warnings = append(mb.Warnings, fmt.Sprintf("the manager image %s provides the arch(s) %s which are "+
+			"not declared via the labels %s<arch>", ip.Image, strings.Join(notDeclared, ", "),
+			operatorFrameworkArchLabel))
+	}
+}
+
+// checkNodeAffinity checks if the node affinity (kubernetes.io/arch) of the deployments matches the archs
+// provided by the images of their containers
+func (mb *MultipleArchitecturesBundleReport) checkNodeAffinity(inspector *manifestInspector) {
+	for _, v := range mb.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		affinity := nodeAffinityArchs(v.Spec.Template.Spec)
+		for _, c := range v.Spec.Template.Spec.Containers {
+			result := inspector.inspect(c.Image)
+			if !result.isList {
+				continue
+			}
+			var provided []string
+			for _, p := range result.platforms {
+				if !contains(provided, p.Architecture) {
+					provided = append(provided, p.Architecture)
+				}
+			}
+			sort.Strings(provided)
+
+			if len(affinity) == 0 {
+				mb.Warnings = append(mb.Warnings, fmt.Sprintf("check if the deployment %s is missing a node "+
+					"affinity configuration (%s) for the image %s which provides the arch(s): %s",
+					v.Name, archNodeLabel, c.Image, strings.Join(provided, ", ")))
+				continue
+			}
+
+			var extra, missing []string
+			for _, arch := range affinity {
+				if !contains(provided, arch) {
+					extra = append(extra, arch)
+				}
+			}
+			for _, arch := range provided {
+				if !contains(affinity, arch) {
+					missing = append(missing, arch)
+				}
+			}
+			if len(extra) > 0 {
+				mb.Warnings = append(mb.Warnings, fmt.Sprintf("the node affinity of the deployment %s includes "+
+					"the arch(s) %s which are not provided by the image %s", v.Name, strings.Join(extra, ", "),
+					c.Image))
+			}
+			if len(missing) > 0 {
+				mb.Warnings = append(mb.Warnings, fmt.Sprintf("the image %s provides the arch(s) %s which are not "+
+					"in the node affinity of the deployment %s", c.Image, strings.Join(missing, ", "), v.Name))
+			}
+		}
+	}
+}
+
+// archNodeLabel is the label of the nodes with their arch which is used in the node affinity
+const archNodeLabel = "kubernetes.io/arch"
+
+// nodeAffinityArchs returns the archs required via the node affinity of the pod
+func nodeAffinityArchs(spec corev1.PodSpec) []string {
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil ||
+		spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	var archs []string
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expression := range term.MatchExpressions {
+			if expression.Key != archNodeLabel || expression.Operator != corev1.NodeSelectorOpIn {
+				continue
+			}
+			for _, arch := range expression.Values {
+				if !contains(archs, arch) {
+					archs = append(archs, arch)
+				}
+			}
+		}
+	}
+	return archs
+}
+
+func (mb *MultipleArchitecturesBundleReport) isDeploymentImage(image string) bool {
+	for _, v := range mb.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range v.Spec.Template.Spec.Containers {
+			if c.Image == image {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func providesArch(platforms []platform, arch string) bool {
+	for _, p := range platforms {
+		if p.Architecture == arch {
+			return true
+		}
+	}
+	return false
+}
+
+func providesOS(platforms []platform, os string) bool {
+	for _, p := range platforms {
+		if p.OS == os {
+			return true
+		}
+	}
+	return false
+}
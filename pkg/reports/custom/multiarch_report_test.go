@@ -0,0 +1,272 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	semverv4 "github.com/blang/semver/v4"
+	opversion "github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// fakeManifests returns the platforms of the manifest lists of the images (os/arch). The images without
+// platforms are not manifest lists and the images which are not in the map cannot be inspected.
+func fakeManifests(images map[string][]string) func(string, string) (pkg.DockerManifestInspect, error) {
+	return func(image string, _ string) (pkg.DockerManifestInspect, error) {
+		platforms, ok := images[image]
+		if !ok {
+			return pkg.DockerManifestInspect{}, fmt.Errorf("manifest unknown")
+		}
+		manifest := pkg.DockerManifestInspect{}
+		for _, p := range platforms {
+			values := strings.Split(p, "/")
+			manifest.ManifestData = append(manifest.ManifestData,
+				pkg.ManifestData{Platform: pkg.Platform{OS: values[0], Architecture: values[1]}})
+		}
+		return manifest, nil
+	}
+}
+
+func TestMultiarchPlatformMatrix(t *testing.T) {
+	defer func() { inspectManifest = pkg.RunDockerManifestInspect }()
+	inspectManifest = fakeManifests(map[string][]string{
+		"quay.io/example/foo:v1":     {"linux/amd64", "linux/arm64", "linux/s390x", "unknown/unknown"},
+		"quay.io/example/proxy:v1":   {"linux/amd64", "linux/arm64"},
+		"quay.io/example/operand:v1": {},
+	})
+
+	head := bundles.Column{PackageName: "foo", IsHeadOfChannel: true, IsFromDefaultChannel: true,
+		Channels: []string{"stable"}, DefaultChannel: "stable",
+		BundleCSV: &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0"},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				InstallStrategy: v1alpha1.NamedInstallStrategy{StrategySpec: v1alpha1.StrategyDetailsDeployment{
+					DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{{
+						Name: "foo-controller-manager",
+						Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{Name: "manager", Image: "quay.io/example/foo:v1"},
+								{Name: "kube-rbac-proxy", Image: "quay.io/example/proxy:v1"},
+							},
+						}}},
+					}},
+				}},
+				RelatedImages: []v1alpha1.RelatedImage{
+					{Name: "manager", Image: "quay.io/example/foo:v1"},
+					{Name: "operand", Image: "quay.io/example/operand:v1"},
+					{Name: "other", Image: "quay.io/example/other:v1"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		archs    []string
+		affinity []string
+		// wantArchs are the columns of the matrix
+		wantArchs string
+		// wantMatrix has the kind, the matrix and the missing archs of each image
+		wantMatrix   map[string]string
+		wantErrors   []string
+		wantWarnings int
+	}{
+		{
+			// the operand is not a manifest list and the manager provides s390x which is not declared nor
+			// in the node affinity and the other image cannot be inspected
+			name:      "should check all images of the CSV against the archs declared",
+			archs:     []string{"amd64", "arm64"},
+			affinity:  []string{"amd64", "arm64"},
+			wantArchs: "amd64,arm64,s390x",
+			wantMatrix: map[string]string{
+				"quay.io/example/foo:v1":     "manager yes,yes,yes []",
+				"quay.io/example/operand:v1": "related ?,?,? []",
+				"quay.io/example/other:v1":   "related ?,?,? []",
+				"quay.io/example/proxy:v1":   "container yes,yes,no []",
+			},
+			wantErrors:   []string{"quay.io/example/operand:v1 is not a manifest list"},
+			wantWarnings: 3,
+		},
+		{
+			name:      "should inform the archs declared which are not provided by the images",
+			archs:     []string{"amd64", "arm64", "ppc64le"},
+			wantArchs: "amd64,arm64,ppc64le,s390x",
+			wantMatrix: map[string]string{
+				"quay.io/example/foo:v1":     "manager yes,yes,no,yes [ppc64le]",
+				"quay.io/example/operand:v1": "related ?,?,?,? []",
+				"quay.io/example/other:v1":   "related ?,?,?,? []",
+				"quay.io/example/proxy:v1":   "container yes,yes,no,no [ppc64le]",
+			},
+			wantErrors: []string{"quay.io/example/foo:v1 does not provide the arch(s) ppc64le",
+				"quay.io/example/operand:v1 is not a manifest list",
+				"quay.io/example/proxy:v1 does not provide the arch(s) ppc64le"},
+			// the images are also not in a node affinity
+			wantWarnings: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column := head
+			column.BundleCSV = head.BundleCSV.DeepCopy()
+			column.BundleCSV.Labels = map[string]string{}
+			for _, arch := range tt.archs {
+				column.BundleCSV.Labels[operatorFrameworkArchLabel+arch] = "supported"
+			}
+			if len(tt.affinity) > 0 {
+				column.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Affinity =
+					&corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: archNodeLabel, Operator: corev1.NodeSelectorOpIn, Values: tt.affinity},
+							}}},
+						},
+					}}
+			}
+
+			mb := MultipleArchitecturesBundleReport{BundleData: column}
+			mb.prepareDataPerBundle(newManifestInspector(pkg.Docker))
+			if !mb.HasMultiArchSupport || strings.Join(mb.PlatformArchs, ",") != tt.wantArchs ||
+				len(mb.ImagePlatforms) != len(tt.wantMatrix) {
+				t.Fatalf("unexpected matrix: %v %+v", mb.PlatformArchs, mb.ImagePlatforms)
+			}
+			for _, ip := range mb.ImagePlatforms {
+				got := fmt.Sprintf("%s %s %v", ip.Kind, strings.Join(ip.Matrix, ","), ip.MissingArchs)
+				if got != tt.wantMatrix[ip.Image] {
+					t.Errorf("got %q for %s, want %q", got, ip.Image, tt.wantMatrix[ip.Image])
+				}
+			}
+			if !containsAll(mb.Errors, tt.wantErrors) || len(mb.Warnings) != tt.wantWarnings {
+				t.Errorf("unexpected errors %v and warnings %v", mb.Errors, mb.Warnings)
+			}
+		})
+	}
+}
+
+func TestMultiarchBundlesInScope(t *testing.T) {
+	// the same bundle can be more than once in the reports generated by older versions
+	var columns []bundles.Column
+	for _, v := range []struct {
+		version          string
+		channels         []string
+		head, deprecated bool
+	}{
+		{version: "1.0.0", channels: []string{"stable", "fast"}},
+		{version: "1.1.0", channels: []string{"stable"}, head: true, deprecated: true},
+		{version: "1.2.0", channels: []string{"fast"}, head: true},
+		{version: "1.2.0", channels: []string{"fast"}, head: true},
+	} {
+		columns = append(columns, bundles.Column{PackageName: "foo", DefaultChannel: "stable", Channels: v.channels,
+			IsHeadOfChannel: v.head, IsDeprecated: v.deprecated, IsFromDefaultChannel: contains(v.channels, "stable"),
+			BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "foo.v" + v.version}}})
+	}
+	columns = append(columns, bundles.Column{PackageName: "bar"})
+
+	tests := []struct {
+		scope string
+		want  []string
+	}{
+		{scope: MultiarchScopeDefaultHead},
+		{scope: MultiarchScopeAllHeads, want: []string{"foo.v1.1.0", "foo.v1.2.0"}},
+		{scope: MultiarchScopeAllBundles, want: []string{"foo.v1.0.0", "foo.v1.1.0", "foo.v1.2.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			var got []string
+			for _, b := range bundlesInScope(columns, tt.scope) {
+				got = append(got, b.BundleName())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("bundlesInScope() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiarchArchsPerChannel(t *testing.T) {
+	var reports []MultipleArchitecturesBundleReport
+	for _, v := range []struct {
+		version  string
+		channels []string
+		archs    []string
+	}{
+		{version: "1.10.0", channels: []string{"fast"}, archs: []string{"amd64"}},
+		{version: "1.2.0", channels: []string{"fast"}, archs: []string{"amd64", "arm64"}},
+		{version: "1.0.0", channels: []string{"stable", "fast"}, archs: []string{"amd64"}},
+	} {
+		mb := MultipleArchitecturesBundleReport{AllArchFound: map[string]string{},
+			BundleData: bundles.Column{PackageName: "foo", DefaultChannel: "stable", Channels: v.channels,
+				BundleCSV: &v1alpha1.ClusterServiceVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: "foo.v" + v.version},
+					Spec: v1alpha1.ClusterServiceVersionSpec{
+						Version: opversion.OperatorVersion{Version: semverv4.MustParse(v.version)}},
+				}}}
+		for _, arch := range v.archs {
+			mb.AllArchFound[arch] = arch
+		}
+		reports = append(reports, mb)
+	}
+
+	channels := archsPerChannel(reports)
+	if len(channels) != 2 || channels[0].Name != "stable" || !channels[0].IsDefault || channels[1].Name != "fast" {
+		t.Fatalf("unexpected channels: %+v", channels)
+	}
+	var got []string
+	for _, v := range channels[1].Versions {
+		got = append(got, fmt.Sprintf("%s %v +%v -%v", v.Version, v.Archs, v.Added, v.Removed))
+	}
+	want := "1.0.0 [amd64] +[] -[]|1.2.0 [amd64 arm64] +[arm64] -[]|1.10.0 [amd64] +[] -[arm64]"
+	if strings.Join(got, "|") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, "|"), want)
+	}
+}
+
+func TestLoadImagesFromCSV(t *testing.T) {
+	csv := v1alpha1.ClusterServiceVersion{}
+	csv.Annotations = map[string]string{"alm-examples": `[{"spec": {"image": "quay.io/example/operand:v1"}}]`}
+	csv.Spec.RelatedImages = []v1alpha1.RelatedImage{{Name: "db", Image: "quay.io/example/db:v1"}}
+	csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "quay.io/example/init:v1"}},
+			Containers: []corev1.Container{
+				{Name: "manager", Image: "quay.io/example/manager:v1", Env: []corev1.EnvVar{
+					{Name: pkg.RelatedImageEnvPrefix + "PROXY", Value: "quay.io/example/proxy:v1"}}},
+				{Name: "kube-rbac-proxy", Image: "quay.io/example/kube-rbac-proxy:v1"},
+			},
+		}}},
+	}}
+
+	managerImages, allOtherImages := loadImagesFromCSV(csv)
+	if len(managerImages) != 1 || managerImages["quay.io/example/manager:v1"] == nil {
+		t.Errorf("unexpected manager images: %v", managerImages)
+	}
+	for _, image := range []string{"quay.io/example/operand:v1", "quay.io/example/db:v1", "quay.io/example/init:v1",
+		"quay.io/example/proxy:v1", "quay.io/example/kube-rbac-proxy:v1"} {
+		if _, ok := allOtherImages[image]; !ok {
+			t.Errorf("the image %s was not loaded, got %v", image, allOtherImages)
+		}
+	}
+	if _, ok := allOtherImages["quay.io/example/manager:v1"]; ok {
+		t.Errorf("the manager image should not be in the other images")
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Output formats supported by the custom reports
+const (
+	HTML = "html"
+	YAML = "yaml"
+)
+
+// OutputFormats returns the output formats supported by the custom reports
+func OutputFormats() []string {
+	return []string{HTML, pkg.JSON, YAML}
+}
+
+// ValidateOutputFormat returns an error when the output format informed via the flags is not supported
+func ValidateOutputFormat() error {
+	for _, format := range OutputFormats() {
+		if Flags.OutputFormat == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value informed via the --output flag :%v. "+
+		"The available options are: %s", Flags.OutputFormat, strings.Join(OutputFormats(), ", "))
+}
+
+// WriteReport writes the report in the JSON or YAML format informed via the flags in the output path. The file
+// is named as the HTML dashboard, e.g. qa_quay.io_operatorhubio_catalog_latest.json
+func WriteReport(report interface{}, imageName, reportType string) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if Flags.OutputFormat == pkg.JSON {
+		return pkg.WriteJSON(data, imageName, Flags.OutputPath, reportType)
+	}
+
+	data, err = yaml.JSONToYAML(data)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(Flags.OutputPath, pkg.GetReportName(imageName, reportType, YAML))
+	return os.WriteFile(path, data, 0644)
+}
+
+// bundleRef identifies the bundle in the JSON and YAML outputs, instead of all data of the bundles report
+type bundleRef struct {
+	PackageName     string   `json:"packageName"`
+	Name            string   `json:"name"`
+	Version         string   `json:"version,omitempty"`
+	BundleImagePath string   `json:"bundleImagePath,omitempty"`
+	Channels        []string `json:"channels,omitempty"`
+	IsHeadOfChannel bool     `json:"isHeadOfChannel"`
+	IsDeprecated    bool     `json:"isDeprecated"`
+	MaxOCPVersion   string   `json:"maxOCPVersion,omitempty"`
+}
+
+func newBundleRef(col bundles.Column) bundleRef {
+	ref := bundleRef{
+		PackageName:     col.PackageName,
+		Name:            col.BundleName(),
+		BundleImagePath: col.BundleImagePath,
+		Channels:        col.Channels,
+		IsHeadOfChannel: col.IsHeadOfChannel,
+		IsDeprecated:    col.IsDeprecated,
+		MaxOCPVersion:   col.MaxOCPVersion,
+	}
+	if col.BundleCSV != nil {
+		ref.Version = col.BundleCSV.Spec.Version.String()
+	}
+	return ref
+}
+
+// MarshalJSON outputs the bundle as a bundleRef
+func (b BundleDeprecate) MarshalJSON() ([]byte, error) {
+	type alias BundleDeprecate
+	return json.Marshal(struct {
+		Bundle bundleRef `json:"bundle"`
+		alias
+	}{Bundle: newBundleRef(b.BundleData), alias: alias(b)})
+}
+
+// MarshalJSON outputs the bundle as a bundleRef
+func (b MultipleArchitecturesBundleReport) MarshalJSON() ([]byte, error) {
+	type alias MultipleArchitecturesBundleReport
+	return json.Marshal(struct {
+		Bundle bundleRef `json:"bundle"`
+		alias
+	}{Bundle: newBundleRef(b.BundleData), alias: alias(b)})
+}
+
+// MarshalJSON outputs the bundle as a bundleRef
+func (b ValidatorReportBundle) MarshalJSON() ([]byte, error) {
+	type alias ValidatorReportBundle
+	return json.Marshal(struct {
+		Bundle bundleRef `json:"bundle"`
+		alias
+	}{Bundle: newBundleRef(b.BundleData), alias: alias(b)})
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func TestWriteReport(t *testing.T) {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Name = "foo.v0.1.0"
+	csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse("0.1.0")}
+	report := ValidatorReport{
+		ImageName: "quay.io/my/index:v4.10",
+		Packages: []ValidatorPkg{{Name: "foo", Bundles: []ValidatorReportBundle{{
+			BundleData:  bundles.Column{PackageName: "foo", BundleCSV: csv, IsHeadOfChannel: true},
+			Validations: []string{"error: invalid CSV"},
+		}}}},
+	}
+
+	defer func() { Flags = BindFlags{} }()
+	Flags.OutputPath = t.TempDir()
+
+	Flags.OutputFormat = "json"
+	if err := WriteReport(&report, report.ImageName, "validator"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(Flags.OutputPath, "validator_quay.io_my_index_v4.10.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		ImageName string `json:"imageName"`
+		Packages  []struct {
+			Bundles []struct {
+				Bundle      bundleRef `json:"bundle"`
+				Validations []string  `json:"validations"`
+			} `json:"bundles"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	bundle := got.Packages[0].Bundles[0]
+	want := bundleRef{PackageName: "foo", Name: "foo.v0.1.0", Version: "0.1.0", IsHeadOfChannel: true}
+	if got.ImageName != report.ImageName || bundle.Bundle.Name != want.Name ||
+		bundle.Bundle.Version != want.Version || !bundle.Bundle.IsHeadOfChannel || len(bundle.Validations) != 1 {
+		t.Errorf("unexpected json output: %s", data)
+	}
+
+	Flags.OutputFormat = YAML
+	if err := WriteReport(&report, report.ImageName, "validator"); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(Flags.OutputPath, "validator_quay.io_my_index_v4.10.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"imageName: quay.io/my/index:v4.10", "packageName: foo", "- 'error: invalid CSV'"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("yaml output does not contain %q:\n%s", s, data)
+		}
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	defer func() { Flags = BindFlags{} }()
+	for _, format := range OutputFormats() {
+		Flags.OutputFormat = format
+		if err := ValidateOutputFormat(); err != nil {
+			t.Errorf("unexpected error for %s: %v", format, err)
+		}
+	}
+	Flags.OutputFormat = "xml"
+	if err := ValidateOutputFormat(); err == nil {
+		t.Error("expected an error for xml")
+	}
+}
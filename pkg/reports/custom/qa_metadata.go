@@ -0,0 +1,310 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	semverv4 "github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// The checks of the metadata of the CSVs (shown on OperatorHub) which can be enabled in the profile
+const (
+	QAMetadataDescription    = "description"
+	QAMetadataIcon           = "icon"
+	QAMetadataMaintainers    = "maintainers"
+	QAMetadataLinks          = "links"
+	QAMetadataKeywords       = "keywords"
+	QAMetadataMinKubeVersion = "minKubeVersion"
+	QAMetadataProvider       = "provider"
+	QAMetadataContainerImage = "containerImage"
+	QAMetadataCreatedAt      = "createdAt"
+	QAMetadataRepository     = "repository"
+)
+
+// NOT_AVAILABLE is the result of the checks which cannot be done because the data is not in the report
+// nolint:golint
+const NOT_AVAILABLE = "NOT AVAILABLE"
+
+// QAMetadataChecks returns the checks of the metadata of the CSVs
+func QAMetadataChecks() []string {
+	return []string{QAMetadataDescription, QAMetadataIcon, QAMetadataMaintainers, QAMetadataLinks,
+		QAMetadataKeywords, QAMetadataMinKubeVersion, QAMetadataProvider, QAMetadataContainerImage,
+		QAMetadataCreatedAt, QAMetadataRepository}
+}
+
+// createdAtLayouts are the formats accepted for the createdAt annotation
+var createdAtLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// markdownLink matches the links and images of the description, e.g. [docs](https://example.com)
+var markdownLink = regexp.MustCompile(`\]\(([^)]*)\)`)
+
+// MetadataRules defines the metadata checks which are done and their criteria
+type MetadataRules struct {
+	// Checks are the checks enabled. See QAMetadataChecks.
+	Checks []string `json:"checks,omitempty"`
+	// MinDescriptionLength is the minimum number of characters of the description
+	MinDescriptionLength int `json:"minDescriptionLength,omitempty"`
+	// MaxIconSize is the maximum size in bytes of the icon (decoded)
+	MaxIconSize int `json:"maxIconSize,omitempty"`
+	// IconMediatypes are the media types allowed for the icon
+	IconMediatypes []string `json:"iconMediatypes,omitempty"`
+}
+
+// MetadataCheck is the result of a check of the metadata of the CSVs of the head of channels
+type MetadataCheck struct {
+	Name     string   `json:"name"`
+	Result   string   `json:"result"`
+	Color    string   `json:"-"`
+	Findings []string `json:"findings,omitempty"`
+}
+
+func isQAMetadataCheck(check string) bool {
+	for _, c := range QAMetadataChecks() {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMetadata does the checks of the metadata enabled in the profile. The description and the icon are not
+// checked when they were not kept in the report (--csv-detail=summary).
+func (p *PackageQA) checkMetadata(csvDetail string) {
+	for _, check := range qaProfile.Metadata.Checks {
+		result := MetadataCheck{Name: check}
+		if csvDetail != "" && csvDetail != bundles.CSVDetailFull &&
+			(check == QAMetadataDescription || check == QAMetadataIcon) {
+			result.Result = NOT_AVAILABLE
+			result.Color = BLACK
+			p.MetadataChecks = append(p.MetadataChecks, result)
+			continue
+		}
+
+		for _, v := range p.HeadOfChannels {
+			csv := v.BundleData.BundleCSV
+			if csv == nil {
+				continue
+			}
+			for _, finding := range metadataFindings(check, csv, qaProfile.Metadata) {
+				result.Findings = append(result.Findings, fmt.Sprintf("%s: %s", csv.Name, finding))
+			}
+		}
+
+		if len(result.Findings) > 0 {
+			result.Result = WARNINGS
+			result.Color = ORANGE
+		} else {
+			result.Result = PASS
+			result.Color = GREEN
+		}
+		p.MetadataChecks = append(p.MetadataChecks, result)
+	}
+}
+
+// metadataFindings returns the issues found by the check in the CSV informed
+func metadataFindings(check string, csv *v1alpha1.ClusterServiceVersion, rules MetadataRules) []string {
+	switch check {
+	case QAMetadataDescription:
+		return descriptionFindings(csv.Spec.Description, rules.MinDescriptionLength)
+	case QAMetadataIcon:
+		return iconFindings(csv.Spec.Icon, rules)
+	case QAMetadataMaintainers:
+		return maintainersFindings(csv.Spec.Maintainers)
+	case QAMetadataLinks:
+		return linksFindings(csv.Spec.Links)
+	case QAMetadataKeywords:
+		if len(csv.Spec.Keywords) == 0 {
+			return []string{"spec.keywords is not informed"}
+		}
+	case QAMetadataMinKubeVersion:
+		if len(csv.Spec.MinKubeVersion) == 0 {
+			return []string{"spec.minKubeVersion is not informed"}
+		}
+		if _, err := semverv4.ParseTolerant(csv.Spec.MinKubeVersion); err != nil {
+			return []string{fmt.Sprintf("spec.minKubeVersion %q is not a valid version", csv.Spec.MinKubeVersion)}
+		}
+	case QAMetadataProvider:
+		if len(strings.TrimSpace(csv.Spec.Provider.Name)) == 0 {
+			return []string{"spec.provider.name is not informed"}
+		}
+	case QAMetadataContainerImage:
+		return containerImageFindings(csv)
+	case QAMetadataCreatedAt:
+		return createdAtFindings(csv.Annotations["createdAt"])
+	case QAMetadataRepository:
+		repository := csv.Annotations["repository"]
+		if len(repository) == 0 {
+			return []string{"the annotation repository is not informed"}
+		}
+		if !isValidURL(repository) {
+			return []string{fmt.Sprintf("the annotation repository %q is not a valid URL", repository)}
+		}
+	}
+	return nil
+}
+
+// descriptionFindings checks the length of the description and if its Markdown is well-formed: the code blocks
+// are closed and the links have a target
+func descriptionFindings(description string, minLength int) []string {
+	description = strings.TrimSpace(description)
+	if len(description) == 0 {
+		return []string{"spec.description is not informed"}
+	}
+
+	var findings []string
+	if length := len([]rune(description)); length < minLength {
+		findings = append(findings, fmt.Sprintf("spec.description has %d characters and should have at least %d",
+			length, minLength))
+	}
+
+	fences := 0
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fences++
+			continue
+		}
+		if strings.Contains(line, "](") && len(markdownLink.FindAllString(line, -1)) < strings.Count(line, "](") {
+			findings = append(findings, fmt.Sprintf("spec.description has a link which is not closed: %q", line))
+		}
+	}
+	if fences%2 != 0 {
+		findings = append(findings, "spec.description has a code block which is not closed")
+	}
+	for _, match := range markdownLink.FindAllStringSubmatch(description, -1) {
+		if len(strings.TrimSpace(match[1])) == 0 {
+			findings = append(findings, "spec.description has a link without target")
+		}
+	}
+	return findings
+}
+
+func iconFindings(icons []v1alpha1.Icon, rules MetadataRules) []string {
+	if len(icons) == 0 {
+		return []string{"spec.icon is not informed"}
+	}
+
+	var findings []string
+	for _, icon := range icons {
+		if len(rules.IconMediatypes) > 0 && !contains(rules.IconMediatypes, icon.MediaType) {
+			findings = append(findings, fmt.Sprintf("spec.icon has the mediatype %q which is not one of: %s",
+				icon.MediaType, strings.Join(rules.IconMediatypes, ", ")))
+		}
+		data, err := base64.StdEncoding.DecodeString(icon.Data)
+		if err != nil || len(data) == 0 {
+			findings = append(findings, "spec.icon has no valid base64 data")
+			continue
+		}
+		if rules.MaxIconSize > 0 && len(data) > rules.MaxIconSize {
+			findings = append(findings, fmt.Sprintf("spec.icon has %d bytes and should have at most %d",
+				len(data), rules.MaxIconSize))
+		}
+	}
+	return findings
+}
+
+func maintainersFindings(maintainers []v1alpha1.Maintainer) []string {
+	if len(maintainers) == 0 {
+		return []string{"spec.maintainers is not informed"}
+	}
+
+	var findings []string
+	for _, m := range maintainers {
+		if len(strings.TrimSpace(m.Name)) == 0 {
+			findings = append(findings, fmt.Sprintf("spec.maintainers has a maintainer without name (%s)", m.Email))
+		}
+		if _, err := mail.ParseAddress(m.Email); err != nil {
+			findings = append(findings, fmt.Sprintf("spec.maintainers has the invalid email %q (%s)", m.Email, m.Name))
+		}
+	}
+	return findings
+}
+
+func linksFindings(links []v1alpha1.AppLink) []string {
+	if len(links) == 0 {
+		return []string{"spec.links is not informed"}
+	}
+
+	var findings []string
+	for _, l := range links {
+		if !isValidURL(l.URL) {
+			findings = append(findings, fmt.Sprintf("spec.links has the invalid URL %q (%s)", l.URL, l.Name))
+		}
+	}
+	return findings
+}
+
+// containerImageFindings checks if the annotation containerImage is the image of the manager, which is the
+// container named manager or, when there is no container with this name, any container of the deployments
+func containerImageFindings(csv *v1alpha1.ClusterServiceVersion) []string {
+	containerImage := csv.Annotations["containerImage"]
+	if len(containerImage) == 0 {
+		return []string{"the annotation containerImage is not informed"}
+	}
+
+	var managerImages, images []string
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			images = append(images, c.Image)
+			if c.Name == "manager" {
+				managerImages = append(managerImages, c.Image)
+			}
+		}
+	}
+	if len(managerImages) > 0 {
+		images = managerImages
+	}
+	if len(images) == 0 || contains(images, containerImage) {
+		return nil
+	}
+	return []string{fmt.Sprintf("the annotation containerImage %q does not match the manager image(s): %s",
+		containerImage, strings.Join(images, ", "))}
+}
+
+func createdAtFindings(createdAt string) []string {
+	if len(createdAt) == 0 {
+		return []string{"the annotation createdAt is not informed"}
+	}
+	for _, layout := range createdAtLayouts {
+		if _, err := time.Parse(layout, createdAt); err == nil {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("the annotation createdAt %q is not in the format %s", createdAt, time.RFC3339)}
+}
+
+func isValidURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
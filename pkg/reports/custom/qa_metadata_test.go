@@ -0,0 +1,115 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func setValidMetadata(csv *v1alpha1.ClusterServiceVersion) {
+	csv.Spec.Description = "## Foo Operator\n\n" + strings.Repeat("Manages the foo instances. ", 5) +
+		"See the [docs](https://example.com/docs).\n\n```yaml\nkind: Foo\n```\n"
+	csv.Spec.Icon = []v1alpha1.Icon{{Data: base64.StdEncoding.EncodeToString([]byte("<svg/>")),
+		MediaType: "image/svg+xml"}}
+	csv.Spec.Maintainers = []v1alpha1.Maintainer{{Name: "Foo Team", Email: "foo@example.com"}}
+	csv.Spec.Links = []v1alpha1.AppLink{{Name: "Docs", URL: "https://example.com/docs"}}
+	csv.Spec.Keywords = []string{"foo"}
+	csv.Spec.MinKubeVersion = "1.25.0"
+	csv.Spec.Provider = v1alpha1.AppLink{Name: "Example"}
+	csv.Annotations["containerImage"] = "quay.io/example/foo:v1.0.0"
+	csv.Annotations["createdAt"] = "2024-01-02T15:04:05Z"
+	csv.Annotations["repository"] = "https://github.com/example/foo"
+	csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+		Name: "foo-controller-manager",
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "kube-rbac-proxy", Image: "quay.io/example/proxy:v1"},
+				{Name: "manager", Image: "quay.io/example/foo:v1.0.0"},
+			},
+		}}},
+	}}
+}
+
+func TestMetadataChecks(t *testing.T) {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Annotations = map[string]string{}
+	setValidMetadata(csv)
+	for _, check := range QAMetadataChecks() {
+		if findings := metadataFindings(check, csv, qaProfile.Metadata); len(findings) > 0 {
+			t.Errorf("unexpected findings for %s: %v", check, findings)
+		}
+	}
+
+	csv.Spec.Description = "Foo [docs]() and [guide](https://example.com\n```\nkind: Foo\n"
+	csv.Spec.Icon = []v1alpha1.Icon{{Data: "invalid", MediaType: "image/bmp"}}
+	csv.Spec.Maintainers = []v1alpha1.Maintainer{{Email: "foo"}}
+	csv.Spec.Links = []v1alpha1.AppLink{{Name: "Docs", URL: "example.com"}}
+	csv.Spec.Keywords = nil
+	csv.Spec.MinKubeVersion = "abc"
+	csv.Spec.Provider = v1alpha1.AppLink{}
+	csv.Annotations["containerImage"] = "quay.io/example/proxy:v1"
+	csv.Annotations["createdAt"] = "01/02/2024"
+	csv.Annotations["repository"] = "github.com/example/foo"
+
+	want := map[string]int{
+		QAMetadataDescription:    4,
+		QAMetadataIcon:           2,
+		QAMetadataMaintainers:    2,
+		QAMetadataLinks:          1,
+		QAMetadataKeywords:       1,
+		QAMetadataMinKubeVersion: 1,
+		QAMetadataProvider:       1,
+		QAMetadataContainerImage: 1,
+		QAMetadataCreatedAt:      1,
+		QAMetadataRepository:     1,
+	}
+	for check, count := range want {
+		if findings := metadataFindings(check, csv, qaProfile.Metadata); len(findings) != count {
+			t.Errorf("got %d findings for %s, want %d: %v", len(findings), check, count, findings)
+		}
+	}
+}
+
+func TestCheckMetadata(t *testing.T) {
+	b := newQABundle("foo.v1.0.0", "Full Lifecycle", "stable")
+	pkgQA := PackageQA{HeadOfChannels: []BundleDeprecate{b}}
+	pkgQA.checkMetadata(bundles.CSVDetailSummary)
+	if len(pkgQA.MetadataChecks) != len(QAMetadataChecks()) {
+		t.Fatalf("unexpected checks: %v", pkgQA.MetadataChecks)
+	}
+	for _, m := range pkgQA.MetadataChecks {
+		notAvailable := m.Name == QAMetadataDescription || m.Name == QAMetadataIcon
+		if notAvailable != (m.Result == NOT_AVAILABLE) || (!notAvailable && m.Color != ORANGE) {
+			t.Errorf("unexpected result: %+v", m)
+		}
+	}
+
+	// the checks which are not in the profile are not done
+	defer func() { qaProfile = mustParseQAProfile(qaProfileData) }()
+	qaProfile.Metadata.Checks = []string{QAMetadataKeywords}
+	pkgQA = PackageQA{HeadOfChannels: []BundleDeprecate{b}}
+	pkgQA.checkMetadata("")
+	if len(pkgQA.MetadataChecks) != 1 || pkgQA.MetadataChecks[0].Findings[0] != "foo.v1.0.0: spec.keywords is not informed" {
+		t.Errorf("unexpected checks: %v", pkgQA.MetadataChecks)
+	}
+}
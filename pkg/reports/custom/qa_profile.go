@@ -0,0 +1,230 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The checks of the qa dashboard which can be weighted in the profile
+const (
+	QACheckValidators          = "validators"
+	QACheckScorecard           = "scorecard"
+	QACheckScorecardCustom     = "scorecardCustom"
+	QACheckSDKUsage            = "sdkUsage"
+	QACheckCapabilities        = "capabilities"
+	QACheckDisconnected        = "disconnected"
+	QACheckChannelNaming       = "channelNaming"
+	QACheckDeprecatedAPIs      = "deprecatedAPIs"
+	QACheckRequiredAnnotations = "requiredAnnotations"
+)
+
+// QAChecks returns the checks of the qa dashboard
+func QAChecks() []string {
+	return []string{QACheckValidators, QACheckScorecard, QACheckScorecardCustom, QACheckSDKUsage,
+		QACheckCapabilities, QACheckDisconnected, QACheckChannelNaming, QACheckDeprecatedAPIs,
+		QACheckRequiredAnnotations}
+}
+
+// colorsByName are the colors which can be used in the profile
+var colorsByName = map[string]string{
+	"green":  GREEN,
+	"yellow": YELLOW,
+	"orange": ORANGE,
+	"red":    RED,
+	"black":  BLACK,
+}
+
+//go:embed qa_profile.yaml
+var qaProfileData []byte
+
+// qaProfile is the profile used to grade the packages. It can be replaced with LoadQAProfileFile.
+var qaProfile = mustParseQAProfile(qaProfileData)
+
+// QAProfile defines the criteria used to grade the packages in the qa dashboard. See qa_profile.yaml.
+type QAProfile struct {
+	Name                string            `json:"name"`
+	Weights             map[string]int    `json:"weights"`
+	ColorPoints         map[string]int    `json:"colorPoints"`
+	ScoreThresholds     ScoreThresholds   `json:"scoreThresholds"`
+	CapabilityLevels    map[string]string `json:"capabilityLevels"`
+	ChannelPrefixes     []string          `json:"channelPrefixes"`
+	RequiredAnnotations []string          `json:"requiredAnnotations,omitempty"`
+	Disconnected        DisconnectedRules `json:"disconnected,omitempty"`
+	Metadata            MetadataRules     `json:"metadata,omitempty"`
+}
+
+// ScoreThresholds defines the minimum score of the packages to be shown as green or orange
+type ScoreThresholds struct {
+	Green  int `json:"green"`
+	Orange int `json:"orange"`
+}
+
+// DisconnectedRules defines when the packages are required or should support the disconnected mode
+type DisconnectedRules struct {
+	RequiredPackages     []string `json:"requiredPackages,omitempty"`
+	ShouldSupportIndexes []string `json:"shouldSupportIndexes,omitempty"`
+}
+
+// ParseQAProfile returns the profile from its YAML or JSON representation
+func ParseQAProfile(data []byte) (QAProfile, error) {
+	profile := QAProfile{}
+	if err := yaml.UnmarshalStrict(data, &profile); err != nil {
+		return profile, err
+	}
+
+	if len(profile.Name) == 0 {
+		return profile, fmt.Errorf("the name of the profile is required")
+	}
+	for check, weight := range profile.Weights {
+		if !isQACheck(check) && !isQAMetadataCheck(check) {
+			return profile, fmt.Errorf("invalid check %q. The available options are: %s",
+				check, strings.Join(append(QAChecks(), QAMetadataChecks()...), ", "))
+		}
+		if weight < 0 {
+			return profile, fmt.Errorf("invalid weight %d for the check %s", weight, check)
+		}
+	}
+	for color, points := range profile.ColorPoints {
+		if _, ok := colorsByName[color]; !ok {
+			return profile, fmt.Errorf("invalid color %q in the colorPoints", color)
+		}
+		if points < 0 || points > 100 {
+			return profile, fmt.Errorf("invalid points %d for the color %s. It should be between 0 and 100",
+				points, color)
+		}
+	}
+	for level, color := range profile.CapabilityLevels {
+		if _, ok := colorsByName[color]; !ok {
+			return profile, fmt.Errorf("invalid color %q for the capability level %s", color, level)
+		}
+	}
+	for _, check := range profile.Metadata.Checks {
+		if !isQAMetadataCheck(check) {
+			return profile, fmt.Errorf("invalid metadata check %q. The available options are: %s",
+				check, strings.Join(QAMetadataChecks(), ", "))
+		}
+	}
+	if profile.ScoreThresholds.Orange > profile.ScoreThresholds.Green {
+		return profile, fmt.Errorf("the orange score threshold (%d) should not be greater than the green one (%d)",
+			profile.ScoreThresholds.Orange, profile.ScoreThresholds.Green)
+	}
+	return profile, nil
+}
+
+func mustParseQAProfile(data []byte) QAProfile {
+	profile, err := ParseQAProfile(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid qa_profile.yaml: %s", err))
+	}
+	return profile
+}
+
+// LoadQAProfileFile replaces the profile used to grade the packages with the one in the file informed
+func LoadQAProfileFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	profile, err := ParseQAProfile(data)
+	if err != nil {
+		return fmt.Errorf("unable to load the QA profile from %s: %s", path, err)
+	}
+	qaProfile = profile
+	return nil
+}
+
+func isQACheck(check string) bool {
+	for _, c := range QAChecks() {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilityColor returns the color of the capability level informed and false when it is not in the profile
+func (p QAProfile) capabilityColor(level string) (string, bool) {
+	color, ok := p.CapabilityLevels[level]
+	if !ok {
+		return RED, false
+	}
+	return colorsByName[color], true
+}
+
+// isChannelNameAllowed returns true when the channel starts with one of the prefixes of the profile
+func (p QAProfile) isChannelNameAllowed(channel string) bool {
+	for _, prefix := range p.ChannelPrefixes {
+		if strings.HasPrefix(channel, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDisconnectedRequired returns true when the package is one of the packages required to support the
+// disconnected mode
+func (p QAProfile) isDisconnectedRequired(packageName string) bool {
+	return contains(p.Disconnected.RequiredPackages, packageName)
+}
+
+// shouldSupportDisconnected returns true when the packages of the index image should support the disconnected mode
+func (p QAProfile) shouldSupportDisconnected(imageName string) bool {
+	for _, v := range p.Disconnected.ShouldSupportIndexes {
+		if strings.Contains(imageName, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// score returns the weighted average of the points of the colors of the results of the checks and its color.
+// The checks without weight or which the color has no points are not scored.
+func (p QAProfile) score(results map[string]string) (int, string) {
+	points := map[string]int{}
+	for name, value := range p.ColorPoints {
+		points[colorsByName[name]] = value
+	}
+
+	total, weights := 0, 0
+	for check, color := range results {
+		value, ok := points[color]
+		if !ok || p.Weights[check] == 0 {
+			continue
+		}
+		total += value * p.Weights[check]
+		weights += p.Weights[check]
+	}
+
+	score := 100
+	if weights > 0 {
+		score = int(math.Round(float64(total) / float64(weights)))
+	}
+
+	switch {
+	case score >= p.ScoreThresholds.Green:
+		return score, GREEN
+	case score >= p.ScoreThresholds.Orange:
+		return score, ORANGE
+	default:
+		return score, RED
+	}
+}
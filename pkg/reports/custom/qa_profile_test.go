@@ -0,0 +1,149 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func newQABundle(name, capability string, channels ...string) BundleDeprecate {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Name = name
+	csv.Annotations = map[string]string{"capabilities": capability}
+	return BundleDeprecate{BundleData: bundles.Column{
+		BundleCSV:       csv,
+		PackageName:     "foo",
+		Channels:        channels,
+		DefaultChannel:  channels[0],
+		IsHeadOfChannel: true,
+	}}
+}
+
+func TestDefaultQAProfile(t *testing.T) {
+	if qaProfile.Name != "default" || len(qaProfile.Weights) != len(QAChecks())+len(QAMetadataChecks()) ||
+		len(qaProfile.Metadata.Checks) != len(QAMetadataChecks()) {
+		t.Errorf("unexpected default profile: %+v", qaProfile)
+	}
+
+	b := newQABundle("foo.v1.0.0", "Full Lifecycle", "stable")
+	setValidMetadata(b.BundleData.BundleCSV)
+	pkgQA := NewPkg("foo", []BundleDeprecate{b}, false, "")
+	if pkgQA.CapabilityColor != GREEN || pkgQA.ChannelNamingColor != GREEN ||
+		pkgQA.RequiredAnnotations != "NOT REQUIRED" {
+		t.Errorf("unexpected grade: %+v", pkgQA)
+	}
+	// validators, scorecard, capabilities, channel naming and the APIs removed pass and the disconnected mode
+	// is not used but it is not required and the metadata is valid
+	if pkgQA.Score != 100 || pkgQA.ScoreColor != GREEN {
+		t.Errorf("got the score %d (%s), want 100", pkgQA.Score, pkgQA.ScoreColor)
+	}
+
+	pkgQA = NewPkg("foo", []BundleDeprecate{newQABundle("foo.v1.0.0", "Basic Install", "alpha")}, false, "")
+	if pkgQA.CapabilityColor != ORANGE || pkgQA.ChannelNamingColor != YELLOW || pkgQA.Score >= 100 {
+		t.Errorf("unexpected grade: %+v", pkgQA)
+	}
+}
+
+func TestIsDisconnectedRequired(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageName string
+		want        bool
+	}{
+		{name: "should be required for a package of the list", packageName: "openshift-gitops-operator", want: true},
+		{name: "should not be required for a package which only contains openshift", packageName: "openshift-foo"},
+		{name: "should not be required for a package which contains a package of the list",
+			packageName: "quay-operator-community"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qaProfile.isDisconnectedRequired(tt.packageName); got != tt.want {
+				t.Errorf("isDisconnectedRequired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	b := newQABundle("openshift-foo.v1.0.0", "Full Lifecycle", "stable")
+	pkgQA := NewPkg("openshift-foo", []BundleDeprecate{b}, false, "")
+	if pkgQA.DisconnectedAnnotation == "REQUIRED" {
+		t.Errorf("the disconnected mode should not be required for openshift-foo: %+v", pkgQA)
+	}
+}
+
+func TestLoadQAProfileFile(t *testing.T) {
+	defer func() { qaProfile = mustParseQAProfile(qaProfileData) }()
+
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	data := `name: community
+weights:
+  capabilities: 1
+  channelNaming: 1
+  requiredAnnotations: 2
+colorPoints:
+  green: 100
+  red: 0
+scoreThresholds:
+  green: 90
+  orange: 60
+capabilityLevels:
+  Seamless Upgrades: green
+channelPrefixes: [alpha]
+requiredAnnotations: [repository]
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadQAProfileFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	b := newQABundle("foo.v1.0.0", "Seamless Upgrades", "alpha")
+	b.BundleData.BundleAnnotations = map[string]string{"repository": "https://github.com/example/foo"}
+	pkgQA := NewPkg("foo", []BundleDeprecate{b}, false, "")
+	if pkgQA.Score != 100 || pkgQA.RequiredAnnotations != FOUND {
+		t.Errorf("unexpected grade: %+v", pkgQA)
+	}
+
+	pkgQA = NewPkg("foo", []BundleDeprecate{newQABundle("foo.v1.0.0", "Basic Install", "alpha")}, false, "")
+	if pkgQA.CapabilityColor != RED || len(pkgQA.AnnotationsMissing) != 1 {
+		t.Errorf("unexpected grade: %+v", pkgQA)
+	}
+	// only the channel naming (weight 1) is green from the 4 points of weight
+	if pkgQA.Score != 25 || pkgQA.ScoreColor != RED {
+		t.Errorf("got the score %d (%s), want 25 (red)", pkgQA.Score, pkgQA.ScoreColor)
+	}
+
+	invalid := []string{
+		"weights: {validators: 1}\n",
+		"name: foo\nweights: {unknown: 1}\n",
+		"name: foo\nweights: {validators: -1}\n",
+		"name: foo\ncolorPoints: {purple: 10}\n",
+		"name: foo\ncolorPoints: {green: 101}\n",
+		"name: foo\ncapabilityLevels: {Auto Pilot: purple}\n",
+		"name: foo\nscoreThresholds: {green: 50, orange: 80}\n",
+		"name: foo\nunknown: true\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseQAProfile([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package custom
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/operator-framework/audit/pkg"
@@ -56,71 +57,48 @@ const GREEN = "green"
 const ORANGE = "orange"
 const BLACK = "black"
 
-// the following data came from: https://access.redhat.com/articles/4740011
-var pkgsThatSupportsDisconnectedMode = []string{
-	"3scale apicast-operator",
-	"3scale-operator",
-	"amq-streams",
-	"businessautomation-operator",
-	"cam-operator",
-	"cluster-logging",
-	"codeready-toolchain-operator",
-	"codeready-workspaces",
-	"compliance-operator",
-	"datagrid",
-	"elasticsearch-operator",
-	"file-integrity-operator",
-	"fuse-online",
-	"jaeger-product",
-	"jenkins-operator",
-	"kiali-ossm",
-	"kubevirt-hyperconverge",
-	"local-storage-operator",
-	"metering-ocp",
-	"mtc-operator",
-	"nfd",
-	"ocs-operator",
-	"openshift-gitops-operator",
-	"openshift", // seems that all that we have now should support //todo: identify all names
-	"ptp-operator",
-	"quay", // todo: check the specific names either
-	"serverless-operator",
-	"servicemeshoperator",
-	"sriov-network-operator",
-}
+// colorsSeverity is used to keep the worst result when the head of channels have different ones
+var colorsSeverity = map[string]int{BLACK: 0, GREEN: 1, YELLOW: 2, ORANGE: 3, RED: 4}
 
 type PackageQA struct {
-	PackageName                 string
-	DeprecateAPI                []string
-	DeprecateAPIColor           string
-	CapabilityColor             string
-	DisconnectedAnnotation      string
-	DisconnectedAnnotationColor string
-	ChannelNaming               string
-	ChannelNamingColor          string
-	SDKUsage                    string
-	SDKUsageColor               string
-	ScorecardDefaultImages      string
-	ScorecardDefaultImagesColor string
-	ScorecardCustomImages       string
-	ScorecardCustomImagesColor  string
-	Validators                  string
-	ValidatorsColor             string
-	ChannelNamesNotComply       []string
-	ChannelNamesComply          []string
-	BundlesWithoutDisconnect    []string
-	HeadOfChannels              []BundleDeprecate
-	Capabilities                []string
-	Subscriptions               []string
+	PackageName                 string            `json:"packageName"`
+	DeprecateAPI                []string          `json:"deprecateAPI"`
+	DeprecateAPIColor           string            `json:"-"`
+	CapabilityColor             string            `json:"-"`
+	DisconnectedAnnotation      string            `json:"disconnectedAnnotation"`
+	DisconnectedAnnotationColor string            `json:"-"`
+	ChannelNaming               string            `json:"channelNaming"`
+	ChannelNamingColor          string            `json:"-"`
+	SDKUsage                    string            `json:"sdkUsage"`
+	SDKUsageColor               string            `json:"-"`
+	ScorecardDefaultImages      string            `json:"scorecardDefaultImages"`
+	ScorecardDefaultImagesColor string            `json:"-"`
+	ScorecardCustomImages       string            `json:"scorecardCustomImages"`
+	ScorecardCustomImagesColor  string            `json:"-"`
+	Validators                  string            `json:"validators"`
+	ValidatorsColor             string            `json:"-"`
+	ChannelNamesNotComply       []string          `json:"channelNamesNotComply"`
+	ChannelNamesComply          []string          `json:"channelNamesComply"`
+	BundlesWithoutDisconnect    []string          `json:"bundlesWithoutDisconnect"`
+	HeadOfChannels              []BundleDeprecate `json:"headOfChannels"`
+	Capabilities                []string          `json:"capabilities"`
+	Subscriptions               []string          `json:"subscriptions"`
+	RequiredAnnotations         string            `json:"requiredAnnotations"`
+	RequiredAnnotationsColor    string            `json:"-"`
+	AnnotationsMissing          []string          `json:"annotationsMissing"`
+	MetadataChecks              []MetadataCheck   `json:"metadataChecks"`
+	Score                       int               `json:"score"`
+	ScoreColor                  string            `json:"-"`
 }
 
 type QAReport struct {
-	ImageName    string
-	ImageID      string
-	ImageHash    string
-	ImageBuild   string
-	GeneratedAt  string
-	PackageGrade []PackageQA
+	ImageName    string      `json:"imageName"`
+	ImageID      string      `json:"imageID"`
+	ImageHash    string      `json:"imageHash"`
+	ImageBuild   string      `json:"imageBuild"`
+	GeneratedAt  string      `json:"generatedAt"`
+	Profile      string      `json:"profile"`
+	PackageGrade []PackageQA `json:"packageGrade"`
 }
 
 func NewQAReport(bundlesReport bundles.Report, filter string) *QAReport {
@@ -129,6 +107,7 @@ func NewQAReport(bundlesReport bundles.Report, filter string) *QAReport {
 	gradeReport.ImageID = bundlesReport.IndexImageInspect.ID
 	gradeReport.ImageBuild = bundlesReport.IndexImageInspect.DockerConfig.Labels["build-date"]
 	gradeReport.GeneratedAt = bundlesReport.GenerateAt
+	gradeReport.Profile = qaProfile.Name
 
 	var allBundles []BundleDeprecate
 	for _, v := range bundlesReport.Columns {
@@ -141,15 +120,13 @@ func NewQAReport(bundlesReport bundles.Report, filter string) *QAReport {
 		bd := BundleDeprecate{BundleData: v}
 		bd.AddDeprecateDataFromValidators()
 		bd.AddPotentialWarning()
+		bd.AddRemovedAPIsFromBundle()
 		allBundles = append(allBundles, bd)
 	}
 
 	mapPackagesWithBundles := MapBundlesPerPackage(allBundles)
 
-	isRedHatIndex := false
-	if strings.Contains(gradeReport.ImageName, "redhat-operator-index") {
-		isRedHatIndex = true
-	}
+	shouldSupportDisconnected := qaProfile.shouldSupportDisconnected(gradeReport.ImageName)
 	for key, bds := range mapPackagesWithBundles {
 		if len(key) == 0 {
 			continue
@@ -157,14 +134,18 @@ func NewQAReport(bundlesReport bundles.Report, filter string) *QAReport {
 		if len(bds) == 0 {
 			continue
 		}
-		pkgGrade := NewPkg(key, bds, isRedHatIndex)
+		pkgGrade := NewPkg(key, bds, shouldSupportDisconnected, bundlesReport.Flags.CSVDetail)
 		gradeReport.PackageGrade = append(gradeReport.PackageGrade, pkgGrade)
 	}
 
 	return &gradeReport
 }
 
-func NewPkg(pkgName string, bundlesOfPkg []BundleDeprecate, isRedHatIndex bool) PackageQA {
+// NewPkg returns the grade of the package according to the profile. When shouldSupportDisconnected is true then,
+// the package is expected to support the disconnected mode. The csvDetail is the detail of the CSVs kept in the
+// report (--csv-detail).
+func NewPkg(pkgName string, bundlesOfPkg []BundleDeprecate, shouldSupportDisconnected bool,
+	csvDetail string) PackageQA {
 
 	pkg := PackageQA{PackageName: pkgName}
 
@@ -175,18 +156,22 @@ func NewPkg(pkgName string, bundlesOfPkg []BundleDeprecate, isRedHatIndex bool)
 	pkg.ScorecardDefaultImagesColor = BLACK
 	pkg.ScorecardCustomImagesColor = BLACK
 	pkg.ValidatorsColor = BLACK
+	pkg.RequiredAnnotationsColor = BLACK
 
 	pkg.HeadOfChannels = GetHeadOfChannels(bundlesOfPkg)
 
 	pkg.checkCapability()
-	pkg.checkDisconnectAnnotation(isRedHatIndex)
+	pkg.checkDisconnectAnnotation(shouldSupportDisconnected)
 	pkg.checkScorecard()
 	pkg.checkValidators()
 	pkg.checkChannelNamingScore()
 	pkg.checkSDKUsage()
 	pkg.checkScorecardCustom()
-	pkg.checkRemovalAPIs1_25_26()
+	pkg.checkRemovalAPIsPermissions()
 	pkg.checkSubscriptions()
+	pkg.checkRequiredAnnotations()
+	pkg.checkMetadata(csvDetail)
+	pkg.checkScore()
 
 	return pkg
 }
@@ -197,20 +182,12 @@ func (p *PackageQA) checkCapability() {
 	for _, v := range p.HeadOfChannels {
 
 		l := v.BundleData.BundleCSV.Annotations["capabilities"]
-		switch l {
-		case "Basic Install":
-			p.CapabilityColor = ORANGE
-		case "Seamless Upgrades":
-			p.CapabilityColor = ORANGE
-		case "Full Lifecycle":
-			p.CapabilityColor = GREEN
-		case "Deep Insights":
-			p.CapabilityColor = GREEN
-		case "Auto Pilot":
-			p.CapabilityColor = GREEN
-		default:
+		color, found := qaProfile.capabilityColor(l)
+		if !found {
 			l = l + " - (Invalid level value)"
-			p.CapabilityColor = RED
+		}
+		if colorsSeverity[color] > colorsSeverity[p.CapabilityColor] {
+			p.CapabilityColor = color
 		}
 		levels = append(levels, l)
 	}
@@ -258,17 +235,27 @@ func (p *PackageQA) checkScorecardCustom() {
 	}
 }
 
-func (p *PackageQA) checkRemovalAPIs1_25_26() {
+// checkRemovalAPIsPermissions checks the permissions requested by the head of the channels for the APIs removed
+// in the k8s versions of the table which have the permissions checked and the APIs removed used in their CSVs
+func (p *PackageQA) checkRemovalAPIsPermissions() {
 
 	var listOfWarnings []string
-	for _, v := range p.HeadOfChannels {
-		listOfWarnings = append(listOfWarnings, v.Permissions1_25...)
-		listOfWarnings = append(listOfWarnings, v.Permissions1_26...)
+	used := false
+	for _, version := range RemovedAPIsK8sVersions() {
+		for _, v := range p.HeadOfChannels {
+			for _, finding := range v.FindingsFor(version) {
+				listOfWarnings = append(listOfWarnings, fmt.Sprintf("(k8s %s) %s", version, finding))
+				used = used || !finding.Potential
+			}
+		}
 	}
 
 	listOfWarnings = pkg.GetUniqueValues(listOfWarnings)
 
-	if len(listOfWarnings) > 0 {
+	if used {
+		p.DeprecateAPIColor = RED
+		p.DeprecateAPI = listOfWarnings
+	} else if len(listOfWarnings) > 0 {
 		p.DeprecateAPIColor = ORANGE
 		p.DeprecateAPI = listOfWarnings
 	} else {
@@ -281,7 +268,7 @@ func (p *PackageQA) checkChannelNamingScore() {
 	var OK []string
 	for _, v := range p.HeadOfChannels {
 		for _, c := range v.BundleData.Channels {
-			if !pkg.IsFollowingChannelNameConventional(c) {
+			if !qaProfile.isChannelNameAllowed(c) {
 				foundErrors = append(foundErrors, c)
 			} else {
 				OK = append(OK, c)
@@ -356,17 +343,10 @@ func (p *PackageQA) checkValidators() {
 	}
 }
 
-func (p *PackageQA) checkDisconnectAnnotation(isRedHatIndex bool) {
-	found := false
-	for _, v := range pkgsThatSupportsDisconnectedMode {
-		if strings.Contains(p.PackageName, v) {
-			found = true
-			break
-		}
-	}
+func (p *PackageQA) checkDisconnectAnnotation(shouldSupport bool) {
+	found := qaProfile.isDisconnectedRequired(p.PackageName)
 	for _, b := range p.HeadOfChannels {
-		infra := b.BundleData.BundleCSV.ObjectMeta.Annotations[pkg.InfrastructureAnnotation]
-		if !strings.Contains(infra, "Disconnected") && !strings.Contains(infra, "disconnected") {
+		if !pkg.IsClaimingFeature(b.BundleData.BundleCSV.ObjectMeta.Annotations, pkg.FeatureDisconnected) {
 			p.BundlesWithoutDisconnect = append(p.BundlesWithoutDisconnect, b.BundleData.BundleCSV.Name)
 		}
 	}
@@ -379,7 +359,7 @@ func (p *PackageQA) checkDisconnectAnnotation(isRedHatIndex bool) {
 			p.DisconnectedAnnotation = "REQUIRED"
 			p.DisconnectedAnnotationColor = RED
 		} else {
-			if isRedHatIndex {
+			if shouldSupport {
 				p.DisconnectedAnnotation = "SHOULD SUPPORT"
 				p.DisconnectedAnnotationColor = ORANGE
 			} else {
@@ -446,3 +426,49 @@ func (p *PackageQA) isDefaultChannel(bundle BundleDeprecate) bool {
 	}
 	return false
 }
+
+// checkRequiredAnnotations checks if the head of channels have the annotations required by the profile in the CSV
+// or in the metadata/annotations.yaml
+func (p *PackageQA) checkRequiredAnnotations() {
+	if len(qaProfile.RequiredAnnotations) == 0 {
+		p.RequiredAnnotations = "NOT REQUIRED"
+		return
+	}
+
+	for _, b := range p.HeadOfChannels {
+		for _, annotation := range qaProfile.RequiredAnnotations {
+			if len(b.BundleData.BundleCSV.Annotations[annotation]) == 0 &&
+				len(b.BundleData.BundleAnnotations[annotation]) == 0 {
+				p.AnnotationsMissing = append(p.AnnotationsMissing,
+					fmt.Sprintf("%s: %s", b.BundleData.BundleCSV.Name, annotation))
+			}
+		}
+	}
+
+	if len(p.AnnotationsMissing) > 0 {
+		p.RequiredAnnotationsColor = RED
+		p.RequiredAnnotations = "MISSING"
+	} else {
+		p.RequiredAnnotationsColor = GREEN
+		p.RequiredAnnotations = FOUND
+	}
+}
+
+// checkScore calculates the score of the package with the weights of the checks in the profile
+func (p *PackageQA) checkScore() {
+	results := map[string]string{
+		QACheckValidators:          p.ValidatorsColor,
+		QACheckScorecard:           p.ScorecardDefaultImagesColor,
+		QACheckScorecardCustom:     p.ScorecardCustomImagesColor,
+		QACheckSDKUsage:            p.SDKUsageColor,
+		QACheckCapabilities:        p.CapabilityColor,
+		QACheckDisconnected:        p.DisconnectedAnnotationColor,
+		QACheckChannelNaming:       p.ChannelNamingColor,
+		QACheckDeprecatedAPIs:      p.DeprecateAPIColor,
+		QACheckRequiredAnnotations: p.RequiredAnnotationsColor,
+	}
+	for _, m := range p.MetadataChecks {
+		results[m.Name] = m.Color
+	}
+	p.Score, p.ScoreColor = qaProfile.score(results)
+}
@@ -0,0 +1,357 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Risk levels of the RBAC permissions requested by the bundles
+const (
+	RiskCritical = "critical"
+	RiskHigh     = "high"
+	RiskMedium   = "medium"
+	RiskLow      = "low"
+	RiskNone     = "none"
+)
+
+// DefaultSensitiveVerbs are the verbs checked against the sensitive resources when none is informed
+var DefaultSensitiveVerbs = []string{"create", "update", "patch", "delete", "deletecollection"}
+
+// DefaultSensitiveResources are the resources checked when none is informed. It covers the permissions which
+// allow the operators to change the nodes or to run workloads on all of them.
+var DefaultSensitiveResources = []string{"nodes", "nodes/status", "daemonsets", "machineconfigs",
+	"machineconfigpools"}
+
+// privilegedVerbs are the verbs which allow to get more permissions than the ones granted
+var privilegedVerbs = []string{"escalate", "bind", "impersonate"}
+
+// privilegedVerbResources are the resources where the privileged verbs take effect
+var privilegedVerbResources = map[string][]string{
+	"escalate":    {"roles", "clusterroles"},
+	"bind":        {"roles", "clusterroles"},
+	"impersonate": {"users", "groups", "serviceaccounts"},
+}
+
+// resourceGroups are the apiGroups of the known resources. They are used to check if the resource is granted
+// when the rule uses the resource wildcard.
+var resourceGroups = map[string]string{
+	"secrets":            "",
+	"nodes":              "",
+	"nodes/status":       "",
+	"users":              "",
+	"groups":             "",
+	"serviceaccounts":    "",
+	"daemonsets":         "apps",
+	"roles":              rbacv1.GroupName,
+	"clusterroles":       rbacv1.GroupName,
+	"machineconfigs":     "machineconfiguration.openshift.io",
+	"machineconfigpools": "machineconfiguration.openshift.io",
+}
+
+// Scores of each kind of finding when the permission is granted in the cluster scope. The findings in
+// the namespace scope score the half.
+const (
+	scoreClusterAdmin      = 100
+	scorePrivilegedVerb    = 30
+	scoreSecrets           = 30
+	scoreWildcard          = 10
+	scoreSensitiveResource = 10
+)
+
+// RBACCriteria defines the verbs and resources which are considered sensitive
+type RBACCriteria struct {
+	SensitiveVerbs     []string `json:"sensitiveVerbs"`
+	SensitiveResources []string `json:"sensitiveResources"`
+}
+
+type RBACFinding struct {
+	ServiceAccount string `json:"serviceAccount"`
+	ClusterScope   bool   `json:"clusterScope"`
+	Score          int    `json:"score"`
+	Message        string `json:"message"`
+}
+
+type RBACBundle struct {
+	Name          string        `json:"name"`
+	Channels      []string      `json:"channels,omitempty"`
+	IsFromDefault bool          `json:"isFromDefaultChannel"`
+	Score         int           `json:"score"`
+	Risk          string        `json:"risk"`
+	Color         string        `json:"-"`
+	Findings      []RBACFinding `json:"findings,omitempty"`
+}
+
+type RBACPackage struct {
+	Name    string       `json:"name"`
+	Score   int          `json:"score"`
+	Risk    string       `json:"risk"`
+	Color   string       `json:"-"`
+	Bundles []RBACBundle `json:"bundles"`
+}
+
+type RBACReport struct {
+	ImageName   string        `json:"imageName"`
+	ImageID     string        `json:"imageID"`
+	ImageHash   string        `json:"imageHash,omitempty"`
+	ImageBuild  string        `json:"imageBuild"`
+	GeneratedAt string        `json:"generatedAt"`
+	Criteria    RBACCriteria  `json:"criteria"`
+	Packages    []RBACPackage `json:"packages"`
+}
+
+// NewRBACReport returns the structure to render the rbac custom dashboard with the risk score of the
+// permissions requested by the head of the channels of each package
+// nolint:dupl
+func NewRBACReport(bundlesReport bundles.Report, criteria RBACCriteria, filter string) *RBACReport {
+	rbacReport := RBACReport{Criteria: criteria}
+	rbacReport.ImageName = bundlesReport.Flags.IndexImage
+	rbacReport.ImageID = bundlesReport.IndexImageInspect.ID
+	rbacReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	rbacReport.GeneratedAt = bundlesReport.GenerateAt
+
+	if len(rbacReport.Criteria.SensitiveVerbs) == 0 {
+		rbacReport.Criteria.SensitiveVerbs = DefaultSensitiveVerbs
+	}
+	if len(rbacReport.Criteria.SensitiveResources) == 0 {
+		rbacReport.Criteria.SensitiveResources = DefaultSensitiveResources
+	}
+
+	mapPackagesWithHeads := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if !v.IsHeadOfChannel || v.IsDeprecated || len(v.PackageName) == 0 || v.BundleCSV == nil {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithHeads[v.PackageName] = append(mapPackagesWithHeads[v.PackageName], v)
+	}
+
+	for name, heads := range mapPackagesWithHeads {
+		pkgRBAC := RBACPackage{Name: name}
+		sort.Slice(heads[:], func(i, j int) bool {
+			return heads[i].BundleCSV.Name < heads[j].BundleCSV.Name
+		})
+		for _, head := range heads {
+			bundleRBAC := rbacReport.Criteria.newRBACBundle(head)
+			if bundleRBAC.Score > pkgRBAC.Score {
+				pkgRBAC.Score = bundleRBAC.Score
+			}
+			pkgRBAC.Bundles = append(pkgRBAC.Bundles, bundleRBAC)
+		}
+		pkgRBAC.Risk = riskForScore(pkgRBAC.Score)
+		pkgRBAC.Color = colorForRisk(pkgRBAC.Risk)
+		rbacReport.Packages = append(rbacReport.Packages, pkgRBAC)
+	}
+
+	sort.Slice(rbacReport.Packages[:], func(i, j int) bool {
+		if rbacReport.Packages[i].Score == rbacReport.Packages[j].Score {
+			return rbacReport.Packages[i].Name < rbacReport.Packages[j].Name
+		}
+		return rbacReport.Packages[i].Score > rbacReport.Packages[j].Score
+	})
+
+	return &rbacReport
+}
+
+func (c RBACCriteria) newRBACBundle(head bundles.Column) RBACBundle {
+	bundleRBAC := RBACBundle{
+		Name:          head.BundleCSV.Name,
+		Channels:      head.Channels,
+		IsFromDefault: head.IsFromDefaultChannel,
+	}
+
+	strategy := head.BundleCSV.Spec.InstallStrategy.StrategySpec
+	for _, perm := range strategy.ClusterPermissions {
+		bundleRBAC.Findings = append(bundleRBAC.Findings, c.checkPermissions(perm, true)...)
+	}
+	for _, perm := range strategy.Permissions {
+		bundleRBAC.Findings = append(bundleRBAC.Findings, c.checkPermissions(perm, false)...)
+	}
+
+	for _, finding := range bundleRBAC.Findings {
+		bundleRBAC.Score += finding.Score
+	}
+	bundleRBAC.Risk = riskForScore(bundleRBAC.Score)
+	bundleRBAC.Color = colorForRisk(bundleRBAC.Risk)
+	return bundleRBAC
+}
+
+// checkPermissions returns the findings of the rules granted to the service account
+func (c RBACCriteria) checkPermissions(perm v1alpha1.StrategyDeploymentPermissions,
+	clusterScope bool) []RBACFinding {
+	var findings []RBACFinding
+	scope := "namespace"
+	if clusterScope {
+		scope = "cluster"
+	}
+	add := func(score int, msg string, args ...interface{}) {
+		if !clusterScope {
+			score /= 2
+		}
+		findings = append(findings, RBACFinding{
+			ServiceAccount: perm.ServiceAccountName,
+			ClusterScope:   clusterScope,
+			Score:          score,
+			Message:        fmt.Sprintf("(%s scope) ", scope) + fmt.Sprintf(msg, args...),
+		})
+	}
+
+	for _, rule := range perm.Rules {
+		// non resource urls are not checked
+		if len(rule.Resources) == 0 {
+			continue
+		}
+
+		if isClusterAdminRule(rule) {
+			if clusterScope {
+				add(scoreClusterAdmin, "grants all verbs on all resources of all apiGroups "+
+					"(cluster-admin equivalent)")
+			} else {
+				add(scoreClusterAdmin, "grants all verbs on all resources of all apiGroups in the namespace")
+			}
+			continue
+		}
+
+		if containsRBAC(rule.Verbs, rbacv1.VerbAll) {
+			add(scoreWildcard, "grants all verbs on %s", strings.Join(rule.Resources, ","))
+		}
+		if containsRBAC(rule.Resources, rbacv1.ResourceAll) {
+			add(scoreWildcard, "grants %s on all resources of the apiGroups %s",
+				strings.Join(rule.Verbs, ","), strings.Join(rule.APIGroups, ","))
+		}
+
+		for _, verb := range privilegedVerbs {
+			if grantsVerb(rule, verb) && grantsAnyResource(rule, privilegedVerbResources[verb]) {
+				add(scorePrivilegedVerb, "grants %s on %s", verb, strings.Join(rule.Resources, ","))
+			}
+		}
+
+		if clusterScope && isCoreGroup(rule.APIGroups) && grantsResource(rule, "secrets") &&
+			hasAnyVerb(rule.Verbs, []string{"get", "list", "watch"}) {
+			add(scoreSecrets, "grants %s on secrets in all namespaces", strings.Join(rule.Verbs, ","))
+		}
+
+		for _, resource := range c.SensitiveResources {
+			if !grantsResource(rule, resource) {
+				continue
+			}
+			if hasAnyVerb(rule.Verbs, c.SensitiveVerbs) {
+				add(scoreSensitiveResource, "grants %s on the sensitive resource %s",
+					strings.Join(rule.Verbs, ","), resource)
+			}
+		}
+	}
+	return findings
+}
+
+func isClusterAdminRule(rule rbacv1.PolicyRule) bool {
+	return containsRBAC(rule.APIGroups, rbacv1.APIGroupAll) &&
+		containsRBAC(rule.Resources, rbacv1.ResourceAll) &&
+		containsRBAC(rule.Verbs, rbacv1.VerbAll)
+}
+
+func isCoreGroup(apiGroups []string) bool {
+	return containsRBAC(apiGroups, "") || containsRBAC(apiGroups, rbacv1.APIGroupAll)
+}
+
+// hasAnyVerb returns true when the rule grants any of the verbs informed
+func hasAnyVerb(ruleVerbs []string, verbs []string) bool {
+	if containsRBAC(ruleVerbs, rbacv1.VerbAll) {
+		return true
+	}
+	for _, verb := range verbs {
+		if containsRBAC(ruleVerbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantsVerb returns true when the rule grants the verb, either by its name or by the wildcard
+func grantsVerb(rule rbacv1.PolicyRule, verb string) bool {
+	return containsRBAC(rule.Verbs, verb) || containsRBAC(rule.Verbs, rbacv1.VerbAll)
+}
+
+// grantsResource returns true when the rule grants the resource, either by its name or by the wildcard.
+// When the wildcard is used and the apiGroup of the resource is known, the rule must also grant its apiGroup.
+func grantsResource(rule rbacv1.PolicyRule, resource string) bool {
+	if containsRBAC(rule.Resources, resource) {
+		return true
+	}
+	if !containsRBAC(rule.Resources, rbacv1.ResourceAll) {
+		return false
+	}
+	group, known := resourceGroups[strings.ToLower(resource)]
+	return !known || containsRBAC(rule.APIGroups, group) || containsRBAC(rule.APIGroups, rbacv1.APIGroupAll)
+}
+
+func grantsAnyResource(rule rbacv1.PolicyRule, resources []string) bool {
+	for _, resource := range resources {
+		if grantsResource(rule, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRBAC returns true when the value is found. Note that the wildcard only matches itself
+// so that, it can be used to check the wildcard usage. Use grantsVerb and grantsResource to check
+// if the permission is granted.
+func containsRBAC(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func riskForScore(score int) string {
+	switch {
+	case score >= scoreClusterAdmin:
+		return RiskCritical
+	case score >= scorePrivilegedVerb:
+		return RiskHigh
+	case score >= scoreWildcard:
+		return RiskMedium
+	case score > 0:
+		return RiskLow
+	default:
+		return RiskNone
+	}
+}
+
+func colorForRisk(risk string) string {
+	switch risk {
+	case RiskCritical, RiskHigh:
+		return RED
+	case RiskMedium:
+		return ORANGE
+	case RiskLow:
+		return YELLOW
+	default:
+		return GREEN
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestCheckPermissionsScore(t *testing.T) {
+	criteria := RBACCriteria{SensitiveVerbs: DefaultSensitiveVerbs, SensitiveResources: DefaultSensitiveResources}
+
+	tests := []struct {
+		name         string
+		rule         rbacv1.PolicyRule
+		clusterScope bool
+		wantScore    int
+		wantRisk     string
+		wantFinding  string
+	}{
+		{
+			name: "should be critical when grants all verbs on all resources of all apiGroups",
+			rule: rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"},
+				Verbs: []string{"*"}},
+			clusterScope: true,
+			wantScore:    scoreClusterAdmin,
+			wantRisk:     RiskCritical,
+			wantFinding:  "cluster-admin equivalent",
+		},
+		{
+			name: "should score secrets, impersonate and nodes when grants all on the core apiGroup",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*"},
+				Verbs: []string{"*"}},
+			clusterScope: true,
+			wantScore: 2*scoreWildcard + scoreSecrets + scorePrivilegedVerb +
+				2*scoreSensitiveResource,
+			wantRisk:    RiskCritical,
+			wantFinding: "on secrets in all namespaces",
+		},
+		{
+			name: "should score the half when grants all on the core apiGroup in the namespace",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*"},
+				Verbs: []string{"*"}},
+			wantScore:   scoreWildcard + scorePrivilegedVerb/2 + scoreSensitiveResource,
+			wantRisk:    RiskHigh,
+			wantFinding: "grants impersonate on *",
+		},
+		{
+			name: "should score escalate and bind when grants all verbs on the roles",
+			rule: rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName},
+				Resources: []string{"clusterroles"}, Verbs: []string{"*"}},
+			clusterScope: true,
+			wantScore:    scoreWildcard + 2*scorePrivilegedVerb,
+			wantRisk:     RiskHigh,
+			wantFinding:  "grants escalate on clusterroles",
+		},
+		{
+			name: "should not score the privileged verbs when grants all verbs on other resources",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"},
+				Verbs: []string{"*"}},
+			clusterScope: true,
+			wantScore:    scoreWildcard,
+			wantRisk:     RiskMedium,
+			wantFinding:  "grants all verbs on configmaps",
+		},
+		{
+			name: "should score secrets when reads them in all namespaces",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"},
+				Verbs: []string{"get", "list"}},
+			clusterScope: true,
+			wantScore:    scoreSecrets,
+			wantRisk:     RiskHigh,
+			wantFinding:  "grants get,list on secrets in all namespaces",
+		},
+		{
+			name: "should not score the resources of other apiGroups when grants all resources",
+			rule: rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"*"},
+				Verbs: []string{"get"}},
+			clusterScope: true,
+			wantScore:    scoreWildcard,
+			wantRisk:     RiskMedium,
+			wantFinding:  "grants get on all resources of the apiGroups apps",
+		},
+		{
+			name: "should score the sensitive resources of the apiGroup when grants all resources",
+			rule: rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"*"},
+				Verbs: []string{"create"}},
+			clusterScope: true,
+			wantScore:    scoreWildcard + scoreSensitiveResource,
+			wantRisk:     RiskMedium,
+			wantFinding:  "grants create on the sensitive resource daemonsets",
+		},
+		{
+			name: "should not score the read only permissions",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods", "configmaps"},
+				Verbs: []string{"get", "list", "watch"}},
+			clusterScope: true,
+			wantRisk:     RiskNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perm := v1alpha1.StrategyDeploymentPermissions{ServiceAccountName: "operator",
+				Rules: []rbacv1.PolicyRule{tt.rule}}
+			findings := criteria.checkPermissions(perm, tt.clusterScope)
+
+			score := 0
+			var msgs []string
+			for _, finding := range findings {
+				score += finding.Score
+				msgs = append(msgs, finding.Message)
+			}
+			if score != tt.wantScore {
+				t.Errorf("score = %d, want %d (%v)", score, tt.wantScore, msgs)
+			}
+			if risk := riskForScore(score); risk != tt.wantRisk {
+				t.Errorf("risk = %s, want %s", risk, tt.wantRisk)
+			}
+			if len(tt.wantFinding) > 0 && !strings.Contains(strings.Join(msgs, "\n"), tt.wantFinding) {
+				t.Errorf("findings %v do not contain %q", msgs, tt.wantFinding)
+			}
+		})
+	}
+}
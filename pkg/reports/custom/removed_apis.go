@@ -0,0 +1,156 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	semverv4 "github.com/blang/semver/v4"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultK8sVersion is the k8s version used to check the APIs removed when none is informed
+const DefaultK8sVersion = "1.22"
+
+//go:embed removed_apis.yaml
+var removedAPIsData []byte
+
+// removedAPIs is the table used to check the APIs removed. It can be replaced with LoadRemovedAPIsFile.
+var removedAPIs = mustParseRemovedAPIs(removedAPIsData)
+
+// RemovedAPI defines an API which is no longer served
+type RemovedAPI struct {
+	Group      string `json:"group"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Resource   string `json:"resource"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+func (a RemovedAPI) String() string {
+	return fmt.Sprintf("%s/%s %s", a.Group, a.Version, a.Kind)
+}
+
+// APIRemovals defines the APIs removed in a k8s version
+type APIRemovals struct {
+	K8sVersion string `json:"k8sVersion"`
+	OCPVersion string `json:"ocpVersion,omitempty"`
+	// CheckPermissions is true when the packages which request permissions for the APIs are potentially impacted
+	CheckPermissions bool         `json:"checkPermissions,omitempty"`
+	APIs             []RemovedAPI `json:"apis,omitempty"`
+}
+
+// RemovedAPIsTable defines the APIs removed per k8s version. See removed_apis.yaml.
+type RemovedAPIsTable struct {
+	Removals []APIRemovals `json:"removals"`
+}
+
+// ParseRemovedAPIs returns the table from its YAML or JSON representation sorted by the k8s versions
+func ParseRemovedAPIs(data []byte) (RemovedAPIsTable, error) {
+	table := RemovedAPIsTable{}
+	if err := yaml.UnmarshalStrict(data, &table); err != nil {
+		return table, err
+	}
+
+	found := map[string]bool{}
+	for i, r := range table.Removals {
+		version, err := NormalizeK8sVersion(r.K8sVersion)
+		if err != nil {
+			return table, err
+		}
+		if found[version] {
+			return table, fmt.Errorf("the k8s version %s is informed more than once", version)
+		}
+		found[version] = true
+		table.Removals[i].K8sVersion = version
+
+		for _, api := range r.APIs {
+			if len(api.Group) == 0 || len(api.Version) == 0 || len(api.Kind) == 0 || len(api.Resource) == 0 {
+				return table, fmt.Errorf("the group, version, kind and resource are required for the APIs "+
+					"removed in %s: %s", version, api)
+			}
+		}
+	}
+
+	sort.SliceStable(table.Removals, func(i, j int) bool {
+		return compareK8sVersions(table.Removals[i].K8sVersion, table.Removals[j].K8sVersion) < 0
+	})
+	return table, nil
+}
+
+func mustParseRemovedAPIs(data []byte) RemovedAPIsTable {
+	table, err := ParseRemovedAPIs(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid removed_apis.yaml: %s", err))
+	}
+	return table
+}
+
+// LoadRemovedAPIsFile replaces the table of the APIs removed with the one in the file informed
+func LoadRemovedAPIsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	table, err := ParseRemovedAPIs(data)
+	if err != nil {
+		return fmt.Errorf("unable to load the APIs removed from %s: %s", path, err)
+	}
+	removedAPIs = table
+	return nil
+}
+
+// RemovedAPIsFor returns the APIs removed in the k8s version informed. The version is returned without APIs
+// when it is not found in the table.
+func RemovedAPIsFor(k8sVersion string) APIRemovals {
+	for _, r := range removedAPIs.Removals {
+		if r.K8sVersion == k8sVersion {
+			return r
+		}
+	}
+	return APIRemovals{K8sVersion: k8sVersion}
+}
+
+// RemovedAPIsK8sVersions returns the k8s versions which have APIs removed in the table
+func RemovedAPIsK8sVersions() []string {
+	var versions []string
+	for _, r := range removedAPIs.Removals {
+		if len(r.APIs) > 0 {
+			versions = append(versions, r.K8sVersion)
+		}
+	}
+	return versions
+}
+
+// NormalizeK8sVersion returns the k8s version informed as major.minor, e.g. v1.25.0 is returned as 1.25
+func NormalizeK8sVersion(k8sVersion string) (string, error) {
+	version, err := semverv4.ParseTolerant(strings.TrimSpace(k8sVersion))
+	if err != nil || version.Major != 1 {
+		return "", fmt.Errorf("invalid k8s version %q. The version should be informed as 1.<minor>, e.g. 1.25",
+			k8sVersion)
+	}
+	return fmt.Sprintf("%d.%d", version.Major, version.Minor), nil
+}
+
+// compareK8sVersions compares the versions informed as major.minor
+func compareK8sVersions(a, b string) int {
+	va, _ := semverv4.ParseTolerant(a)
+	vb, _ := semverv4.ParseTolerant(b)
+	return va.Compare(vb)
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func TestRemovedAPIsTable(t *testing.T) {
+	versions := RemovedAPIsK8sVersions()
+	for _, v := range []string{"1.22", "1.25", "1.26", "1.27", "1.29", "1.32"} {
+		if !contains(versions, v) {
+			t.Errorf("expected the version %s in the table, got %v", v, versions)
+		}
+	}
+	if removals := RemovedAPIsFor("1.29"); removals.OCPVersion != "4.16" || len(removals.APIs) == 0 {
+		t.Errorf("unexpected removals for 1.29: %v", removals)
+	}
+	if removals := RemovedAPIsFor("1.40"); removals.K8sVersion != "1.40" || len(removals.APIs) > 0 {
+		t.Errorf("unexpected removals for 1.40: %v", removals)
+	}
+
+	for in, want := range map[string]string{"1.25": "1.25", "v1.29.0": "1.29", " 1.32 ": "1.32"} {
+		if got, err := NormalizeK8sVersion(in); err != nil || got != want {
+			t.Errorf("NormalizeK8sVersion(%q) = %s, %v, want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "abc", "2.1"} {
+		if _, err := NormalizeK8sVersion(in); err == nil {
+			t.Errorf("expected an error for %q", in)
+		}
+	}
+}
+
+func TestLoadRemovedAPIsFile(t *testing.T) {
+	defer func() { removedAPIs = mustParseRemovedAPIs(removedAPIsData) }()
+
+	path := filepath.Join(t.TempDir(), "removed.yaml")
+	data := `removals:
+  - k8sVersion: "v1.40"
+    ocpVersion: "4.27"
+    checkPermissions: true
+    apis:
+      - {group: example.com, version: v1beta1, kind: Foo, resource: foos, replacedBy: example.com/v1}
+  - k8sVersion: "1.39"
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadRemovedAPIsFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if versions := RemovedAPIsK8sVersions(); len(versions) != 1 || versions[0] != "1.40" {
+		t.Errorf("got %v, want [1.40]", versions)
+	}
+
+	bd := BundleDeprecate{BundleData: bundles.Column{BundleCSV: &v1alpha1.ClusterServiceVersion{}}}
+	bd.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{
+		{ServiceAccountName: "foo-operator",
+			Rules: []rbacv1.PolicyRule{{APIGroups: []string{"example.com"}, Resources: []string{"foos"}}}},
+	}
+	bd.AddPotentialWarning()
+	want := "(apiGroups/resources): example.com/foos in clusterPermissions of the service account foo-operator"
+	if got := bd.Permissions["1.40"]; len(got) != 1 || got[0] != want {
+		t.Errorf("unexpected permissions: %v", bd.Permissions)
+	}
+	if findings := bd.FindingsFor("1.40"); len(findings) != 1 || !findings[0].Potential {
+		t.Errorf("unexpected findings: %v", bd.Findings)
+	}
+
+	invalid := []string{
+		"removals:\n  - k8sVersion: abc\n",
+		"removals:\n  - k8sVersion: \"1.25\"\n  - k8sVersion: \"1.25\"\n",
+		"removals:\n  - k8sVersion: \"1.25\"\n    apis:\n      - {group: batch, version: v1beta1}\n",
+		"unknown: true\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseRemovedAPIs([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestRemovedAPIsFromValidators(t *testing.T) {
+	bd := BundleDeprecate{BundleData: bundles.Column{ValidatorWarnings: []string{
+		"this bundle is using APIs which were deprecated and removed in v1.25. More info: " +
+			"https://kubernetes.io/docs/reference/using-api/deprecation-guide/#v1-25. Migrate the API(s) for " +
+			"cronjobs: ([\"batch/v1beta1\"])",
+	}}}
+	bd.AddDeprecateDataFromValidators()
+	if len(bd.RemovedAPIsFor("1.25")) != 1 || len(bd.RemovedAPIsFor("1.22")) != 0 {
+		t.Errorf("unexpected APIs removed: %v", bd.APIsRemoved)
+	}
+
+	report, err := NewAPIDashReport(bundles.Report{Columns: []bundles.Column{bd.BundleData}},
+		map[string]string{K8sVersionKey: "1.25"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.K8SVersion != "1.25" || report.OCPVersion != "4.12" || !report.CheckPermissions ||
+		len(report.NotMigrated) != 1 || len(report.NotChecked) != 1 || report.NotChecked[0] != "manifests" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	if _, err := NewAPIDashReport(bundles.Report{}, map[string]string{K8sVersionKey: "abc"}, ""); err == nil {
+		t.Errorf("NewAPIDashReport() expected error for invalid k8s version")
+	}
+	if report, err = NewAPIDashReport(bundles.Report{}, nil, ""); err != nil || report.K8SVersion != DefaultK8sVersion {
+		t.Errorf("NewAPIDashReport() should use the default k8s version, got %v, %v", report, err)
+	}
+	if report.NotChecked != nil {
+		t.Errorf("NewAPIDashReport() got sources not checked %v for the full CSV", report.NotChecked)
+	}
+
+	summary := bundles.Report{Flags: bundles.BindFlags{CSVDetail: bundles.CSVDetailSummary}}
+	if report, err = NewAPIDashReport(summary, nil, ""); err != nil || len(report.NotChecked) != 1 ||
+		report.NotChecked[0] != "alm-examples" {
+		t.Errorf("NewAPIDashReport() should not check the alm-examples of the CSV summary, got %v, %v", report, err)
+	}
+}
+
+func TestRemovedAPIsFromCSV(t *testing.T) {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.Annotations = map[string]string{"alm-examples": `[
+		{"apiVersion": "batch/v1beta1", "kind": "CronJob", "metadata": {"name": "example"}},
+		{"apiVersion": "batch/v1", "kind": "CronJob", "metadata": {"name": "migrated"}}
+	]`}
+	csv.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{{
+		GenerateName: "vpolicy.example.com",
+		Rules: []admissionregistrationv1.RuleWithOperations{{Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{"policy"},
+			APIVersions: []string{"v1beta1", "*"},
+			Resources:   []string{"poddisruptionbudgets/status", "*"},
+		}}},
+	}}
+	csv.Spec.APIServiceDefinitions.Owned = []v1alpha1.APIServiceDescription{
+		{Name: "flowschemas", Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Kind: "FlowSchema"},
+	}
+	csv.Spec.NativeAPIs = []metav1.GroupVersionKind{
+		{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"},
+	}
+
+	bd := BundleDeprecate{BundleData: bundles.Column{BundleCSV: csv}}
+	bd.AddRemovedAPIsFromBundle()
+
+	want := map[string]string{
+		"alm-examples (CronJob example)":           "1.25",
+		"webhookdefinitions (vpolicy.example.com)": "1.25",
+		"nativeAPIs": "1.26",
+		"apiservicedefinitions.owned (flowschemas)": "1.29",
+	}
+	if len(bd.Findings) != len(want) {
+		t.Fatalf("unexpected findings: %v", bd.Findings)
+	}
+	for _, f := range bd.Findings {
+		if want[f.Source] != f.K8sVersion || f.Potential || len(f.ReplacedBy) == 0 {
+			t.Errorf("unexpected finding: %+v", f)
+		}
+	}
+	if len(bd.RemovedAPIsFor("1.25")) != 2 || len(bd.RemovedAPIsFor("1.22")) != 0 {
+		t.Errorf("unexpected APIs removed: %v", bd.APIsRemoved)
+	}
+
+	// the findings are not duplicated when the CSV is checked again
+	bd.AddRemovedAPIsFromBundle()
+	if len(bd.Findings) != len(want) {
+		t.Errorf("unexpected findings: %v", bd.Findings)
+	}
+
+	csv.Annotations["alm-examples"] = "invalid"
+	bd = BundleDeprecate{BundleData: bundles.Column{BundleCSV: csv}}
+	bd.AddRemovedAPIsFromBundle()
+	if len(bd.Findings) != 3 {
+		t.Errorf("unexpected findings: %v", bd.Findings)
+	}
+}
+
+func TestRemovedAPIsFromManifests(t *testing.T) {
+	csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0"}}
+	flowSchema := &unstructured.Unstructured{}
+	flowSchema.SetAPIVersion("flowcontrol.apiserver.k8s.io/v1beta2")
+	flowSchema.SetKind("FlowSchema")
+	flowSchema.SetName("foo-operator")
+	bundle := &apimanifests.Bundle{CSV: csv, Objects: []*unstructured.Unstructured{flowSchema}}
+	column := bundles.NewColumn(models.AuditBundle{PackageName: "foo", IsHeadOfChannel: true,
+		Channels: []string{"stable"}, Bundle: bundle})
+	// the validators only report the versions which they know and are not used when the manifests are informed
+	column.ValidatorWarnings = []string{"this bundle is using APIs which were deprecated and removed in v1.22."}
+
+	bd := BundleDeprecate{BundleData: *column}
+	bd.AddDeprecateDataFromValidators()
+	bd.AddRemovedAPIsFromBundle()
+	findings := bd.FindingsFor("1.29")
+	if len(findings) != 1 || findings[0].Source != "manifests (FlowSchema foo-operator)" ||
+		findings[0].ReplacedBy != "flowcontrol.apiserver.k8s.io/v1" || len(bd.RemovedAPIsFor("1.22")) != 0 {
+		t.Errorf("unexpected findings: %v (%v)", bd.Findings, bd.APIsRemoved)
+	}
+
+	report, err := NewAPIDashReport(bundles.Report{Columns: []bundles.Column{*column}},
+		map[string]string{K8sVersionKey: "1.29"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.NotMigrated) != 1 || len(report.Migrated) != 0 || report.NotChecked != nil {
+		t.Errorf("the package should not be migrated: %+v", report)
+	}
+
+	qa := NewQAReport(bundles.Report{Columns: []bundles.Column{*column}}, "")
+	if len(qa.PackageGrade) != 1 || qa.PackageGrade[0].DeprecateAPIColor != RED {
+		t.Errorf("the APIs removed should be reported by the QA: %+v", qa)
+	}
+}
+
+func TestAddPotentialWarningWildcards(t *testing.T) {
+	bd := BundleDeprecate{BundleData: bundles.Column{BundleCSV: &v1alpha1.ClusterServiceVersion{}}}
+	bd.BundleData.BundleCSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{
+		{ServiceAccountName: "foo-operator", Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"policy"}, Resources: []string{"*"}},
+			{APIGroups: []string{"*"}, Resources: []string{"cronjobs"}},
+		}},
+	}
+	bd.AddPotentialWarning()
+
+	want := []string{
+		"(All from apiGroups): policy/* in clusterPermissions of the service account foo-operator",
+		"(apiGroups/resources): */cronjobs in clusterPermissions of the service account foo-operator",
+	}
+	got := bd.Permissions["1.25"]
+	sort.Strings(got)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got permissions %v, want %v", got, want)
+	}
+	if findings := bd.FindingsFor("1.25"); len(findings) != len(want) {
+		t.Errorf("unexpected findings: %v", findings)
+	}
+}
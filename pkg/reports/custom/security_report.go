@@ -0,0 +1,309 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Pod Security Admission levels. See: https://kubernetes.io/docs/concepts/security/pod-security-standards/
+const (
+	PSARestricted = "restricted"
+	PSABaseline   = "baseline"
+	PSAPrivileged = "privileged"
+)
+
+// baselineCapabilities are the capabilities which can be added by the workloads under the baseline level
+var baselineCapabilities = []corev1.Capability{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL",
+	"MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+
+// securityFinding defines an issue found in the workload and the most restrictive PSA level
+// which the workload still complies with. Findings without level are only informative.
+type securityFinding struct {
+	msg   string
+	level string
+}
+
+type SecurityBundle struct {
+	Name          string   `json:"name"`
+	Channels      []string `json:"channels"`
+	IsFromDefault bool     `json:"isFromDefault"`
+	Level         string   `json:"level"`
+	Color         string   `json:"-"`
+	Violations    []string `json:"violations"`
+	Warnings      []string `json:"warnings"`
+}
+
+type SecurityPackage struct {
+	Name    string           `json:"name"`
+	Level   string           `json:"level"`
+	Color   string           `json:"-"`
+	Bundles []SecurityBundle `json:"bundles"`
+}
+
+type SecurityReport struct {
+	ImageName   string            `json:"imageName"`
+	ImageID     string            `json:"imageID"`
+	ImageHash   string            `json:"imageHash"`
+	ImageBuild  string            `json:"imageBuild"`
+	GeneratedAt string            `json:"generatedAt"`
+	Restricted  []SecurityPackage `json:"restricted"`
+	Baseline    []SecurityPackage `json:"baseline"`
+	Privileged  []SecurityPackage `json:"privileged"`
+}
+
+// NewSecurityReport returns the structure to render the security custom dashboard with the Pod Security
+// Admission level which each package complies with from the deployments of the head of the channels
+// nolint:dupl
+func NewSecurityReport(bundlesReport bundles.Report, filter string) *SecurityReport {
+	securityReport := SecurityReport{}
+	securityReport.ImageName = bundlesReport.Flags.IndexImage
+	securityReport.ImageID = bundlesReport.IndexImageInspect.ID
+	securityReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	securityReport.GeneratedAt = bundlesReport.GenerateAt
+
+	mapPackagesWithHeads := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if !v.IsHeadOfChannel || v.IsDeprecated || len(v.PackageName) == 0 || v.BundleCSV == nil {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithHeads[v.PackageName] = append(mapPackagesWithHeads[v.PackageName], v)
+	}
+
+	for name, heads := range mapPackagesWithHeads {
+		pkgSecurity := newSecurityPackage(name, heads)
+		switch pkgSecurity.Level {
+		case PSARestricted:
+			securityReport.Restricted = append(securityReport.Restricted, pkgSecurity)
+		case PSABaseline:
+			securityReport.Baseline = append(securityReport.Baseline, pkgSecurity)
+		default:
+			securityReport.Privileged = append(securityReport.Privileged, pkgSecurity)
+		}
+	}
+
+	for _, list := range [][]SecurityPackage{securityReport.Restricted, securityReport.Baseline,
+		securityReport.Privileged} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &securityReport
+}
+
+// newSecurityPackage returns the level of the package which is the level of the head of the default channel.
+// If it is not found, then the less restrictive level found in the heads of the channels is used.
+func newSecurityPackage(name string, heads []bundles.Column) SecurityPackage {
+	pkgSecurity := SecurityPackage{Name: name, Level: PSARestricted}
+
+	sort.Slice(heads[:], func(i, j int) bool {
+		return heads[i].BundleCSV.Name < heads[j].BundleCSV.Name
+	})
+
+	levelFromDefault := ""
+	for _, head := range heads {
+		bundleSecurity := newSecurityBundle(head)
+		if bundleSecurity.IsFromDefault {
+			levelFromDefault = bundleSecurity.Level
+		}
+		pkgSecurity.Level = lessRestrictiveLevel(pkgSecurity.Level, bundleSecurity.Level)
+		pkgSecurity.Bundles = append(pkgSecurity.Bundles, bundleSecurity)
+	}
+	if len(levelFromDefault) > 0 {
+		pkgSecurity.Level = levelFromDefault
+	}
+	pkgSecurity.Color = colorForLevel(pkgSecurity.Level)
+	return pkgSecurity
+}
+
+func newSecurityBundle(head bundles.Column) SecurityBundle {
+	bundleSecurity := SecurityBundle{
+		Name:          head.BundleCSV.Name,
+		Channels:      head.Channels,
+		IsFromDefault: head.IsFromDefaultChannel,
+		Level:         PSARestricted,
+	}
+
+	if len(head.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs) == 0 {
+		bundleSecurity.Warnings = append(bundleSecurity.Warnings, "no deployments found in the CSV")
+	}
+	for _, deployment := range head.BundleCSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, finding := range checkPodSecurity(deployment.Spec.Template.Spec) {
+			msg := fmt.Sprintf("(deployment: %s) %s", deployment.Name, finding.msg)
+			if len(finding.level) == 0 {
+				bundleSecurity.Warnings = append(bundleSecurity.Warnings, msg)
+				continue
+			}
+			bundleSecurity.Violations = append(bundleSecurity.Violations, msg)
+			bundleSecurity.Level = lessRestrictiveLevel(bundleSecurity.Level, finding.level)
+		}
+	}
+
+	bundleSecurity.Color = colorForLevel(bundleSecurity.Level)
+	return bundleSecurity
+}
+
+// checkPodSecurity returns the findings of the pod spec against the Pod Security Standards
+func checkPodSecurity(spec corev1.PodSpec) []securityFinding {
+	var findings []securityFinding
+
+	if spec.HostNetwork {
+		findings = append(findings, securityFinding{"uses hostNetwork", PSAPrivileged})
+	}
+	if spec.HostPID {
+		findings = append(findings, securityFinding{"uses hostPID", PSAPrivileged})
+	}
+	if spec.HostIPC {
+		findings = append(findings, securityFinding{"uses hostIPC", PSAPrivileged})
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			findings = append(findings, securityFinding{
+				fmt.Sprintf("uses the hostPath volume %s (%s)", volume.Name, volume.HostPath.Path), PSAPrivileged})
+		}
+	}
+
+	podContext := spec.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, finding := range checkContainerSecurity(container, podContext) {
+			finding.msg = fmt.Sprintf("container %s %s", container.Name, finding.msg)
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// checkContainerSecurity returns the findings of the container. Note that the values set in the container
+// take precedence over the ones set in the pod
+func checkContainerSecurity(container corev1.Container, podContext *corev1.PodSecurityContext) []securityFinding {
+	var findings []securityFinding
+
+	securityContext := container.SecurityContext
+	if securityContext == nil {
+		securityContext = &corev1.SecurityContext{}
+	}
+
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		findings = append(findings, securityFinding{"is privileged", PSAPrivileged})
+	}
+
+	for _, port := range container.Ports {
+		if port.HostPort != 0 {
+			findings = append(findings, securityFinding{fmt.Sprintf("uses the hostPort %d", port.HostPort),
+				PSAPrivileged})
+		}
+	}
+
+	if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+		findings = append(findings, securityFinding{"does not set allowPrivilegeEscalation=false", PSABaseline})
+	}
+
+	runAsNonRoot := podContext.RunAsNonRoot
+	if securityContext.RunAsNonRoot != nil {
+		runAsNonRoot = securityContext.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		findings = append(findings, securityFinding{"does not set runAsNonRoot=true", PSABaseline})
+	}
+
+	runAsUser := podContext.RunAsUser
+	if securityContext.RunAsUser != nil {
+		runAsUser = securityContext.RunAsUser
+	}
+	if runAsUser != nil && *runAsUser == 0 {
+		findings = append(findings, securityFinding{"runs as root (runAsUser=0)", PSABaseline})
+	}
+
+	if securityContext.Capabilities != nil {
+		for _, capability := range securityContext.Capabilities.Add {
+			switch {
+			case !containsCapability(baselineCapabilities, capability):
+				findings = append(findings, securityFinding{fmt.Sprintf("adds the capability %s", capability),
+					PSAPrivileged})
+			case capability != "NET_BIND_SERVICE":
+				findings = append(findings, securityFinding{fmt.Sprintf("adds the capability %s", capability),
+					PSABaseline})
+			}
+		}
+	}
+	if securityContext.Capabilities == nil || !containsCapability(securityContext.Capabilities.Drop, "ALL") {
+		findings = append(findings, securityFinding{"does not drop ALL capabilities", PSABaseline})
+	}
+
+	seccompProfile := podContext.SeccompProfile
+	if securityContext.SeccompProfile != nil {
+		seccompProfile = securityContext.SeccompProfile
+	}
+	switch {
+	case seccompProfile == nil:
+		findings = append(findings, securityFinding{"does not set a seccomp profile", PSABaseline})
+	case seccompProfile.Type == corev1.SeccompProfileTypeUnconfined:
+		findings = append(findings, securityFinding{"uses the Unconfined seccomp profile", PSAPrivileged})
+	}
+
+	if securityContext.ReadOnlyRootFilesystem == nil || !*securityContext.ReadOnlyRootFilesystem {
+		findings = append(findings, securityFinding{"does not set readOnlyRootFilesystem=true", ""})
+	}
+
+	return findings
+}
+
+func containsCapability(list []corev1.Capability, capability corev1.Capability) bool {
+	for _, v := range list {
+		if strings.EqualFold(string(v), string(capability)) {
+			return true
+		}
+	}
+	return false
+}
+
+// lessRestrictiveLevel returns the less restrictive level between both informed
+func lessRestrictiveLevel(a, b string) string {
+	order := map[string]int{PSARestricted: 0, PSABaseline: 1, PSAPrivileged: 2}
+	if order[b] > order[a] {
+		return b
+	}
+	return a
+}
+
+func colorForLevel(level string) string {
+	switch level {
+	case PSARestricted:
+		return GREEN
+	case PSABaseline:
+		return ORANGE
+	default:
+		return RED
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// restrictedContainer returns a container which complies with the restricted level
+func restrictedContainer() corev1.Container {
+	no, yes := false, true
+	return corev1.Container{Name: "manager", SecurityContext: &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &no,
+		RunAsNonRoot:             &yes,
+		ReadOnlyRootFilesystem:   &yes,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}}
+}
+
+// levelOf returns the level of the findings as it is done for the bundles
+func levelOf(findings []securityFinding) (string, []string) {
+	level := PSARestricted
+	var msgs []string
+	for _, finding := range findings {
+		msgs = append(msgs, finding.msg)
+		if len(finding.level) > 0 {
+			level = lessRestrictiveLevel(level, finding.level)
+		}
+	}
+	return level, msgs
+}
+
+func TestCheckContainerSecurity(t *testing.T) {
+	var root int64
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		change      func(c *corev1.Container, pod *corev1.PodSecurityContext)
+		wantLevel   string
+		wantFinding string
+	}{
+		{
+			name:      "should be restricted when all settings are informed",
+			change:    func(c *corev1.Container, pod *corev1.PodSecurityContext) {},
+			wantLevel: PSARestricted,
+		},
+		{
+			name: "should only inform when the root filesystem is not read only",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.ReadOnlyRootFilesystem = &no
+			},
+			wantLevel:   PSARestricted,
+			wantFinding: "does not set readOnlyRootFilesystem=true",
+		},
+		{
+			name: "should be baseline when the privilege escalation is allowed",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.AllowPrivilegeEscalation = nil
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "does not set allowPrivilegeEscalation=false",
+		},
+		{
+			name: "should use the runAsNonRoot and seccomp profile of the pod when not set in the container",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				pod.RunAsNonRoot = &yes
+				pod.SeccompProfile = c.SecurityContext.SeccompProfile
+				c.SecurityContext.RunAsNonRoot = nil
+				c.SecurityContext.SeccompProfile = nil
+			},
+			wantLevel: PSARestricted,
+		},
+		{
+			name: "should use the value of the container over the pod one",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				pod.RunAsNonRoot = &yes
+				c.SecurityContext.RunAsNonRoot = &no
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "does not set runAsNonRoot=true",
+		},
+		{
+			name: "should be baseline when runs as root",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				pod.RunAsUser = &root
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "runs as root (runAsUser=0)",
+		},
+		{
+			name: "should be baseline when the capabilities are not dropped",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.Capabilities = nil
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "does not drop ALL capabilities",
+		},
+		{
+			name: "should be restricted when adds NET_BIND_SERVICE",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE"}
+			},
+			wantLevel: PSARestricted,
+		},
+		{
+			name: "should be baseline when adds a capability allowed by the baseline level",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.Capabilities.Add = []corev1.Capability{"chown"}
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "adds the capability chown",
+		},
+		{
+			name: "should be privileged when adds other capabilities",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.Capabilities.Add = []corev1.Capability{"SYS_ADMIN"}
+			},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "adds the capability SYS_ADMIN",
+		},
+		{
+			name: "should be baseline without seccomp profile",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.SeccompProfile = nil
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "does not set a seccomp profile",
+		},
+		{
+			name: "should be privileged with the Unconfined seccomp profile",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.SeccompProfile.Type = corev1.SeccompProfileTypeUnconfined
+			},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "uses the Unconfined seccomp profile",
+		},
+		{
+			name: "should be privileged when the container is privileged",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext.Privileged = &yes
+			},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "is privileged",
+		},
+		{
+			name: "should be privileged when uses a host port",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.Ports = []corev1.ContainerPort{{ContainerPort: 8080, HostPort: 8080}}
+			},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "uses the hostPort 8080",
+		},
+		{
+			name: "should be baseline without security context",
+			change: func(c *corev1.Container, pod *corev1.PodSecurityContext) {
+				c.SecurityContext = nil
+			},
+			wantLevel:   PSABaseline,
+			wantFinding: "does not set allowPrivilegeEscalation=false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := restrictedContainer()
+			podContext := &corev1.PodSecurityContext{}
+			tt.change(&container, podContext)
+
+			level, msgs := levelOf(checkContainerSecurity(container, podContext))
+			if level != tt.wantLevel {
+				t.Errorf("checkContainerSecurity() level = %s, want %s (%v)", level, tt.wantLevel, msgs)
+			}
+			if len(tt.wantFinding) > 0 && !strings.Contains(strings.Join(msgs, "\n"), tt.wantFinding) {
+				t.Errorf("checkContainerSecurity() findings = %v, want %q", msgs, tt.wantFinding)
+			}
+			if len(tt.wantFinding) == 0 && len(msgs) > 0 {
+				t.Errorf("checkContainerSecurity() unexpected findings = %v", msgs)
+			}
+		})
+	}
+}
+
+func TestCheckPodSecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        corev1.PodSpec
+		wantLevel   string
+		wantFinding string
+	}{
+		{
+			name:      "should be restricted when the containers are restricted",
+			spec:      corev1.PodSpec{Containers: []corev1.Container{restrictedContainer()}},
+			wantLevel: PSARestricted,
+		},
+		{
+			name:        "should be privileged when uses the host network",
+			spec:        corev1.PodSpec{HostNetwork: true, Containers: []corev1.Container{restrictedContainer()}},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "uses hostNetwork",
+		},
+		{
+			name: "should be privileged when uses a hostPath volume",
+			spec: corev1.PodSpec{Containers: []corev1.Container{restrictedContainer()},
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}}}}},
+			wantLevel:   PSAPrivileged,
+			wantFinding: "uses the hostPath volume data (/var/run)",
+		},
+		{
+			name: "should check the init containers",
+			spec: corev1.PodSpec{Containers: []corev1.Container{restrictedContainer()},
+				InitContainers: []corev1.Container{{Name: "init"}}},
+			wantLevel:   PSABaseline,
+			wantFinding: "container init does not set runAsNonRoot=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, msgs := levelOf(checkPodSecurity(tt.spec))
+			if level != tt.wantLevel {
+				t.Errorf("checkPodSecurity() level = %s, want %s (%v)", level, tt.wantLevel, msgs)
+			}
+			if len(tt.wantFinding) > 0 && !strings.Contains(strings.Join(msgs, "\n"), tt.wantFinding) {
+				t.Errorf("checkPodSecurity() findings = %v, want %q", msgs, tt.wantFinding)
+			}
+		})
+	}
+}
+
+func TestSecurityReportLevelFromDefaultChannel(t *testing.T) {
+	newHead := func(name string, isFromDefault bool, spec corev1.PodSpec) bundles.Column {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.Name = name
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{
+			{Name: "controller", Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: spec}}}}
+		return bundles.Column{PackageName: "memcached", BundleCSV: csv, IsHeadOfChannel: true,
+			IsFromDefaultChannel: isFromDefault}
+	}
+	restricted := corev1.PodSpec{Containers: []corev1.Container{restrictedContainer()}}
+	privileged := corev1.PodSpec{HostPID: true, Containers: []corev1.Container{restrictedContainer()}}
+
+	report := NewSecurityReport(bundles.Report{Columns: []bundles.Column{
+		newHead("memcached.v0.0.2", true, restricted),
+		newHead("memcached.v0.0.3", false, privileged),
+	}}, "")
+	if len(report.Restricted) != 1 || len(report.Privileged) != 0 {
+		t.Fatalf("the level of the head of the default channel should be used: %+v", report)
+	}
+
+	report = NewSecurityReport(bundles.Report{Columns: []bundles.Column{
+		newHead("memcached.v0.0.2", false, restricted),
+		newHead("memcached.v0.0.3", false, privileged),
+	}}, "")
+	if len(report.Privileged) != 1 || report.Privileged[0].Bundles[1].Violations[0] !=
+		"(deployment: controller) uses hostPID" {
+		t.Errorf("the less restrictive level should be used without the default channel: %+v", report)
+	}
+}
@@ -22,23 +22,23 @@ import (
 )
 
 type ValidatorReportBundle struct {
-	BundleData  bundles.Column
-	Validations []string
+	BundleData  bundles.Column `json:"-"`
+	Validations []string       `json:"validations"`
 }
 
 type ValidatorPkg struct {
-	Name    string
-	Bundles []ValidatorReportBundle
+	Name    string                  `json:"name"`
+	Bundles []ValidatorReportBundle `json:"bundles"`
 }
 
 type ValidatorReport struct {
-	ImageName   string
-	ImageID     string
-	ImageHash   string
-	ImageBuild  string
-	GeneratedAt string
-	FilterBy    string
-	Packages    []ValidatorPkg
+	ImageName   string         `json:"imageName"`
+	ImageID     string         `json:"imageID"`
+	ImageHash   string         `json:"imageHash"`
+	ImageBuild  string         `json:"imageBuild"`
+	GeneratedAt string         `json:"generatedAt"`
+	FilterBy    string         `json:"filterBy"`
+	Packages    []ValidatorPkg `json:"packages"`
 }
 
 // nolint:dupl
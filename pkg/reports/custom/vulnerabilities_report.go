@@ -0,0 +1,183 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Results of the vulnerabilities found in the images referenced by the bundles
+const (
+	VulnerabilitiesCritical = "CRITICAL"
+	VulnerabilitiesHigh     = "HIGH"
+	VulnerabilitiesOK       = "NO CRITICAL OR HIGH"
+)
+
+// ImageVulnerabilitiesResult is an image referenced by the bundle with the data to render it
+type ImageVulnerabilitiesResult struct {
+	models.ImageVulnerabilities
+	Color string `json:"-"`
+}
+
+type VulnerabilitiesBundle struct {
+	Name            string   `json:"name"`
+	Channels        []string `json:"channels"`
+	IsHeadOfChannel bool     `json:"isHeadOfChannel"`
+	Result          string   `json:"result"`
+	Color           string   `json:"-"`
+	models.VulnerabilityCounts
+	Images []ImageVulnerabilitiesResult `json:"images"`
+	// Warnings are the images which could not be checked, e.g. when their SBOM was not found
+	Warnings []string `json:"warnings"`
+}
+
+type VulnerabilitiesPackage struct {
+	Name    string                  `json:"name"`
+	Result  string                  `json:"result"`
+	Color   string                  `json:"-"`
+	Bundles []VulnerabilitiesBundle `json:"bundles"`
+}
+
+type VulnerabilitiesReport struct {
+	ImageName   string `json:"imageName"`
+	ImageID     string `json:"imageID"`
+	ImageHash   string `json:"imageHash"`
+	ImageBuild  string `json:"imageBuild"`
+	GeneratedAt string `json:"generatedAt"`
+	// VulnerabilityDB is empty when the bundles report was not generated with --vulnerability-db
+	VulnerabilityDB string                   `json:"vulnerabilityDB"`
+	Critical        []VulnerabilitiesPackage `json:"critical"`
+	High            []VulnerabilitiesPackage `json:"high"`
+	OK              []VulnerabilitiesPackage `json:"ok"`
+}
+
+// NewVulnerabilitiesReport returns the structure to render the vulnerabilities custom dashboard with the
+// vulnerabilities found in the SBOMs of the images referenced by the bundles (see the flag --vulnerability-db of
+// audit-tool index bundles)
+// nolint:dupl
+func NewVulnerabilitiesReport(bundlesReport bundles.Report, filter string) *VulnerabilitiesReport {
+	vulnReport := VulnerabilitiesReport{}
+	vulnReport.ImageName = bundlesReport.Flags.IndexImage
+	vulnReport.ImageID = bundlesReport.IndexImageInspect.ID
+	vulnReport.ImageBuild = bundlesReport.IndexImageInspect.Created
+	vulnReport.GeneratedAt = bundlesReport.GenerateAt
+	vulnReport.VulnerabilityDB = bundlesReport.Flags.VulnerabilityDB
+
+	mapPackagesWithBundles := make(map[string][]bundles.Column)
+	for _, v := range bundlesReport.Columns {
+		if len(v.PackageName) == 0 || v.Vulnerabilities == nil {
+			continue
+		}
+		// filter by the name
+		if len(filter) > 0 && !strings.Contains(v.PackageName, filter) {
+			continue
+		}
+		mapPackagesWithBundles[v.PackageName] = append(mapPackagesWithBundles[v.PackageName], v)
+	}
+
+	for name, bundles := range mapPackagesWithBundles {
+		pkgVuln := newVulnerabilitiesPackage(name, bundles)
+		switch pkgVuln.Result {
+		case VulnerabilitiesOK:
+			vulnReport.OK = append(vulnReport.OK, pkgVuln)
+		case VulnerabilitiesHigh:
+			vulnReport.High = append(vulnReport.High, pkgVuln)
+		default:
+			vulnReport.Critical = append(vulnReport.Critical, pkgVuln)
+		}
+	}
+
+	for _, list := range [][]VulnerabilitiesPackage{vulnReport.Critical, vulnReport.High, vulnReport.OK} {
+		//nolint: scopelint
+		sort.Slice(list[:], func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+	}
+
+	return &vulnReport
+}
+
+// newVulnerabilitiesPackage returns the package with the worst result found in its bundles
+func newVulnerabilitiesPackage(name string, columns []bundles.Column) VulnerabilitiesPackage {
+	pkgVuln := VulnerabilitiesPackage{Name: name, Result: VulnerabilitiesOK}
+
+	sort.Slice(columns[:], func(i, j int) bool {
+		return columns[i].BundleName() < columns[j].BundleName()
+	})
+
+	order := map[string]int{VulnerabilitiesOK: 0, VulnerabilitiesHigh: 1, VulnerabilitiesCritical: 2}
+	for _, column := range columns {
+		bundleVuln := newVulnerabilitiesBundle(column)
+		if order[bundleVuln.Result] > order[pkgVuln.Result] {
+			pkgVuln.Result = bundleVuln.Result
+		}
+		pkgVuln.Bundles = append(pkgVuln.Bundles, bundleVuln)
+	}
+	pkgVuln.Color = colorForVulnerabilitiesResult(pkgVuln.Result)
+	return pkgVuln
+}
+
+// newVulnerabilitiesBundle returns the bundle with the vulnerabilities of its images and with warnings for the
+// images which could not be checked
+func newVulnerabilitiesBundle(column bundles.Column) VulnerabilitiesBundle {
+	bundleVuln := VulnerabilitiesBundle{
+		Name:                column.BundleName(),
+		Channels:            column.Channels,
+		IsHeadOfChannel:     column.IsHeadOfChannel,
+		VulnerabilityCounts: column.Vulnerabilities.VulnerabilityCounts,
+	}
+
+	for _, image := range column.Vulnerabilities.Images {
+		result := ImageVulnerabilitiesResult{ImageVulnerabilities: image, Color: GREEN}
+		switch {
+		case len(image.Error) > 0:
+			result.Color = ORANGE
+			bundleVuln.Warnings = append(bundleVuln.Warnings,
+				fmt.Sprintf("unable to check the vulnerabilities of the image %s: %s", image.Image, image.Error))
+		case image.Critical > 0:
+			result.Color = RED
+		case image.High > 0:
+			result.Color = ORANGE
+		}
+		bundleVuln.Images = append(bundleVuln.Images, result)
+	}
+
+	switch {
+	case bundleVuln.Critical > 0:
+		bundleVuln.Result = VulnerabilitiesCritical
+	case bundleVuln.High > 0:
+		bundleVuln.Result = VulnerabilitiesHigh
+	default:
+		bundleVuln.Result = VulnerabilitiesOK
+	}
+	bundleVuln.Color = colorForVulnerabilitiesResult(bundleVuln.Result)
+	return bundleVuln
+}
+
+func colorForVulnerabilitiesResult(result string) string {
+	switch result {
+	case VulnerabilitiesOK:
+		return GREEN
+	case VulnerabilitiesHigh:
+		return ORANGE
+	default:
+		return RED
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/audit/pkg/models"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func TestVulnerabilitiesBundle(t *testing.T) {
+	column := bundles.Column{PackageName: "foo", IsHeadOfChannel: true, Channels: []string{"stable"},
+		BundleCSV: &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "foo.v1.0.0"}}}
+	clean := models.ImageVulnerabilities{Image: "quay.io/example/clean:v1", SBOMFormat: "cyclonedx", Packages: 10}
+
+	tests := []struct {
+		name         string
+		summary      models.VulnerabilitySummary
+		wantResult   string
+		wantColors   []string
+		wantWarnings []string
+	}{
+		{
+			name:       "should be ok when the images have no critical or high vulnerabilities",
+			summary:    models.VulnerabilitySummary{Images: []models.ImageVulnerabilities{clean}},
+			wantResult: VulnerabilitiesOK,
+			wantColors: []string{GREEN},
+		},
+		{
+			name: "should warn when the SBOM of an image was not found",
+			summary: models.VulnerabilitySummary{Images: []models.ImageVulnerabilities{clean,
+				{Image: "quay.io/example/missing:v1", Error: "the SBOM was not found"}}},
+			wantResult: VulnerabilitiesOK,
+			wantColors: []string{GREEN, ORANGE},
+			wantWarnings: []string{
+				"unable to check the vulnerabilities of the image quay.io/example/missing:v1: the SBOM was not found"},
+		},
+		{
+			name: "should be high when an image has high vulnerabilities",
+			summary: models.VulnerabilitySummary{VulnerabilityCounts: models.VulnerabilityCounts{High: 2, Low: 1},
+				Images: []models.ImageVulnerabilities{clean, {Image: "quay.io/example/high:v1", SBOMFormat: "spdx",
+					VulnerabilityCounts: models.VulnerabilityCounts{High: 2, Low: 1}}}},
+			wantResult: VulnerabilitiesHigh,
+			wantColors: []string{GREEN, ORANGE},
+		},
+		{
+			name: "should be critical when an image has critical vulnerabilities",
+			summary: models.VulnerabilitySummary{VulnerabilityCounts: models.VulnerabilityCounts{Critical: 1, High: 2},
+				Images: []models.ImageVulnerabilities{
+					{Image: "quay.io/example/critical:v1", VulnerabilityCounts: models.VulnerabilityCounts{Critical: 1}},
+					{Image: "quay.io/example/high:v1", VulnerabilityCounts: models.VulnerabilityCounts{High: 2}}}},
+			wantResult: VulnerabilitiesCritical,
+			wantColors: []string{RED, ORANGE},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := tt.summary
+			column.Vulnerabilities = &summary
+			b := newVulnerabilitiesBundle(column)
+			if b.Result != tt.wantResult || b.VulnerabilityCounts != tt.summary.VulnerabilityCounts ||
+				!containsAll(b.Warnings, tt.wantWarnings) || len(b.Images) != len(tt.wantColors) {
+				t.Fatalf("unexpected result: %+v", b)
+			}
+			for i, image := range b.Images {
+				if image.Color != tt.wantColors[i] {
+					t.Errorf("unexpected color for %s: %s", image.Image, image.Color)
+				}
+			}
+		})
+	}
+}
+
+func TestNewVulnerabilitiesReport(t *testing.T) {
+	report := bundles.Report{Flags: bundles.BindFlags{VulnerabilityDB: "osv.json"}}
+	for _, b := range []struct {
+		name   string
+		counts models.VulnerabilityCounts
+	}{
+		{name: "clean.v1.0.0"},
+		{name: "high.v1.0.0", counts: models.VulnerabilityCounts{High: 2}},
+		{name: "critical.v1.0.0"},
+		{name: "critical.v2.0.0", counts: models.VulnerabilityCounts{Critical: 1}},
+	} {
+		report.Columns = append(report.Columns, bundles.Column{PackageName: strings.Split(b.name, ".")[0],
+			BundleCSV:       &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: b.name}},
+			Vulnerabilities: &models.VulnerabilitySummary{VulnerabilityCounts: b.counts}})
+	}
+	report.Columns = append(report.Columns, bundles.Column{PackageName: "unchecked"})
+
+	result := NewVulnerabilitiesReport(report, "")
+	if result.VulnerabilityDB != "osv.json" || len(result.Critical) != 1 || len(result.High) != 1 ||
+		len(result.OK) != 1 {
+		t.Fatalf("unexpected report: %+v", result)
+	}
+	// the package has the worst result of its bundles
+	pkgCritical := result.Critical[0]
+	if pkgCritical.Name != "critical" || len(pkgCritical.Bundles) != 2 ||
+		pkgCritical.Bundles[0].Result != VulnerabilitiesOK || pkgCritical.Bundles[1].Result != VulnerabilitiesCritical {
+		t.Errorf("unexpected critical package: %+v", pkgCritical)
+	}
+
+	if filtered := NewVulnerabilitiesReport(report, "high"); len(filtered.High) != 1 || len(filtered.Critical) != 0 {
+		t.Errorf("unexpected filtered report: %+v", filtered)
+	}
+}
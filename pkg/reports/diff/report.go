@@ -0,0 +1,231 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/operator-framework/audit/pkg"
+)
+
+// Kinds of the findings compared between the reports
+const (
+	ValidatorError   = "validator-error"
+	ValidatorWarning = "validator-warning"
+	ScorecardFailing = "scorecard-failing-test"
+)
+
+// ReportRef identifies the bundles report compared
+type ReportRef struct {
+	Image      string `json:"image"`
+	ImageID    string `json:"imageID,omitempty"`
+	GenerateAt string `json:"generateAt"`
+}
+
+// Change defines a value which has changed between the reports
+type Change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BundleChange defines a value of the bundle which has changed between the reports
+type BundleChange struct {
+	Bundle string `json:"bundle"`
+	Change
+}
+
+// FindingsDiff defines the findings of a bundle which are new or resolved in the report compared
+type FindingsDiff struct {
+	Bundle   string   `json:"bundle"`
+	Kind     string   `json:"kind"`
+	New      []string `json:"new,omitempty"`
+	Resolved []string `json:"resolved,omitempty"`
+}
+
+// PackageDiff defines what has changed in a package which is in both reports
+type PackageDiff struct {
+	Name                 string         `json:"name"`
+	BundlesAdded         []string       `json:"bundlesAdded,omitempty"`
+	BundlesRemoved       []string       `json:"bundlesRemoved,omitempty"`
+	NewHeads             []string       `json:"newHeads,omitempty"`
+	DefaultChannelChange *Change        `json:"defaultChannelChange,omitempty"`
+	MaxOCPVersionChanges []BundleChange `json:"maxOCPVersionChanges,omitempty"`
+	NewlyDeprecated      []string       `json:"newlyDeprecated,omitempty"`
+	Findings             []FindingsDiff `json:"findings,omitempty"`
+}
+
+// Summary has the totals of the changes found
+type Summary struct {
+	PackagesAdded         int `json:"packagesAdded"`
+	PackagesRemoved       int `json:"packagesRemoved"`
+	PackagesChanged       int `json:"packagesChanged"`
+	BundlesAdded          int `json:"bundlesAdded"`
+	BundlesRemoved        int `json:"bundlesRemoved"`
+	NewHeads              int `json:"newHeads"`
+	NewlyDeprecated       int `json:"newlyDeprecated"`
+	NewFindings           int `json:"newFindings"`
+	ResolvedFindings      int `json:"resolvedFindings"`
+	DefaultChannelChanges int `json:"defaultChannelChanges"`
+}
+
+type Report struct {
+	From            ReportRef     `json:"from"`
+	To              ReportRef     `json:"to"`
+	GenerateAt      string        `json:"generateAt"`
+	Summary         Summary       `json:"summary"`
+	PackagesAdded   []string      `json:"packagesAdded,omitempty"`
+	PackagesRemoved []string      `json:"packagesRemoved,omitempty"`
+	Packages        []PackageDiff `json:"packages,omitempty"`
+}
+
+// NewReport returns what has changed from the first report to the second one
+func NewReport(from, to *Snapshot) *Report {
+	report := Report{
+		From:       ReportRef{Image: from.Image, ImageID: from.ImageID, GenerateAt: from.GenerateAt},
+		To:         ReportRef{Image: to.Image, ImageID: to.ImageID, GenerateAt: to.GenerateAt},
+		GenerateAt: time.Now().Format("2006-01-02"),
+	}
+
+	for _, name := range pkg.SortedKeys(to.packages) {
+		if _, ok := from.packages[name]; !ok {
+			report.PackagesAdded = append(report.PackagesAdded, name)
+		}
+	}
+	for _, name := range pkg.SortedKeys(from.packages) {
+		newPkg, ok := to.packages[name]
+		if !ok {
+			report.PackagesRemoved = append(report.PackagesRemoved, name)
+			continue
+		}
+		if pkgDiff := diffPackage(name, from.packages[name], newPkg); pkgDiff != nil {
+			report.Packages = append(report.Packages, *pkgDiff)
+		}
+	}
+
+	report.summarize()
+	return &report
+}
+
+// diffPackage returns nil when nothing has changed in the package
+func diffPackage(name string, old, new *packageState) *PackageDiff {
+	pkgDiff := PackageDiff{Name: name}
+	changed := false
+
+	if old.defaultChannel != new.defaultChannel {
+		pkgDiff.DefaultChannelChange = &Change{From: old.defaultChannel, To: new.defaultChannel}
+		changed = true
+	}
+
+	for _, bundleName := range pkg.SortedKeys(old.bundles) {
+		if _, ok := new.bundles[bundleName]; !ok {
+			pkgDiff.BundlesRemoved = append(pkgDiff.BundlesRemoved, bundleName)
+			changed = true
+		}
+	}
+
+	for _, bundleName := range pkg.SortedKeys(new.bundles) {
+		newBundle := new.bundles[bundleName]
+		oldBundle, found := old.bundles[bundleName]
+		if !found {
+			pkgDiff.BundlesAdded = append(pkgDiff.BundlesAdded, bundleName)
+			// the findings of the bundles added are all new
+			oldBundle = &bundleState{}
+			changed = true
+		}
+
+		if newBundle.isHeadOfChannel && !oldBundle.isHeadOfChannel {
+			pkgDiff.NewHeads = append(pkgDiff.NewHeads, bundleName)
+			changed = true
+		}
+		if newBundle.isDeprecated && !oldBundle.isDeprecated {
+			pkgDiff.NewlyDeprecated = append(pkgDiff.NewlyDeprecated, bundleName)
+			changed = true
+		}
+		if found && oldBundle.maxOCPVersion != newBundle.maxOCPVersion {
+			pkgDiff.MaxOCPVersionChanges = append(pkgDiff.MaxOCPVersionChanges, BundleChange{Bundle: bundleName,
+				Change: Change{From: oldBundle.maxOCPVersion, To: newBundle.maxOCPVersion}})
+			changed = true
+		}
+
+		for _, f := range []struct {
+			kind     string
+			old, new []string
+		}{
+			{ValidatorError, oldBundle.validatorErrors, newBundle.validatorErrors},
+			{ValidatorWarning, oldBundle.validatorWarnings, newBundle.validatorWarnings},
+			{ScorecardFailing, oldBundle.scorecardFailing, newBundle.scorecardFailing},
+		} {
+			findings := FindingsDiff{Bundle: bundleName, Kind: f.kind, New: subtract(f.new, f.old),
+				Resolved: subtract(f.old, f.new)}
+			if len(findings.New) > 0 || len(findings.Resolved) > 0 {
+				pkgDiff.Findings = append(pkgDiff.Findings, findings)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return &pkgDiff
+}
+
+// subtract returns the values of a which are not in b
+func subtract(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, v := range b {
+		inB[v] = true
+	}
+	var result []string
+	for _, v := range a {
+		if !inB[v] {
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (r *Report) summarize() {
+	r.Summary.PackagesAdded = len(r.PackagesAdded)
+	r.Summary.PackagesRemoved = len(r.PackagesRemoved)
+	r.Summary.PackagesChanged = len(r.Packages)
+	for _, p := range r.Packages {
+		r.Summary.BundlesAdded += len(p.BundlesAdded)
+		r.Summary.BundlesRemoved += len(p.BundlesRemoved)
+		r.Summary.NewHeads += len(p.NewHeads)
+		r.Summary.NewlyDeprecated += len(p.NewlyDeprecated)
+		if p.DefaultChannelChange != nil {
+			r.Summary.DefaultChannelChanges++
+		}
+		for _, f := range p.Findings {
+			r.Summary.NewFindings += len(f.New)
+			r.Summary.ResolvedFindings += len(f.Resolved)
+		}
+	}
+}
+
+// WriteJSON writes the report in the JSON format in the output path informed
+func (r *Report) WriteJSON(outputPath string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	const reportType = "diff"
+	return pkg.WriteJSON(data, r.To.Image, outputPath, reportType)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const fromReport = `{"schemaVersion":"v2","columns":[
+{"packageName":"foo","defaultChannel":"alpha","bundleImagePath":"foo:v0.1.0","isHeadOfChannel":true,
+ "validatorErrors":["error a"]},
+{"packageName":"bar","bundleImagePath":"bar:v0.1.0","isHeadOfChannel":true}
+],"flags":{"image":"index:v1"},"indexImageInspect":{},"generateAt":"2021-04-22"}`
+
+const toReport = `{"schemaVersion":"v2","columns":[
+{"packageName":"foo","defaultChannel":"stable","bundleImagePath":"foo:v0.1.0","isDeprecated":true,
+ "maxOCPVersion":"4.8","validatorErrors":["error b"]},
+{"packageName":"foo","defaultChannel":"stable","bundleImagePath":"foo:v0.2.0","isHeadOfChannel":true},
+{"packageName":"baz","bundleImagePath":"baz:v0.1.0","isHeadOfChannel":true}
+],"flags":{"image":"index:v2"},"indexImageInspect":{},"generateAt":"2021-04-29"}`
+
+func TestNewReport(t *testing.T) {
+	from, err := LoadSnapshot(strings.NewReader(fromReport))
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	to, err := LoadSnapshot(strings.NewReader(toReport))
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	report := NewReport(from, to)
+	if report.From.Image != "index:v1" || report.To.Image != "index:v2" {
+		t.Errorf("unexpected images compared: %+v %+v", report.From, report.To)
+	}
+	if !reflect.DeepEqual(report.PackagesAdded, []string{"baz"}) ||
+		!reflect.DeepEqual(report.PackagesRemoved, []string{"bar"}) {
+		t.Errorf("unexpected packages added %v and removed %v", report.PackagesAdded, report.PackagesRemoved)
+	}
+
+	want := []PackageDiff{{
+		Name:                 "foo",
+		BundlesAdded:         []string{"foo:v0.2.0"},
+		NewHeads:             []string{"foo:v0.2.0"},
+		DefaultChannelChange: &Change{From: "alpha", To: "stable"},
+		MaxOCPVersionChanges: []BundleChange{{Bundle: "foo:v0.1.0", Change: Change{From: "", To: "4.8"}}},
+		NewlyDeprecated:      []string{"foo:v0.1.0"},
+		Findings: []FindingsDiff{
+			{Bundle: "foo:v0.1.0", Kind: ValidatorError, New: []string{"error b"}, Resolved: []string{"error a"}},
+		},
+	}}
+	if !reflect.DeepEqual(report.Packages, want) {
+		t.Errorf("NewReport() packages = %+v, want %+v", report.Packages, want)
+	}
+	if report.Summary.NewFindings != 1 || report.Summary.ResolvedFindings != 1 || report.Summary.BundlesAdded != 1 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+}
+
+func TestNewReportWithoutChanges(t *testing.T) {
+	from, err := LoadSnapshot(strings.NewReader(fromReport))
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	to, err := LoadSnapshot(strings.NewReader(fromReport))
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	report := NewReport(from, to)
+	if len(report.Packages) != 0 || len(report.PackagesAdded) != 0 || len(report.PackagesRemoved) != 0 {
+		t.Errorf("expected no changes, got %+v", report)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"io"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Snapshot is the data of a bundles report required to compare it with another one. It is built while the report is
+// decoded so that the CSVs embedded in the report are not kept in memory.
+type Snapshot struct {
+	Image      string
+	ImageID    string
+	GenerateAt string
+	packages   map[string]*packageState
+}
+
+type packageState struct {
+	defaultChannel string
+	bundles        map[string]*bundleState
+}
+
+type bundleState struct {
+	isHeadOfChannel   bool
+	isDeprecated      bool
+	maxOCPVersion     string
+	validatorErrors   []string
+	validatorWarnings []string
+	scorecardFailing  []string
+}
+
+// LoadSnapshot decodes the JSON bundles report and returns its snapshot
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{packages: make(map[string]*packageState)}
+	report, err := bundles.DecodeReport(r, func(col bundles.Column) error {
+		snapshot.add(col)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Image = report.Flags.IndexImage
+	snapshot.ImageID = report.IndexImageInspect.ID
+	snapshot.GenerateAt = report.GenerateAt
+	return snapshot, nil
+}
+
+// add merges the column into the snapshot. Note that the same bundle can be in the report once per channel.
+func (s *Snapshot) add(col bundles.Column) {
+	if len(col.PackageName) == 0 {
+		return
+	}
+	p, ok := s.packages[col.PackageName]
+	if !ok {
+		p = &packageState{bundles: make(map[string]*bundleState)}
+		s.packages[col.PackageName] = p
+	}
+	if len(p.defaultChannel) == 0 {
+		p.defaultChannel = col.DefaultChannel
+	}
+
+	name := col.BundleName()
+	b, ok := p.bundles[name]
+	if !ok {
+		b = &bundleState{}
+		p.bundles[name] = b
+	}
+	b.isHeadOfChannel = b.isHeadOfChannel || col.IsHeadOfChannel
+	b.isDeprecated = b.isDeprecated || col.IsDeprecated
+	if len(b.maxOCPVersion) == 0 {
+		b.maxOCPVersion = col.MaxOCPVersion
+	}
+	b.validatorErrors = pkg.GetUniqueValues(append(b.validatorErrors, col.ValidatorErrors...))
+	b.validatorWarnings = pkg.GetUniqueValues(append(b.validatorWarnings, col.ValidatorWarnings...))
+	b.scorecardFailing = pkg.GetUniqueValues(append(b.scorecardFailing, col.ScorecardFailingTests...))
+}
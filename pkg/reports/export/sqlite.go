@@ -0,0 +1,360 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// schema creates the tables where the bundles reports are exported. All data of a bundle is related to the report
+// from which it was exported so that many reports, e.g. of each version of the index, can be in the same database.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		index_image TEXT,
+		index_image_id TEXT,
+		index_version TEXT,
+		generate_at TEXT,
+		schema_version TEXT,
+		UNIQUE (index_image, index_version)
+	)`,
+	`CREATE TABLE IF NOT EXISTS packages (
+		report_id INTEGER NOT NULL REFERENCES reports(id),
+		name TEXT NOT NULL,
+		default_channel TEXT,
+		PRIMARY KEY (report_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS bundles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		report_id INTEGER NOT NULL REFERENCES reports(id),
+		package_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		version TEXT,
+		bundle_image_path TEXT,
+		max_ocp_version TEXT,
+		is_head_of_channel INTEGER NOT NULL DEFAULT 0,
+		is_deprecated INTEGER NOT NULL DEFAULT 0,
+		is_from_default_channel INTEGER NOT NULL DEFAULT 0,
+		has_custom_scorecard_tests INTEGER NOT NULL DEFAULT 0,
+		compressed_size INTEGER,
+		UNIQUE (report_id, package_name, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS channels (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		name TEXT NOT NULL,
+		PRIMARY KEY (bundle_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS findings (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		source TEXT NOT NULL,
+		severity TEXT NOT NULL,
+		message TEXT NOT NULL,
+		PRIMARY KEY (bundle_id, source, severity, message)
+	)`,
+	`CREATE TABLE IF NOT EXISTS labels (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		key TEXT NOT NULL,
+		value TEXT,
+		PRIMARY KEY (bundle_id, key)
+	)`,
+	`CREATE TABLE IF NOT EXISTS annotations (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		source TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT,
+		PRIMARY KEY (bundle_id, source, key)
+	)`,
+	`CREATE TABLE IF NOT EXISTS properties (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		type TEXT NOT NULL,
+		value TEXT,
+		PRIMARY KEY (bundle_id, type, value)
+	)`,
+	`CREATE TABLE IF NOT EXISTS related_images (
+		bundle_id INTEGER NOT NULL REFERENCES bundles(id),
+		name TEXT,
+		image TEXT NOT NULL,
+		PRIMARY KEY (bundle_id, image)
+	)`,
+}
+
+// tables which have data of the bundles, in the order that they should be deleted
+var bundleTables = []string{"channels", "findings", "labels", "annotations", "properties", "related_images"}
+
+// Sources of the findings exported
+const (
+	SourceValidator = "validator"
+	SourceScorecard = "scorecard"
+	SourceAudit     = "audit"
+)
+
+// Sources of the annotations exported
+const (
+	AnnotationsFromBundle = "bundle"
+	AnnotationsFromCSV    = "csv"
+)
+
+// Options define how the report is exported
+type Options struct {
+	// Version identifies the report in the database. (Default: the tag of the index image)
+	Version string
+	// Replace removes the data of the report with the same index image and version before exporting it
+	Replace bool
+}
+
+// CreateSchema creates the tables in the database when they do not exist
+func CreateSchema(db *sql.DB) error {
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("unable to create the tables: %s", err)
+		}
+	}
+	return nil
+}
+
+// Export decodes the bundles report and inserts its data in the database. It returns the id of the report.
+func Export(db *sql.DB, r io.Reader, options Options) (int64, error) {
+	if err := CreateSchema(db); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	w := &writer{tx: tx, packages: make(map[string]bool)}
+	report, err := bundles.DecodeReport(r, func(col bundles.Column) error {
+		// the report row is inserted when the first column is decoded and its image, version and flags are only
+		// set by updateReport, since the flags can be written after the columns (e.g. legacy or streamed reports)
+		return w.addColumn(col)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if w.reportID == 0 {
+		err = fmt.Errorf("the report has no bundles")
+		return 0, err
+	}
+	if err = w.updateReport(report, options); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return w.reportID, nil
+}
+
+type writer struct {
+	tx       *sql.Tx
+	reportID int64
+	packages map[string]bool
+}
+
+func (w *writer) addColumn(col bundles.Column) error {
+	if w.reportID == 0 {
+		// the image and the version are set by updateReport when the whole report is decoded
+		result, err := w.tx.Exec(`INSERT INTO reports DEFAULT VALUES`)
+		if err != nil {
+			return err
+		}
+		if w.reportID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	if !w.packages[col.PackageName] {
+		if _, err := w.tx.Exec(`INSERT INTO packages (report_id, name, default_channel) VALUES (?, ?, ?)`,
+			w.reportID, col.PackageName, col.DefaultChannel); err != nil {
+			return err
+		}
+		w.packages[col.PackageName] = true
+	}
+
+	bundleID, err := w.upsertBundle(col)
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range col.Channels {
+		if err := w.insert(`INSERT OR IGNORE INTO channels (bundle_id, name) VALUES (?, ?)`,
+			bundleID, channel); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range []struct {
+		source, severity string
+		messages         []string
+	}{
+		{SourceValidator, "error", col.ValidatorErrors},
+		{SourceValidator, "warning", col.ValidatorWarnings},
+		{SourceScorecard, "error", col.ScorecardErrors},
+		{SourceScorecard, "suggestion", col.ScorecardSuggestions},
+		{SourceScorecard, "failing-test", col.ScorecardFailingTests},
+		{SourceAudit, "error", col.AuditErrors},
+	} {
+		for _, message := range f.messages {
+			if err := w.insert(`INSERT OR IGNORE INTO findings (bundle_id, source, severity, message)
+				VALUES (?, ?, ?, ?)`, bundleID, f.source, f.severity, message); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, value := range col.BundleImageLabels {
+		if err := w.insert(`INSERT OR IGNORE INTO labels (bundle_id, key, value) VALUES (?, ?, ?)`,
+			bundleID, key, value); err != nil {
+			return err
+		}
+	}
+
+	annotations := map[string]map[string]string{AnnotationsFromBundle: col.BundleAnnotations}
+	if col.BundleCSV != nil {
+		annotations[AnnotationsFromCSV] = col.BundleCSV.Annotations
+	}
+	for source, values := range annotations {
+		for key, value := range values {
+			if err := w.insert(`INSERT OR IGNORE INTO annotations (bundle_id, source, key, value)
+				VALUES (?, ?, ?, ?)`, bundleID, source, key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range col.PropertiesFromDB {
+		if err := w.insert(`INSERT OR IGNORE INTO properties (bundle_id, type, value) VALUES (?, ?, ?)`,
+			bundleID, p.Type, p.Value); err != nil {
+			return err
+		}
+	}
+
+	if col.BundleCSV != nil {
+		for _, related := range col.BundleCSV.Spec.RelatedImages {
+			if err := w.insert(`INSERT OR IGNORE INTO related_images (bundle_id, name, image) VALUES (?, ?, ?)`,
+				bundleID, related.Name, related.Image); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// upsertBundle inserts the bundle or merges the column with the bundle already inserted, since the same bundle
+// can be in the report once per channel
+func (w *writer) upsertBundle(col bundles.Column) (int64, error) {
+	var version string
+	if col.BundleCSV != nil {
+		version = col.BundleCSV.Spec.Version.String()
+	}
+	var compressedSize *int64
+	if col.BundleSize != nil {
+		compressedSize = &col.BundleSize.CompressedSize
+	}
+
+	if _, err := w.tx.Exec(`INSERT INTO bundles (report_id, package_name, name, version, bundle_image_path,
+			max_ocp_version, is_head_of_channel, is_deprecated, is_from_default_channel, has_custom_scorecard_tests,
+			compressed_size)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (report_id, package_name, name) DO UPDATE SET
+			is_head_of_channel = max(is_head_of_channel, excluded.is_head_of_channel),
+			is_deprecated = max(is_deprecated, excluded.is_deprecated),
+			is_from_default_channel = max(is_from_default_channel, excluded.is_from_default_channel)`,
+		w.reportID, col.PackageName, col.BundleName(), version, col.BundleImagePath, col.MaxOCPVersion,
+		col.IsHeadOfChannel, col.IsDeprecated, col.IsFromDefaultChannel, col.HasCustomScorecardTests,
+		compressedSize); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := w.tx.QueryRow(`SELECT id FROM bundles WHERE report_id = ? AND package_name = ? AND name = ?`,
+		w.reportID, col.PackageName, col.BundleName()).Scan(&id)
+	return id, err
+}
+
+func (w *writer) insert(stmt string, args ...interface{}) error {
+	_, err := w.tx.Exec(stmt, args...)
+	return err
+}
+
+// updateReport sets the data of the report, which is only known when the whole report is decoded
+func (w *writer) updateReport(report bundles.Report, options Options) error {
+	version := options.Version
+	if len(version) == 0 {
+		version = versionFromImage(report.Flags.IndexImage)
+	}
+
+	var existing int64
+	err := w.tx.QueryRow(`SELECT id FROM reports WHERE index_image = ? AND index_version = ?`,
+		report.Flags.IndexImage, version).Scan(&existing)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return err
+	case !options.Replace:
+		return fmt.Errorf("the database already has a report of the index %s with the version %s. "+
+			"Inform another version or replace it", report.Flags.IndexImage, version)
+	default:
+		if err := w.deleteReport(existing); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.tx.Exec(`UPDATE reports SET index_image = ?, index_image_id = ?, index_version = ?, generate_at = ?,
+		schema_version = ? WHERE id = ?`, report.Flags.IndexImage, report.IndexImageInspect.ID, version,
+		report.GenerateAt, report.SchemaVersion, w.reportID)
+	return err
+}
+
+func (w *writer) deleteReport(id int64) error {
+	for _, table := range bundleTables {
+		if _, err := w.tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE bundle_id IN
+			(SELECT id FROM bundles WHERE report_id = ?)`, table), id); err != nil {
+			return err
+		}
+	}
+	for _, table := range []string{"bundles", "packages", "reports"} {
+		column := "report_id"
+		if table == "reports" {
+			column = "id"
+		}
+		if _, err := w.tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s = ?`, table, column), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionFromImage returns the tag of the image, e.g. v4.9 for registry.redhat.io/redhat/redhat-operator-index:v4.9
+func versionFromImage(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(name, "@"); i >= 0 {
+		return name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
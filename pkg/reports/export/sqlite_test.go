@@ -0,0 +1,92 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const report = `{"schemaVersion":"v2","columns":[
+{"packageName":"foo","defaultChannel":"stable","bundleImagePath":"foo:v0.1.0","bundleChannel":["alpha"],
+ "validatorErrors":["error a"],"bundleImageLabels":{"l":"v"},"bundleAnnotations":{"a":"b"},
+ "propertiesFromDB":[{"type":"olm.maxOpenShiftVersion","value":"4.8"}]},
+{"packageName":"foo","defaultChannel":"stable","bundleImagePath":"foo:v0.1.0","bundleChannel":["stable"],
+ "isHeadOfChannel":true,"validatorErrors":["error a"]},
+{"packageName":"bar","bundleImagePath":"bar:v1.0.0","isHeadOfChannel":true,"scorecardFailingTests":["basic"]}
+],"flags":{"image":"quay.io/example/index:v4.9"},"indexImageInspect":{"ID":"sha256:123"},"generateAt":"2021-04-22"}`
+
+func TestExport(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	id, err := Export(db, strings.NewReader(report), Options{})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var image, version string
+	if err := db.QueryRow(`SELECT index_image, index_version FROM reports WHERE id = ?`, id).
+		Scan(&image, &version); err != nil {
+		t.Fatal(err)
+	}
+	if image != "quay.io/example/index:v4.9" || version != "v4.9" {
+		t.Errorf("unexpected report exported: image=%s version=%s", image, version)
+	}
+
+	counts := map[string]int{"packages": 2, "bundles": 2, "channels": 2, "findings": 2, "labels": 1,
+		"annotations": 1, "properties": 1, "related_images": 0}
+	for table, want := range counts {
+		var got int
+		if err := db.QueryRow(`SELECT count(*) FROM ` + table).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("table %s has %d rows, want %d", table, got, want)
+		}
+	}
+
+	var isHead bool
+	if err := db.QueryRow(`SELECT is_head_of_channel FROM bundles WHERE name = 'foo:v0.1.0'`).
+		Scan(&isHead); err != nil || !isHead {
+		t.Errorf("the bundle in more than one channel was not merged: head=%v err=%v", isHead, err)
+	}
+
+	if _, err := Export(db, strings.NewReader(report), Options{}); err == nil {
+		t.Errorf("Export() expected an error when the report was already exported")
+	}
+	if _, err := Export(db, strings.NewReader(report), Options{Version: "v4.9-rc"}); err != nil {
+		t.Errorf("Export() with another version error = %v", err)
+	}
+	if _, err := Export(db, strings.NewReader(report), Options{Replace: true}); err != nil {
+		t.Errorf("Export() with replace error = %v", err)
+	}
+
+	var reports, bundles int
+	if err := db.QueryRow(`SELECT (SELECT count(*) FROM reports), (SELECT count(*) FROM bundles)`).
+		Scan(&reports, &bundles); err != nil {
+		t.Fatal(err)
+	}
+	if reports != 2 || bundles != 4 {
+		t.Errorf("unexpected data after the reports were appended: reports=%d bundles=%d", reports, bundles)
+	}
+}
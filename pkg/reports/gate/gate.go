@@ -0,0 +1,277 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gate checks the bundles of a report against the thresholds informed via --fail-on so that the
+// pipelines can block on the results of the audit.
+package gate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+	"github.com/operator-framework/audit/pkg/reports/custom"
+)
+
+// Checks which can be informed via --fail-on
+const (
+	// ValidatorErrors fails when the validators return errors for the bundle
+	ValidatorErrors = "validator-errors"
+	// ValidatorWarnings fails when the validators return warnings for the bundle
+	ValidatorWarnings = "validator-warnings"
+	// ScorecardFailures fails when scorecard tests are failing for the bundle
+	ScorecardFailures = "scorecard-failures"
+	// DeprecatedAPIs fails when the bundle uses APIs removed in the k8s version informed (by default 1.22)
+	DeprecatedAPIs = "deprecated-apis"
+	// MultiarchErrors fails when the multiarch checks return errors for the head of the default channel
+	MultiarchErrors = "multiarch-errors"
+)
+
+// maxBundlesInSummary is the number of bundles failing a check which are listed in the summary
+const maxBundlesInSummary = 5
+
+// Checks returns the checks which can be informed via --fail-on
+func Checks() []string {
+	return []string{ValidatorErrors, ValidatorWarnings, ScorecardFailures, DeprecatedAPIs, MultiarchErrors}
+}
+
+// Threshold defines a check informed via --fail-on and how many bundles can fail it. Only the heads of the
+// channels which are not deprecated are checked since they are the bundles which can be installed.
+type Threshold struct {
+	Check string
+	// Max is the number of bundles which can fail the check without exceeding the threshold
+	Max int
+	// K8sVersion is the version used to check the APIs removed for the check deprecated-apis
+	K8sVersion string
+}
+
+func (t Threshold) String() string {
+	switch {
+	case t.Check == DeprecatedAPIs:
+		return fmt.Sprintf("%s=%s", t.Check, t.K8sVersion)
+	case t.Max > 0:
+		return fmt.Sprintf("%s=%d", t.Check, t.Max)
+	default:
+		return t.Check
+	}
+}
+
+// ParseThresholds parses the values informed via --fail-on. The values are <check> or <check>=<max> where max
+// is the number of bundles which can fail the check. For the check deprecated-apis the value informed is the
+// k8s version, e.g. deprecated-apis=1.25.
+func ParseThresholds(values []string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, v := range values {
+		check, value, hasValue := strings.Cut(strings.TrimSpace(v), "=")
+		if !contains(Checks(), check) {
+			return nil, fmt.Errorf("invalid value informed via the --fail-on flag :%v. "+
+				"The available checks are: %s", v, strings.Join(Checks(), ", "))
+		}
+
+		threshold := Threshold{Check: check}
+		switch {
+		case check == DeprecatedAPIs:
+			threshold.K8sVersion = custom.DefaultK8sVersion
+			if hasValue {
+				version, err := custom.NormalizeK8sVersion(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value informed via the --fail-on flag :%v. %s", v, err)
+				}
+				threshold.K8sVersion = version
+			}
+		case !hasValue:
+		default:
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("invalid value informed via the --fail-on flag :%v. "+
+					"The maximum number of bundles failing the check should be a non-negative number", v)
+			}
+			threshold.Max = limit
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// Evaluator checks the bundles added against the thresholds. The bundles are checked as they are added so that
+// it can be used with reports which are streamed.
+type Evaluator struct {
+	thresholds      []Threshold
+	containerEngine string
+	failing         [][]string
+	bundles         int
+	heads           int
+	multiarch       bool
+	// multiarchHeads are the heads of the default channels kept to run the multiarch checks in the end
+	multiarchHeads []bundles.Column
+}
+
+// NewEvaluator returns an Evaluator for the thresholds. The container engine is used by the multiarch checks
+// to inspect the images.
+func NewEvaluator(thresholds []Threshold, containerEngine string) *Evaluator {
+	e := &Evaluator{
+		thresholds:      thresholds,
+		containerEngine: containerEngine,
+		failing:         make([][]string, len(thresholds)),
+	}
+	for _, t := range thresholds {
+		e.multiarch = e.multiarch || t.Check == MultiarchErrors
+	}
+	return e
+}
+
+// Add checks the bundle against the thresholds. Its signature allows to use it as the callback of
+// bundles.DecodeReport.
+func (e *Evaluator) Add(col bundles.Column) error {
+	e.bundles++
+	if !col.IsHeadOfChannel || col.IsDeprecated {
+		return nil
+	}
+	e.heads++
+	if e.multiarch && col.IsFromDefaultChannel && col.BundleCSV != nil && len(col.PackageName) > 0 {
+		e.multiarchHeads = append(e.multiarchHeads, col)
+	}
+
+	for i, t := range e.thresholds {
+		failing := false
+		switch t.Check {
+		case ValidatorErrors:
+			failing = len(col.ValidatorErrors) > 0
+		case ValidatorWarnings:
+			failing = len(col.ValidatorWarnings) > 0
+		case ScorecardFailures:
+			failing = len(col.ScorecardFailingTests) > 0 || len(col.ScorecardErrors) > 0
+		case DeprecatedAPIs:
+			bd := custom.BundleDeprecate{BundleData: col}
+			bd.AddDeprecateDataFromValidators()
+			bd.AddRemovedAPIsFromBundle()
+			failing = len(bd.RemovedAPIsFor(t.K8sVersion)) > 0
+		}
+		if failing {
+			e.failing[i] = append(e.failing[i], col.BundleName())
+		}
+	}
+	return nil
+}
+
+// Result returns the result of the checks of all bundles added
+func (e *Evaluator) Result(image string) Result {
+	result := Result{Image: image, Bundles: e.bundles, Heads: e.heads}
+	var multiarchFailing []string
+	if e.multiarch {
+		multiarchFailing = e.multiarchFailing()
+	}
+	for i, t := range e.thresholds {
+		failing := e.failing[i]
+		if t.Check == MultiarchErrors {
+			failing = multiarchFailing
+		}
+		sort.Strings(failing)
+		result.Checks = append(result.Checks, CheckResult{Threshold: t, Bundles: failing})
+	}
+	return result
+}
+
+func (e *Evaluator) multiarchFailing() []string {
+	if len(e.multiarchHeads) == 0 {
+		return nil
+	}
+	var failing []string
+	report := custom.NewMultipleArchitecturesReport(bundles.Report{Columns: e.multiarchHeads}, "",
+		e.containerEngine, custom.MultiarchScopeDefaultHead)
+	for _, packages := range [][]custom.MultipleArchitecturesPackageReport{report.Supported,
+		report.SupportedWithWarnings, report.SupportedWithErrors, report.Unsupported} {
+		for _, p := range packages {
+			for _, b := range p.Bundles {
+				if len(b.Errors) > 0 {
+					failing = append(failing, b.BundleData.BundleName())
+				}
+			}
+		}
+	}
+	return failing
+}
+
+// CheckResult defines the bundles which are failing the check of the threshold
+type CheckResult struct {
+	Threshold Threshold
+	Bundles   []string
+}
+
+// Exceeded returns true when more bundles than the threshold allows are failing the check
+func (c CheckResult) Exceeded() bool {
+	return len(c.Bundles) > c.Threshold.Max
+}
+
+// Result defines the result of the checks for all thresholds
+type Result struct {
+	Image   string
+	Bundles int
+	Heads   int
+	Checks  []CheckResult
+}
+
+// Err returns a pkg.ExitError with the code pkg.ExitThresholdExceeded when a threshold is exceeded
+func (r Result) Err() error {
+	var exceeded []string
+	for _, c := range r.Checks {
+		if c.Exceeded() {
+			exceeded = append(exceeded, c.Threshold.String())
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return &pkg.ExitError{Code: pkg.ExitThresholdExceeded,
+		Err: fmt.Errorf("thresholds exceeded: %s", strings.Join(exceeded, ", "))}
+}
+
+// WriteSummary writes a short summary of the checks which can be output on stdout
+func (r Result) WriteSummary(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Audit of %s: %d bundles, %d heads of channels checked\n",
+		r.Image, r.Bundles, r.Heads); err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CHECK\tRESULT\tFAILING\tMAX\tBUNDLES")
+	for _, c := range r.Checks {
+		status := "PASS"
+		if c.Exceeded() {
+			status = "FAIL"
+		}
+		names := c.Bundles
+		if len(names) > maxBundlesInSummary {
+			names = append(append([]string{}, names[:maxBundlesInSummary]...),
+				fmt.Sprintf("and %d more", len(c.Bundles)-maxBundlesInSummary))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\n", c.Threshold, status, len(c.Bundles), c.Threshold.Max,
+			strings.Join(names, ", "))
+	}
+	return writer.Flush()
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
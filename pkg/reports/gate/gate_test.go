@@ -0,0 +1,105 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds([]string{"validator-errors", "scorecard-failures=2", "deprecated-apis=1.25"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Threshold{{Check: ValidatorErrors}, {Check: ScorecardFailures, Max: 2},
+		{Check: DeprecatedAPIs, K8sVersion: "1.25"}}
+	if len(thresholds) != len(want) {
+		t.Fatalf("got %v, want %v", thresholds, want)
+	}
+	for i := range want {
+		if thresholds[i] != want[i] {
+			t.Errorf("got %v, want %v", thresholds[i], want[i])
+		}
+	}
+
+	for _, invalid := range []string{"unknown", "validator-errors=-1", "validator-errors=a", "deprecated-apis=abc"} {
+		if _, err := ParseThresholds([]string{invalid}); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	thresholds, err := ParseThresholds([]string{"validator-errors", "validator-warnings=1", "deprecated-apis"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluator := NewEvaluator(thresholds, pkg.Docker)
+	columns := []bundles.Column{
+		{PackageName: "foo", BundleImagePath: "foo:v0.1.0", ValidatorErrors: []string{"invalid"}},
+		{PackageName: "foo", BundleImagePath: "foo:v0.2.0", IsHeadOfChannel: true,
+			ValidatorWarnings: []string{"this bundle is using APIs which were deprecated and removed in v1.22"}},
+		{PackageName: "bar", BundleImagePath: "bar:v1.0.0", IsHeadOfChannel: true,
+			ValidatorErrors: []string{"invalid"}, ValidatorWarnings: []string{"missing icon"}},
+		{PackageName: "baz", BundleImagePath: "baz:v1.0.0", IsHeadOfChannel: true, IsDeprecated: true,
+			ValidatorErrors: []string{"invalid"}},
+	}
+	for _, col := range columns {
+		if err := evaluator.Add(col); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := evaluator.Result("quay.io/my/index:v4.15")
+	if result.Bundles != 4 || result.Heads != 2 {
+		t.Errorf("got %d bundles and %d heads, want 4 and 2", result.Bundles, result.Heads)
+	}
+	wantFailing := [][]string{{"bar:v1.0.0"}, {"bar:v1.0.0", "foo:v0.2.0"}, {"foo:v0.2.0"}}
+	for i, c := range result.Checks {
+		if strings.Join(c.Bundles, ",") != strings.Join(wantFailing[i], ",") {
+			t.Errorf("%s: got %v, want %v", c.Threshold, c.Bundles, wantFailing[i])
+		}
+		if !c.Exceeded() {
+			t.Errorf("%s: expected the threshold to be exceeded", c.Threshold)
+		}
+	}
+
+	if code := pkg.ExitCode(result.Err()); code != pkg.ExitThresholdExceeded {
+		t.Errorf("got exit code %d, want %d", code, pkg.ExitThresholdExceeded)
+	}
+
+	var out bytes.Buffer
+	if err := result.WriteSummary(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"quay.io/my/index:v4.15: 4 bundles, 2 heads", "validator-warnings=1  FAIL    2"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("summary does not contain %q:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestResultErr(t *testing.T) {
+	result := Result{Checks: []CheckResult{{Threshold: Threshold{Check: ValidatorErrors, Max: 1},
+		Bundles: []string{"foo.v0.1.0"}}}}
+	if err := result.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
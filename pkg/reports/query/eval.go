@@ -0,0 +1,342 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// aliases maps the short names which can be used in the expressions to the JSON keys of the columns
+var aliases = map[string]string{
+	"package":            "packageName",
+	"bundle":             "bundleName",
+	"bundleImage":        "bundleImagePath",
+	"channel":            "bundleChannel",
+	"channels":           "bundleChannel",
+	"head":               "isHeadOfChannel",
+	"deprecated":         "isDeprecated",
+	"fromDefaultChannel": "isFromDefaultChannel",
+	"labels":             "bundleImageLabels",
+	"annotations":        "bundleAnnotations",
+	"properties":         "propertiesFromDB",
+	"auditErrors":        "errors",
+}
+
+// Document is the column of the bundles report as a generic JSON object
+type Document map[string]interface{}
+
+// NewDocument returns the column informed as a Document. The name of the bundle is added in the key bundleName.
+func NewDocument(col bundles.Column) (Document, error) {
+	data, err := json.Marshal(col)
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc["bundleName"] = col.BundleName()
+	return doc, nil
+}
+
+// Path is the list of keys used to get a value of the Document
+type Path []string
+
+func (p Path) String() string {
+	return strings.Join(p, ".")
+}
+
+// Lookup returns the value of the path in the Document. When the path goes through a list the values found in each
+// item are returned in a list.
+func (p Path) Lookup(doc Document) interface{} {
+	if len(p) == 0 {
+		return nil
+	}
+	root := p[0]
+	if key, ok := aliases[root]; ok {
+		root = key
+	}
+	return lookup(doc[root], p[1:])
+}
+
+func lookup(value interface{}, path []string) interface{} {
+	if len(path) == 0 || value == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return lookup(v[path[0]], path[1:])
+	case []interface{}:
+		if i, err := strconv.Atoi(path[0]); err == nil {
+			if i < 0 || i >= len(v) {
+				return nil
+			}
+			return lookup(v[i], path[1:])
+		}
+		var result []interface{}
+		for _, item := range v {
+			found := lookup(item, path)
+			if list, ok := found.([]interface{}); ok {
+				result = append(result, list...)
+			} else if found != nil {
+				result = append(result, found)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// Match returns true when the expression is true for the Document
+func Match(expr Expr, doc Document) (bool, error) {
+	value, err := expr.eval(doc)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+func (l literal) eval(doc Document) (interface{}, error) {
+	return l.value, nil
+}
+
+func (f field) eval(doc Document) (interface{}, error) {
+	return f.path.Lookup(doc), nil
+}
+
+func (u unary) eval(doc Document) (interface{}, error) {
+	value, err := u.expr.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(value), nil
+}
+
+func (b binary) eval(doc Document) (interface{}, error) {
+	left, err := b.left.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := b.right.eval(doc)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := b.right.eval(doc)
+		return truthy(right), err
+	}
+
+	right, err := b.right.eval(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch b.op {
+	case "contains":
+		return contains(left, right), nil
+	case "!=":
+		// not equal means that none of the values is equal
+		result, err := anyMatch(left, right, "==")
+		return !result, err
+	}
+	return anyMatch(left, right, b.op)
+}
+
+// anyMatch returns true when the comparison is true for the value or, if it is a list, for any of its items
+func anyMatch(left, right interface{}, op string) (bool, error) {
+	list, ok := left.([]interface{})
+	if !ok {
+		return compare(left, right, op)
+	}
+	for _, item := range list {
+		result, err := compare(item, right, op)
+		if err != nil {
+			return false, err
+		}
+		if result {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func compare(left, right interface{}, op string) (bool, error) {
+	switch op {
+	case "==":
+		return equal(left, right), nil
+	case "=~":
+		pattern, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("the operator =~ requires a string with the regular expression")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		return left != nil && re.MatchString(toString(left)), nil
+	case "<", "<=", ">", ">=":
+		if left == nil || right == nil {
+			return false, nil
+		}
+		result := order(left, right)
+		switch op {
+		case "<":
+			return result < 0, nil
+		case "<=":
+			return result <= 0, nil
+		case ">":
+			return result > 0, nil
+		default:
+			return result >= 0, nil
+		}
+	}
+	return false, fmt.Errorf("unknown operator %s", op)
+}
+
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		// a field which is not in the report is equal to an empty value
+		return !truthy(left) && !truthy(right) && !isBool(left) && !isBool(right)
+	}
+	if l, ok := toNumber(left); ok {
+		if r, ok := toNumber(right); ok {
+			return l == r
+		}
+	}
+	return toString(left) == toString(right)
+}
+
+// order compares the values as numbers, versions (e.g. 4.8 and 4.10) or strings
+func order(left, right interface{}) int {
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if lok && rok && !strings.Contains(toString(left), ".") && !strings.Contains(toString(right), ".") {
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+		return 0
+	}
+	lv, lerr := semver.ParseTolerant(toString(left))
+	rv, rerr := semver.ParseTolerant(toString(right))
+	if lerr == nil && rerr == nil {
+		return lv.Compare(rv)
+	}
+	if lok && rok {
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(toString(left), toString(right))
+}
+
+func contains(left, right interface{}) bool {
+	switch v := left.(type) {
+	case string:
+		return strings.Contains(v, toString(right))
+	case map[string]interface{}:
+		_, ok := v[toString(right)]
+		return ok
+	case []interface{}:
+		for _, item := range v {
+			if equal(item, right) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return len(v) > 0
+	case float64:
+		return v != 0
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		for _, item := range v {
+			if truthy(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isBool(value interface{}) bool {
+	_, ok := value.(bool)
+	return ok
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, toString(item))
+		}
+		return strings.Join(values, ", ")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
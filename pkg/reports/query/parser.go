@@ -0,0 +1,383 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenDot
+	tokenLBracket
+	tokenRBracket
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// operators sorted so that the longest ones are matched first
+var operators = []string{"==", "!=", "=~", "<=", ">=", "&&", "||", "<", ">", "!"}
+
+// keywords which are operators when written as identifiers
+var keywords = map[string]string{
+	"and":      "&&",
+	"or":       "||",
+	"not":      "!",
+	"contains": "contains",
+	"matches":  "=~",
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, value: ".", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, value: "[", pos: i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, value: "]", pos: i})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			value, end, err := readString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i = end
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(input) && unicode.IsDigit(rune(input[i+1]))):
+			end := i + 1
+			for end < len(input) && (unicode.IsDigit(rune(input[end])) || input[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: input[i:end], pos: i})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := readWord(input, i)
+			word := input[i:end]
+			if op, ok := keywords[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{kind: tokenOperator, value: op, pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, value: word, pos: i})
+			}
+			i = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, value: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// readString returns the string quoted which starts in the position informed and the position after its end
+func readString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var sb strings.Builder
+	for end := start + 1; end < len(input); end++ {
+		switch input[end] {
+		case quote:
+			return sb.String(), end + 1, nil
+		case '\\':
+			if end+1 < len(input) {
+				end++
+			}
+		}
+		sb.WriteByte(input[end])
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+// readWord returns the position after the end of the identifier which starts in the position informed
+func readWord(input string, start int) int {
+	end := start + 1
+	for end < len(input) {
+		c := rune(input[end])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// Expr is a parsed expression which can be evaluated against the columns of the bundles report
+type Expr interface {
+	eval(doc Document) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+type field struct {
+	path Path
+}
+
+type unary struct {
+	op   string
+	expr Expr
+}
+
+type binary struct {
+	op          string
+	left, right Expr
+}
+
+// Parse returns the expression informed parsed. The expressions are built with:
+//
+// - fields of the columns, e.g. package, head, csv.spec.install.spec.deployments, annotations["key"]
+// - literals, e.g. "string", 'string', 4.8, true, false, null
+// - comparisons: ==, !=, <, <=, >, >=, =~ (or matches) and contains
+// - logical operators: and (&&), or (||), not (!) and parentheses
+//
+// When a field has a list of values, e.g. the deployments of the CSV, the comparison is true when it is true for
+// any of the values.
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().value, p.peek().pos)
+	}
+	return expr, nil
+}
+
+// ParsePath returns the path of the field informed, e.g. csv.metadata.name or annotations["key"]
+func ParsePath(input string) (Path, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().value, p.peek().pos)
+	}
+	return path, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(values ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, v := range values {
+		if t.value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.isOperator("!") {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: "!", expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("==", "!=", "=~", "<", "<=", ">", ">=", "contains") {
+		op := p.next().value
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, fmt.Errorf("missing ) for the ( at position %d", t.pos)
+		}
+		return expr, nil
+	case tokenString:
+		p.next()
+		return literal{value: t.value}, nil
+	case tokenNumber:
+		p.next()
+		n, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			// values such as 4.8.1 are kept as strings and compared as versions
+			return literal{value: t.value}, nil
+		}
+		return literal{value: n}, nil
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			p.next()
+			return literal{value: true}, nil
+		case "false":
+			p.next()
+			return literal{value: false}, nil
+		case "null":
+			p.next()
+			return literal{value: nil}, nil
+		}
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return field{path: path}, nil
+	}
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+func (p *parser) parsePath() (Path, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a field name at position %d", t.pos)
+	}
+	path := Path{t.value}
+	for {
+		switch p.peek().kind {
+		case tokenDot:
+			p.next()
+			t = p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field name at position %d", t.pos)
+			}
+			path = append(path, t.value)
+		case tokenLBracket:
+			p.next()
+			t = p.next()
+			if t.kind != tokenString && t.kind != tokenNumber {
+				return nil, fmt.Errorf("expected a key or an index at position %d", t.pos)
+			}
+			path = append(path, t.value)
+			if p.next().kind != tokenRBracket {
+				return nil, fmt.Errorf("missing ] at position %d", t.pos)
+			}
+		default:
+			return path, nil
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/operator-framework/audit/pkg"
+	"github.com/operator-framework/audit/pkg/reports/bundles"
+)
+
+// Output formats supported by the query results
+const (
+	Table = "table"
+	CSV   = "csv"
+)
+
+// DefaultFields are the fields selected when none is informed
+var DefaultFields = []string{"package", "bundle", "channels", "defaultChannel", "head", "maxOCPVersion"}
+
+// OutputFormats returns the output formats supported
+func OutputFormats() []string {
+	return []string{Table, CSV, pkg.JSON}
+}
+
+// Query selects the fields of the columns of the bundles report which match its expression
+type Query struct {
+	where  Expr
+	fields []string
+	paths  []Path
+}
+
+// New returns the Query for the expression and the fields informed. All columns match an empty expression.
+func New(where string, fields []string) (*Query, error) {
+	q := &Query{}
+	if len(strings.TrimSpace(where)) > 0 {
+		expr, err := Parse(where)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %s", where, err)
+		}
+		q.where = expr
+	}
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	for _, f := range fields {
+		path, err := ParsePath(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %s", f, err)
+		}
+		q.fields = append(q.fields, f)
+		q.paths = append(q.paths, path)
+	}
+	return q, nil
+}
+
+// Result has the values of the fields selected for each column which matches the query
+type Result struct {
+	Fields []string
+	Rows   [][]interface{}
+}
+
+// Run decodes the bundles report and returns the columns which match the query
+func (q *Query) Run(r io.Reader) (*Result, error) {
+	result := &Result{Fields: q.fields}
+	_, err := bundles.DecodeReport(r, func(col bundles.Column) error {
+		doc, err := NewDocument(col)
+		if err != nil {
+			return err
+		}
+		if q.where != nil {
+			matched, err := Match(q.where, doc)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return nil
+			}
+		}
+		row := make([]interface{}, 0, len(q.paths))
+		for _, path := range q.paths {
+			row = append(row, path.Lookup(doc))
+		}
+		result.Rows = append(result.Rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Write writes the result in the format informed
+func (r *Result) Write(w io.Writer, format string) error {
+	switch format {
+	case pkg.JSON:
+		return r.writeJSON(w)
+	case CSV:
+		return r.writeCSV(w)
+	case Table:
+		return r.writeTable(w)
+	}
+	return fmt.Errorf("unsupported output format %s. The available options are: %s", format,
+		strings.Join(OutputFormats(), ", "))
+}
+
+func (r *Result) writeJSON(w io.Writer) error {
+	items := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		item := make(map[string]interface{}, len(r.Fields))
+		for i, f := range r.Fields {
+			item[f] = row[i]
+		}
+		items = append(items, item)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(items)
+}
+
+func (r *Result) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(r.Fields); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		if err := writer.Write(stringValues(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *Result) writeTable(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, 0, len(r.Fields))
+	for _, f := range r.Fields {
+		header = append(header, strings.ToUpper(f))
+	}
+	if _, err := fmt.Fprintln(writer, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		if _, err := fmt.Fprintln(writer, strings.Join(stringValues(row), "\t")); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func stringValues(row []interface{}) []string {
+	values := make([]string, 0, len(row))
+	for _, v := range row {
+		values = append(values, toString(v))
+	}
+	return values
+}
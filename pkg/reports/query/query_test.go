@@ -0,0 +1,105 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const report = `{"schemaVersion":"v2","columns":[
+{"packageName":"foo","bundleImagePath":"foo:v0.1.0","bundleChannel":["alpha"],"maxOCPVersion":"4.8",
+ "bundleAnnotations":{"operators.openshift.io/infrastructure-features":"[\"disconnected\"]"}},
+{"packageName":"foo","bundleImagePath":"foo:v0.2.0","bundleChannel":["alpha","stable"],"isHeadOfChannel":true,
+ "isFromDefaultChannel":true,"csv":{"metadata":{"name":"foo.v0.2.0"},"spec":{"install":{"strategy":"deployment",
+ "spec":{"deployments":[{"name":"a","spec":{"selector":null,"template":{"spec":{"containers":null}}}},
+ {"name":"b","spec":{"selector":null,"template":{"spec":{"hostNetwork":true,"containers":null}}}}]}}}}},
+{"packageName":"bar","bundleImagePath":"bar:v1.0.0","bundleChannel":["stable"],"isHeadOfChannel":true,
+ "maxOCPVersion":"4.10","validatorErrors":["error a"]}
+],"flags":{"image":"index:v1"},"indexImageInspect":{},"generateAt":"2021-04-22"}`
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		where string
+		want  []string
+	}{
+		{name: "all", where: "", want: []string{"foo:v0.1.0", "foo.v0.2.0", "bar:v1.0.0"}},
+		{name: "bool field", where: "head", want: []string{"foo.v0.2.0", "bar:v1.0.0"}},
+		{name: "not and missing field", where: "head and not maxOCPVersion", want: []string{"foo.v0.2.0"}},
+		{name: "any item of a list", where: "csv.spec.install.spec.deployments.spec.template.spec.hostNetwork == true",
+			want: []string{"foo.v0.2.0"}},
+		{name: "not equal to any item", where: `channels != "alpha"`, want: []string{"bar:v1.0.0"}},
+		{name: "contains", where: `channels contains "stable" && package == 'foo'`, want: []string{"foo.v0.2.0"}},
+		{name: "versions", where: "maxOCPVersion >= 4.9", want: []string{"bar:v1.0.0"}},
+		{name: "regex and brackets", where: `annotations["operators.openshift.io/infrastructure-features"] =~ "disc"`,
+			want: []string{"foo:v0.1.0"}},
+		{name: "or and parentheses", where: `(validatorErrors or fromDefaultChannel) and package matches "^f"`,
+			want: []string{"foo.v0.2.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(tt.where, []string{"bundle"})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			result, err := q.Run(strings.NewReader(report))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got []string
+			for _, row := range result.Rows {
+				got = append(got, toString(row[0]))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"head ==", "(head", `package == "foo`, "head $ true", "annotations[", "head head"} {
+		if _, err := New(expr, nil); err == nil {
+			t.Errorf("New(%q) expected an error", expr)
+		}
+	}
+}
+
+func TestResultWrite(t *testing.T) {
+	result := &Result{Fields: []string{"package", "channels"},
+		Rows: [][]interface{}{{"foo", []interface{}{"alpha", "stable"}}}}
+
+	var buf bytes.Buffer
+	if err := result.Write(&buf, CSV); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := "package,channels\nfoo,\"alpha, stable\"\n"; buf.String() != want {
+		t.Errorf("Write() csv = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := result.Write(&buf, Table); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "PACKAGE  CHANNELS\nfoo") {
+		t.Errorf("Write() table = %q", buf.String())
+	}
+
+	if err := result.Write(&buf, "yaml"); err == nil {
+		t.Errorf("Write() expected an error for an unsupported format")
+	}
+}
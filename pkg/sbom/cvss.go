@@ -0,0 +1,169 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"math"
+	"strings"
+)
+
+// cvssV3Weights are the weights of the metrics of the CVSS v3 base score.
+// See: https://www.first.org/cvss/v3.1/specification-document#7-4-Metric-Values
+var cvssV3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvssV2Weights are the weights of the metrics of the CVSS v2 base score.
+// See: https://www.first.org/cvss/v2/guide#3-2-1-Base-Equation
+var cvssV2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.660},
+	"I":  {"N": 0, "P": 0.275, "C": 0.660},
+	"A":  {"N": 0, "P": 0.275, "C": 0.660},
+}
+
+// severityFromCVSS returns the severity of the base score of the CVSS v3 or v2 vector, e.g.
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H is CRITICAL. It returns false when the vector cannot be scored
+// (e.g. CVSS v4) or when its score is 0.
+func severityFromCVSS(vector string) (string, bool) {
+	if strings.HasPrefix(vector, "CVSS:3.") {
+		score, ok := cvssV3Score(vector)
+		if !ok {
+			return "", false
+		}
+		switch {
+		case score >= 9:
+			return SeverityCritical, true
+		case score >= 7:
+			return SeverityHigh, true
+		case score >= 4:
+			return SeverityMedium, true
+		case score > 0:
+			return SeverityLow, true
+		}
+		return "", false
+	}
+
+	// the CVSS v4 is not scored and the CVSS v2 has no critical severity
+	if strings.HasPrefix(vector, "CVSS:") && !strings.HasPrefix(vector, "CVSS:2.") {
+		return "", false
+	}
+	score, ok := cvssV2Score(vector)
+	if !ok {
+		return "", false
+	}
+	switch {
+	case score >= 7:
+		return SeverityHigh, true
+	case score >= 4:
+		return SeverityMedium, true
+	case score > 0:
+		return SeverityLow, true
+	}
+	return "", false
+}
+
+// cvssMetrics returns the values of the metrics of the vector which are found in the weights
+func cvssMetrics(vector string, weights map[string]map[string]float64) (map[string]float64, map[string]string, bool) {
+	values := map[string]float64{}
+	raw := map[string]string{}
+	for _, part := range strings.Split(vector, "/") {
+		metric, value, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+		raw[metric] = value
+		if w, ok := weights[metric]; ok {
+			weight, ok := w[value]
+			if !ok {
+				return nil, nil, false
+			}
+			values[metric] = weight
+		}
+	}
+	if len(values) != len(weights) {
+		return nil, nil, false
+	}
+	return values, raw, true
+}
+
+func cvssV3Score(vector string) (float64, bool) {
+	m, raw, ok := cvssMetrics(vector, cvssV3Weights)
+	if !ok {
+		return 0, false
+	}
+	changed := false
+	switch raw["S"] {
+	case "C":
+		changed = true
+	case "U":
+	default:
+		return 0, false
+	}
+	if changed {
+		// the privileges required weigh more when the scope is changed
+		switch raw["PR"] {
+		case "L":
+			m["PR"] = 0.68
+		case "H":
+			m["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-m["C"])*(1-m["I"])*(1-m["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * m["AV"] * m["AC"] * m["PR"] * m["UI"]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvssRoundUp returns the smallest number with one decimal which is equal or higher than the value
+// See: https://www.first.org/cvss/v3.1/specification-document#Appendix-A---Floating-Point-Rounding
+func cvssRoundUp(value float64) float64 {
+	intValue := int(math.Round(value * 100000))
+	if intValue%10000 == 0 {
+		return float64(intValue) / 100000
+	}
+	return (math.Floor(float64(intValue)/10000) + 1) / 10
+}
+
+func cvssV2Score(vector string) (float64, bool) {
+	m, _, ok := cvssMetrics(strings.Trim(vector, "()"), cvssV2Weights)
+	if !ok {
+		return 0, false
+	}
+	impact := 10.41 * (1 - (1-m["C"])*(1-m["I"])*(1-m["A"]))
+	exploitability := 20 * m["AV"] * m["AC"] * m["Au"]
+	if impact == 0 {
+		return 0, true
+	}
+	return math.Round((0.6*impact+0.4*exploitability-1.5)*1.176*10) / 10, true
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom parses the SBOMs of the images and matches their packages against an offline vulnerability
+// database so that the images shipped by the Operators can be checked without access to the internet.
+package sbom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Formats of the SBOMs supported (JSON)
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Package is a software package listed in the SBOM
+type Package struct {
+	Name    string
+	Version string
+	PURL    string
+	// Ecosystem is the type of the purl of the package, e.g. golang, npm or rpm
+	Ecosystem string
+}
+
+type spdxDocument struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	BOMFormat  string               `json:"bomFormat"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// Parse returns the format and the packages of the SBOM in the SPDX or CycloneDX JSON format.
+// The packages without purl are ignored since it is not possible to know their ecosystem.
+func Parse(data []byte) (string, []Package, error) {
+	var cdx cycloneDXDocument
+	if err := json.Unmarshal(data, &cdx); err != nil {
+		return "", nil, fmt.Errorf("unable to parse the SBOM: %s", err)
+	}
+	if strings.EqualFold(cdx.BOMFormat, "CycloneDX") {
+		var packages []Package
+		var walk func(components []cycloneDXComponent)
+		walk = func(components []cycloneDXComponent) {
+			for _, c := range components {
+				if p, ok := newPackage(c.Name, c.Version, c.PURL); ok {
+					packages = append(packages, p)
+				}
+				walk(c.Components)
+			}
+		}
+		walk(cdx.Components)
+		return FormatCycloneDX, packages, nil
+	}
+
+	var spdx spdxDocument
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return "", nil, fmt.Errorf("unable to parse the SBOM: %s", err)
+	}
+	if !strings.HasPrefix(spdx.SPDXVersion, "SPDX-") {
+		return "", nil, errors.New("the SBOM is not in the SPDX or CycloneDX JSON format")
+	}
+	var packages []Package
+	for _, p := range spdx.Packages {
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType != "purl" {
+				continue
+			}
+			if pkg, ok := newPackage(p.Name, p.VersionInfo, ref.ReferenceLocator); ok {
+				packages = append(packages, pkg)
+			}
+			break
+		}
+	}
+	return FormatSPDX, packages, nil
+}
+
+func newPackage(name, version, purl string) (Package, bool) {
+	ecosystem, purlName, purlVersion, ok := parsePURL(purl)
+	if !ok {
+		return Package{}, false
+	}
+	if len(purlName) > 0 {
+		name = purlName
+	}
+	if len(purlVersion) > 0 {
+		version = purlVersion
+	}
+	return Package{Name: name, Version: version, PURL: purl, Ecosystem: ecosystem}, true
+}
+
+// parsePURL returns the type, the name (with its namespace) and the version of the package url,
+// e.g. pkg:golang/github.com/foo/bar@v1.0.0. See: https://github.com/package-url/purl-spec
+func parsePURL(purl string) (string, string, string, bool) {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return "", "", "", false
+	}
+	value := strings.TrimPrefix(purl, "pkg:")
+	if i := strings.IndexAny(value, "?#"); i >= 0 {
+		value = value[:i]
+	}
+	version := ""
+	if i := strings.LastIndex(value, "@"); i >= 0 {
+		version, _ = url.PathUnescape(value[i+1:])
+		value = value[:i]
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 {
+		return "", "", "", false
+	}
+	ecosystem := strings.ToLower(parts[0])
+	var names []string
+	for _, p := range parts[1:] {
+		unescaped, err := url.PathUnescape(p)
+		if err != nil {
+			unescaped = p
+		}
+		names = append(names, unescaped)
+	}
+	separator := "/"
+	if ecosystem == "maven" {
+		separator = ":"
+	}
+	// the namespace of the OS packages is the distro, e.g. pkg:rpm/redhat/openssl
+	if isOSEcosystem(ecosystem) {
+		names = names[len(names)-1:]
+	}
+	return ecosystem, strings.Join(names, separator), version, true
+}
+
+func isOSEcosystem(ecosystem string) bool {
+	return ecosystem == "rpm" || ecosystem == "deb" || ecosystem == "apk"
+}
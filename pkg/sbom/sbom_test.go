@@ -0,0 +1,218 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"name": "golang.org/x/net", "version": "v0.10.0", "purl": "pkg:golang/golang.org/x/net@v0.10.0",
+     "components": [{"name": "openssl-libs", "purl": "pkg:rpm/redhat/openssl-libs@3.0.7-16.el9?arch=x86_64"}]},
+    {"name": "no-purl", "version": "1.0.0"}
+  ]
+}`
+
+const spdx = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"name": "requests", "versionInfo": "2.30.0", "externalRefs": [
+      {"referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:python:requests:2.30.0"},
+      {"referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.30.0"}]},
+    {"name": "log4j-core", "versionInfo": "2.14.1", "externalRefs": [
+      {"referenceType": "purl", "referenceLocator": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"}]}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantFormat string
+		want       []Package
+		wantErr    bool
+	}{
+		{
+			name:       "should parse the nested components of the CycloneDX SBOM",
+			data:       cycloneDX,
+			wantFormat: FormatCycloneDX,
+			want: []Package{
+				{Name: "golang.org/x/net", Version: "v0.10.0", Ecosystem: "golang"},
+				{Name: "openssl-libs", Version: "3.0.7-16.el9", Ecosystem: "rpm"},
+			},
+		},
+		{
+			name:       "should parse the purls of the SPDX SBOM",
+			data:       spdx,
+			wantFormat: FormatSPDX,
+			want: []Package{
+				{Name: "requests", Version: "2.30.0", Ecosystem: "pypi"},
+				{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Ecosystem: "maven"},
+			},
+		},
+		{
+			name:    "should fail when the format is not supported",
+			data:    `{"name": "unknown"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, packages, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if format != tt.wantFormat || len(packages) != len(tt.want) {
+				t.Fatalf("Parse() = %s %+v, want %s %+v", format, packages, tt.wantFormat, tt.want)
+			}
+			for i, p := range packages {
+				if p.Name != tt.want[i].Name || p.Version != tt.want[i].Version ||
+					p.Ecosystem != tt.want[i].Ecosystem {
+					t.Errorf("Parse() package = %+v, want %+v", p, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// osvRecords are the records of the vulnerability database, one per line
+var osvRecords = []string{
+	`{"id": "GO-1", "affected": [{"package": {"ecosystem": "Go", "name": "golang.org/x/net"}, ` +
+		`"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.17.0"}]}]}], ` +
+		`"database_specific": {"severity": "HIGH"}}`,
+	`{"id": "RHSA-1", "affected": [{"package": {"ecosystem": "Red Hat:9", "name": "openssl-libs"}, ` +
+		`"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1:3.0.7-18.el9"}]}]}], ` +
+		`"database_specific": {"severity": "CRITICAL"}}`,
+	`{"id": "GHSA-1", "affected": [{"package": {"purl": "pkg:pypi/Requests"}, "versions": ["2.30.0"]}], ` +
+		`"database_specific": {"severity": "MODERATE"}}`,
+	`{"id": "GHSA-2", "affected": [{"package": {"ecosystem": "Maven", ` +
+		`"name": "org.apache.logging.log4j:log4j-core"}, "ranges": [{"type": "ECOSYSTEM", ` +
+		`"events": [{"introduced": "2.0-beta9"}, {"last_affected": "2.14.1"}]}, ` +
+		`{"type": "GIT", "events": [{"introduced": "abc"}]}]}]}`,
+	`{"id": "GHSA-3", "affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "versions": ["4.17.20"]}], ` +
+		`"severity": [{"type": "CVSS_V4", "score": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}, ` +
+		`{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}], ` +
+		`"database_specific": {"severity": "MODERATE"}}`,
+	`{"id": "GHSA-4", "affected": [{"package": {"ecosystem": "npm", "name": "minimist"}, "versions": ["1.2.5"]}], ` +
+		`"severity": [{"type": "CVSS_V4", "score": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}], ` +
+		`"database_specific": {"severity": "LOW"}}`,
+}
+
+func TestLoadDatabaseAndMatch(t *testing.T) {
+	dir := t.TempDir()
+	jsonLines := filepath.Join(dir, "osv.jsonl")
+	if err := os.WriteFile(jsonLines, []byte(strings.Join(osvRecords, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadDatabase(jsonLines)
+	if err != nil {
+		t.Fatalf("LoadDatabase() error = %v", err)
+	}
+	if db.Records != 6 {
+		t.Fatalf("LoadDatabase() loaded %d records", db.Records)
+	}
+
+	tests := []struct {
+		pkg  Package
+		want string
+	}{
+		{pkg: Package{Name: "golang.org/x/net", Version: "v0.10.0", Ecosystem: "golang"}, want: "GO-1 HIGH 0.17.0"},
+		{pkg: Package{Name: "golang.org/x/net", Version: "v0.17.0", Ecosystem: "golang"}},
+		{pkg: Package{Name: "openssl-libs", Version: "3.0.7-16.el9", Ecosystem: "rpm"},
+			want: "RHSA-1 CRITICAL 1:3.0.7-18.el9"},
+		{pkg: Package{Name: "requests", Version: "2.30.0", Ecosystem: "pypi"}, want: "GHSA-1 MEDIUM "},
+		{pkg: Package{Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Ecosystem: "maven"},
+			want: "GHSA-2 UNKNOWN "},
+		{pkg: Package{Name: "org.apache.logging.log4j:log4j-core", Version: "2.15.0", Ecosystem: "maven"}},
+		// the severity of the CVSS vectors has precedence over the database_specific one
+		{pkg: Package{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}, want: "GHSA-3 CRITICAL "},
+		{pkg: Package{Name: "minimist", Version: "1.2.5", Ecosystem: "npm"}, want: "GHSA-4 LOW "},
+	}
+	for _, tt := range tests {
+		got := ""
+		for _, f := range db.Match(tt.pkg) {
+			got = f.ID + " " + f.Severity + " " + f.FixedIn
+		}
+		if got != tt.want {
+			t.Errorf("Match(%+v) = %q, want %q", tt.pkg, got, tt.want)
+		}
+	}
+
+	jsonArray := filepath.Join(dir, "osv.json")
+	if err := os.WriteFile(jsonArray, []byte(`[{"id": "GO-1"}, {"id": "GO-2"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if db, err := LoadDatabase(jsonArray); err != nil || db.Records != 2 {
+		t.Errorf("LoadDatabase() = %+v, %v", db, err)
+	}
+}
+
+func TestSeverityFromCVSS(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   string
+	}{
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", want: "CRITICAL 9.8"},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", want: "CRITICAL 10.0"},
+		{vector: "CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N", want: "MEDIUM 6.4"},
+		{vector: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", want: "MEDIUM 5.5"},
+		{vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:U/C:H/I:H/A:N", want: "MEDIUM 6.8"},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", want: "HIGH 7.5"},
+		{vector: "CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", want: "LOW 1.6"},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", want: " 0.0"},
+		{vector: "AV:N/AC:L/Au:N/C:C/I:C/A:C", want: "HIGH 10.0"},
+		{vector: "AV:N/AC:M/Au:N/C:P/I:N/A:N", want: "MEDIUM 4.3"},
+		{vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", want: " 0.0"},
+		{vector: "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", want: " 0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vector, func(t *testing.T) {
+			severity, _ := severityFromCVSS(tt.vector)
+			score, _ := cvssV3Score(tt.vector)
+			if !strings.HasPrefix(tt.vector, "CVSS:3.") {
+				score, _ = cvssV2Score(tt.vector)
+			}
+			if got := fmt.Sprintf("%s %.1f", severity, score); got != tt.want {
+				t.Errorf("severityFromCVSS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.0", "1.9.2", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0.1", -1},
+		{"2.0-beta9", "2.0.0", -1},
+		{"1:3.0.7-16.el9", "3.0.7-18.el9", -1},
+		{"0.17.0", "v0.17.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Severities of the vulnerabilities
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+	SeverityUnknown  = "UNKNOWN"
+)
+
+// ecosystems maps the types of the purls to the ecosystems of the OSV records.
+// See: https://ossf.github.io/osv-schema/#affectedpackage-field
+var ecosystems = map[string]string{
+	"golang":   "go",
+	"npm":      "npm",
+	"pypi":     "pypi",
+	"maven":    "maven",
+	"cargo":    "crates.io",
+	"gem":      "rubygems",
+	"nuget":    "nuget",
+	"composer": "packagist",
+	"hex":      "hex",
+	"pub":      "pub",
+	"apk":      "alpine",
+	"deb":      "debian",
+	"rpm":      "red hat",
+}
+
+// record is a vulnerability in the OSV format (https://ossf.github.io/osv-schema/). Only the fields used to match
+// the packages are parsed.
+type record struct {
+	ID       string `json:"id"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+			PURL      string `json:"purl"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string              `json:"type"`
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// severity returns the highest severity of the CVSS vectors of the record or, when none of them can be scored, the
+// severity informed by the database (e.g. GitHub and Red Hat inform it in the database_specific)
+func (r record) severity() string {
+	order := map[string]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+	result := ""
+	for _, s := range r.Severity {
+		severity := ""
+		switch s.Type {
+		case "CVSS_V3", "CVSS_V2":
+			severity, _ = severityFromCVSS(s.Score)
+		case "Ubuntu":
+			severity = normalizeSeverity(s.Score)
+		}
+		if order[severity] > order[result] {
+			result = severity
+		}
+	}
+	if len(result) > 0 {
+		return result
+	}
+	return normalizeSeverity(r.DatabaseSpecific.Severity)
+}
+
+// affected is a package affected by a vulnerability of the database
+type affected struct {
+	id       string
+	severity string
+	ranges   [][]map[string]string
+	versions []string
+}
+
+// Finding is a vulnerability found for a package of the SBOM
+type Finding struct {
+	ID       string
+	Package  string
+	Version  string
+	Severity string
+	// FixedIn is the first version which fixes the vulnerability, when known
+	FixedIn string
+}
+
+// Database is an offline vulnerability database with the records in the OSV format
+type Database struct {
+	// affected are the vulnerabilities per package (<ecosystem>/<name>)
+	affected map[string][]affected
+	// Records is the number of vulnerabilities loaded
+	Records int
+}
+
+// LoadDatabase loads the vulnerability database from a file with the OSV records as a JSON array or one JSON
+// record per line (e.g. the records of https://osv.dev/ downloaded per ecosystem)
+func LoadDatabase(path string) (*Database, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("unable to parse the vulnerability database %s: %s", path, err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var r record
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				return nil, fmt.Errorf("unable to parse the line %d of the vulnerability database %s: %s",
+					line, path, err)
+			}
+			records = append(records, r)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	db := &Database{affected: map[string][]affected{}, Records: len(records)}
+	for _, r := range records {
+		severity := r.severity()
+		for _, a := range r.Affected {
+			ecosystem, name := a.Package.Ecosystem, a.Package.Name
+			if purlType, purlName, _, ok := parsePURL(a.Package.PURL); ok {
+				ecosystem, name = purlType, purlName
+			}
+			entry := affected{id: r.ID, severity: severity, versions: a.Versions}
+			for _, versionRange := range a.Ranges {
+				// the commits of the GIT ranges cannot be compared with the versions of the packages
+				if versionRange.Type != "GIT" {
+					entry.ranges = append(entry.ranges, versionRange.Events)
+				}
+			}
+			key := packageKey(ecosystem, name)
+			db.affected[key] = append(db.affected[key], entry)
+		}
+	}
+	return db, nil
+}
+
+// Match returns the vulnerabilities of the database which affect the version of the package
+func (db *Database) Match(p Package) []Finding {
+	var findings []Finding
+	for _, a := range db.affected[packageKey(p.Ecosystem, p.Name)] {
+		if affectedVersion, fixedIn := a.affects(p.Version); affectedVersion {
+			findings = append(findings, Finding{ID: a.id, Package: p.Name, Version: p.Version,
+				Severity: a.severity, FixedIn: fixedIn})
+		}
+	}
+	return findings
+}
+
+// affects returns true when the version is in the versions or in the ranges affected and the version which fixes it
+func (a affected) affects(version string) (bool, string) {
+	if len(version) == 0 {
+		return false, ""
+	}
+	for _, v := range a.versions {
+		if compareVersions(v, version) == 0 {
+			return true, ""
+		}
+	}
+	for _, events := range a.ranges {
+		isAffected := false
+		for _, event := range events {
+			switch {
+			case len(event["introduced"]) > 0:
+				if event["introduced"] == "0" || compareVersions(version, event["introduced"]) >= 0 {
+					isAffected = true
+				}
+			case len(event["fixed"]) > 0:
+				if isAffected && compareVersions(version, event["fixed"]) < 0 {
+					return true, event["fixed"]
+				}
+				isAffected = false
+			case len(event["last_affected"]) > 0:
+				if isAffected && compareVersions(version, event["last_affected"]) <= 0 {
+					return true, ""
+				}
+				isAffected = false
+			}
+		}
+		if isAffected {
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+// packageKey returns the key of the package in the database. The purl types are converted to the OSV ecosystems
+// and the releases of the OS ecosystems are ignored, e.g. Debian:12 is debian.
+func packageKey(ecosystem, name string) string {
+	ecosystem = strings.ToLower(ecosystem)
+	if i := strings.Index(ecosystem, ":"); i > 0 {
+		ecosystem = ecosystem[:i]
+	}
+	if value, ok := ecosystems[ecosystem]; ok {
+		ecosystem = value
+	}
+	name = strings.ToLower(name)
+	if ecosystem == "pypi" {
+		name = strings.ReplaceAll(name, "_", "-")
+	}
+	return ecosystem + "/" + name
+}
+
+func normalizeSeverity(severity string) string {
+	switch strings.ToUpper(severity) {
+	case SeverityCritical:
+		return SeverityCritical
+	case SeverityHigh:
+		return SeverityHigh
+	case SeverityMedium, "MODERATE":
+		return SeverityMedium
+	case SeverityLow:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// compareVersions compares the versions by their numeric and alphanumeric parts, e.g. v1.10.0 > 1.9.2 and
+// 1.0.0-rc1 < 1.0.0. It does not follow the rules of each ecosystem but it is enough for the common cases.
+func compareVersions(a, b string) int {
+	partsA, partsB := versionParts(a), versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		switch {
+		case i >= len(partsA):
+			return comparePreRelease(partsB[i])
+		case i >= len(partsB):
+			return -comparePreRelease(partsA[i])
+		}
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			return 1
+		case errB == nil:
+			return -1
+		case partsA[i] != partsB[i]:
+			if partsA[i] < partsB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// comparePreRelease returns the result of the comparison of a version without the part with a version with it,
+// e.g. 1.0.0 > 1.0.0-rc1 but 1.0.0 < 1.0.0.1
+func comparePreRelease(part string) int {
+	if _, err := strconv.Atoi(part); err == nil {
+		return -1
+	}
+	return 1
+}
+
+// versionParts splits the version by its numeric and alphanumeric parts ignoring the prefix v and the epoch
+func versionParts(version string) []string {
+	version = strings.TrimPrefix(strings.ToLower(version), "v")
+	if i := strings.Index(version, ":"); i >= 0 {
+		version = version[i+1:]
+	}
+	var parts []string
+	var current strings.Builder
+	lastIsDigit := false
+	for _, r := range version {
+		isDigit := unicode.IsDigit(r)
+		if !unicode.IsLetter(r) && !isDigit {
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		if current.Len() > 0 && isDigit != lastIsDigit {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		current.WriteRune(r)
+		lastIsDigit = isDigit
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
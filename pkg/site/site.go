@@ -0,0 +1,301 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// DashboardsDir is the directory of the site where the dashboards are generated. They are organized by catalog and
+// version, e.g. dashboards/quay.io_operatorhubio_catalog/latest/qa_quay.io_operatorhubio_catalog_latest.html
+const DashboardsDir = "dashboards"
+
+// navBegin and navEnd delimit the navigation added to the dashboards so that it can be replaced
+const (
+	navBegin = "<!-- audit-site-nav -->"
+	navEnd   = "<!-- /audit-site-nav -->"
+)
+
+// titles of the dashboards generated by audit. The dashboards of other kinds are shown by their kind.
+var titles = map[string]string{
+	"qa":              "Projects QA",
+	"bundle-size":     "Bundle Size",
+	"security":        "Pod Security",
+	"rbac":            "RBAC Permissions",
+	"multiarch":       "Multi-Arch",
+	"validator":       "Validator",
+	"interactive":     "Interactive Report",
+	"features":        "Feature Annotations",
+	"disconnected":    "Disconnected",
+	"images":          "Images",
+	"vulnerabilities": "Vulnerabilities",
+	"maxocp":          "Max OCP Version - Monitor",
+}
+
+// Dashboard is an HTML dashboard of the site
+type Dashboard struct {
+	Kind    string
+	Title   string
+	Version string
+	// Path of the dashboard relative to the root of the site
+	Path string
+}
+
+// Version has the dashboards generated from the report of a version (tag) of the catalog
+type Version struct {
+	Name       string
+	Dashboards []Dashboard
+}
+
+// Row has the same kind of dashboard for all versions of a catalog. The Path is empty for the versions
+// without the dashboard.
+type Row struct {
+	Kind  string
+	Title string
+	Cells []Dashboard
+}
+
+// Catalog has the dashboards of all versions of a catalog (index image without the tag)
+type Catalog struct {
+	Name     string
+	Dir      string
+	Versions []Version
+	Rows     []Row
+}
+
+// Site has all dashboards found in the dashboards directory
+type Site struct {
+	Catalogs []Catalog
+}
+
+// CatalogDir returns the name of the directory of the catalog of the image informed
+func CatalogDir(image string) string {
+	name := CatalogName(image)
+	name = strings.ReplaceAll(name, "/", "_")
+	name = strings.ReplaceAll(name, ":", "_")
+	return name
+}
+
+// VersionFromImage returns the tag or the digest of the image, or latest when it has none
+func VersionFromImage(image string) string {
+	last := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(last, "@"); i >= 0 {
+		return strings.ReplaceAll(last[i+1:], ":", "_")
+	}
+	if i := strings.LastIndex(last, ":"); i >= 0 {
+		return last[i+1:]
+	}
+	return "latest"
+}
+
+// CatalogName returns the image without the tag or the digest
+func CatalogName(image string) string {
+	version := VersionFromImage(image)
+	if strings.HasSuffix(image, ":"+version) {
+		return strings.TrimSuffix(image, ":"+version)
+	}
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[:i]
+	}
+	return image
+}
+
+// OutputDir returns the directory where the dashboards of the image informed are generated
+func OutputDir(sitePath, image string) string {
+	return filepath.Join(sitePath, DashboardsDir, CatalogDir(image), VersionFromImage(image))
+}
+
+// Scan returns the site with all dashboards found in the dashboards directory of the site path. The names are
+// the images of the catalogs by their directories, which are shown instead of the names of the directories.
+func Scan(sitePath string, names map[string]string) (*Site, error) {
+	root := filepath.Join(sitePath, DashboardsDir)
+	catalogDirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Site{}, nil
+		}
+		return nil, err
+	}
+
+	site := &Site{}
+	for _, catalogDir := range catalogDirs {
+		if !catalogDir.IsDir() {
+			continue
+		}
+		catalog := Catalog{Name: catalogDir.Name(), Dir: catalogDir.Name()}
+		if name, ok := names[catalogDir.Name()]; ok {
+			catalog.Name = name
+		}
+
+		versionDirs, err := os.ReadDir(filepath.Join(root, catalogDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, versionDir := range versionDirs {
+			if !versionDir.IsDir() {
+				continue
+			}
+			version, err := scanVersion(root, catalogDir.Name(), versionDir.Name())
+			if err != nil {
+				return nil, err
+			}
+			if len(version.Dashboards) > 0 {
+				catalog.Versions = append(catalog.Versions, version)
+			}
+		}
+		if len(catalog.Versions) == 0 {
+			continue
+		}
+		sortVersions(catalog.Versions)
+		catalog.Rows = rows(catalog.Versions)
+		site.Catalogs = append(site.Catalogs, catalog)
+	}
+
+	sort.Slice(site.Catalogs, func(i, j int) bool {
+		return site.Catalogs[i].Name < site.Catalogs[j].Name
+	})
+	return site, nil
+}
+
+func scanVersion(root, catalogDir, versionDir string) (Version, error) {
+	version := Version{Name: versionDir}
+	files, err := os.ReadDir(filepath.Join(root, catalogDir, versionDir))
+	if err != nil {
+		return version, err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".html") {
+			continue
+		}
+		kind := KindFromFileName(f.Name())
+		version.Dashboards = append(version.Dashboards, Dashboard{
+			Kind:    kind,
+			Title:   Title(kind),
+			Version: versionDir,
+			Path:    path.Join(DashboardsDir, catalogDir, versionDir, f.Name()),
+		})
+	}
+	sort.Slice(version.Dashboards, func(i, j int) bool {
+		return version.Dashboards[i].Title < version.Dashboards[j].Title
+	})
+	return version, nil
+}
+
+// KindFromFileName returns the kind of the dashboard from the name of its file, e.g. qa for
+// qa_quay.io_operatorhubio_catalog_latest.html
+func KindFromFileName(name string) string {
+	name = strings.TrimSuffix(name, ".html")
+	if i := strings.Index(name, "_"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// Title returns the title of the kind of dashboard informed
+func Title(kind string) string {
+	if title, ok := titles[kind]; ok {
+		return title
+	}
+	if strings.HasPrefix(kind, "deprecate-apis-") {
+		return fmt.Sprintf("Removed API(s) in %s", strings.TrimPrefix(kind, "deprecate-apis-"))
+	}
+	return kind
+}
+
+// sortVersions sorts the versions as semantic versions when possible, e.g. v4.9 before v4.10
+func sortVersions(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := semver.ParseTolerant(versions[i].Name)
+		vj, errj := semver.ParseTolerant(versions[j].Name)
+		switch {
+		case erri == nil && errj == nil:
+			return vi.LT(vj)
+		case erri == nil:
+			return true
+		case errj == nil:
+			return false
+		}
+		return versions[i].Name < versions[j].Name
+	})
+}
+
+func rows(versions []Version) []Row {
+	byKind := make(map[string]*Row)
+	var kinds []string
+	for i, v := range versions {
+		for _, d := range v.Dashboards {
+			row, ok := byKind[d.Kind]
+			if !ok {
+				row = &Row{Kind: d.Kind, Title: d.Title, Cells: make([]Dashboard, len(versions))}
+				byKind[d.Kind] = row
+				kinds = append(kinds, d.Kind)
+			}
+			row.Cells[i] = d
+		}
+	}
+
+	result := make([]Row, 0, len(kinds))
+	for _, kind := range kinds {
+		result = append(result, *byKind[kind])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Title < result[j].Title
+	})
+	return result
+}
+
+// Relative returns the path informed, relative to the root of the site, from the dashboard informed
+func (d Dashboard) Relative(target string) string {
+	depth := strings.Count(d.Path, "/")
+	return strings.Repeat("../", depth) + target
+}
+
+// InjectNav adds the navigation informed to the dashboard file after the <main> tag. The navigation previously
+// added is replaced so that the site can be generated again in the same directory.
+func InjectNav(file string, nav []byte) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if begin := bytes.Index(data, []byte(navBegin)); begin >= 0 {
+		if end := bytes.Index(data, []byte(navEnd)); end > begin {
+			data = append(data[:begin:begin], data[end+len(navEnd):]...)
+		}
+	}
+
+	anchor := []byte("<main>")
+	i := bytes.Index(data, anchor)
+	if i < 0 {
+		return fmt.Errorf("unable to find where to add the navigation in %s", file)
+	}
+	i += len(anchor)
+
+	var out bytes.Buffer
+	out.Write(data[:i])
+	out.WriteString(navBegin)
+	out.Write(nav)
+	out.WriteString(navEnd)
+	out.Write(data[i:])
+	return os.WriteFile(file, out.Bytes(), 0644)
+}
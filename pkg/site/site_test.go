@@ -0,0 +1,102 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageNames(t *testing.T) {
+	tests := []struct {
+		image, catalogDir, catalogName, version string
+	}{
+		{"quay.io/operatorhubio/catalog:latest", "quay.io_operatorhubio_catalog", "quay.io/operatorhubio/catalog",
+			"latest"},
+		{"localhost:5000/my/index:v4.10", "localhost_5000_my_index", "localhost:5000/my/index", "v4.10"},
+		{"localhost:5000/my/index", "localhost_5000_my_index", "localhost:5000/my/index", "latest"},
+		{"quay.io/my/index@sha256:abc", "quay.io_my_index", "quay.io/my/index", "sha256_abc"},
+	}
+	for _, tt := range tests {
+		if got := CatalogDir(tt.image); got != tt.catalogDir {
+			t.Errorf("CatalogDir(%s) = %s, want %s", tt.image, got, tt.catalogDir)
+		}
+		if got := CatalogName(tt.image); got != tt.catalogName {
+			t.Errorf("CatalogName(%s) = %s, want %s", tt.image, got, tt.catalogName)
+		}
+		if got := VersionFromImage(tt.image); got != tt.version {
+			t.Errorf("VersionFromImage(%s) = %s, want %s", tt.image, got, tt.version)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	sitePath := t.TempDir()
+	files := []string{
+		"dashboards/my_index/v4.10/qa_my_index_v4.10.html",
+		"dashboards/my_index/v4.9/qa_my_index_v4.9.html",
+		"dashboards/my_index/v4.9/deprecate-apis-1.25_my_index_v4.9.html",
+		"dashboards/my_index/v4.9/notes.txt",
+	}
+	for _, f := range files {
+		path := filepath.Join(sitePath, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<html><main></main></html>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site, err := Scan(sitePath, map[string]string{"my_index": "my/index"})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(site.Catalogs) != 1 || site.Catalogs[0].Name != "my/index" {
+		t.Fatalf("unexpected catalogs: %+v", site.Catalogs)
+	}
+	catalog := site.Catalogs[0]
+	if len(catalog.Versions) != 2 || catalog.Versions[0].Name != "v4.9" || catalog.Versions[1].Name != "v4.10" {
+		t.Errorf("the versions are not sorted: %+v", catalog.Versions)
+	}
+	if len(catalog.Rows) != 2 || catalog.Rows[0].Title != "Projects QA" ||
+		catalog.Rows[1].Title != "Removed API(s) in 1.25" {
+		t.Fatalf("unexpected rows: %+v", catalog.Rows)
+	}
+	if deprecate := catalog.Rows[1]; deprecate.Cells[0].Path == "" || deprecate.Cells[1].Path != "" {
+		t.Errorf("the dashboard is only in the first version: %+v", deprecate.Cells)
+	}
+
+	dashboard := catalog.Versions[0].Dashboards[0]
+	if got := dashboard.Relative("index.html"); got != "../../../index.html" {
+		t.Errorf("Relative() = %s", got)
+	}
+
+	file := filepath.Join(sitePath, dashboard.Path)
+	for i := 0; i < 2; i++ {
+		if err := InjectNav(file, []byte("<nav/>")); err != nil {
+			t.Fatalf("InjectNav() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), "<nav/>") != 1 || !strings.HasPrefix(string(data), "<html><main>"+navBegin) {
+		t.Errorf("unexpected navigation added: %s", data)
+	}
+}
@@ -16,7 +16,13 @@ package validation
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/operator-framework/api/pkg/encoding"
 	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/validation/errors"
 	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
@@ -26,9 +32,11 @@ import (
 // note that this check will raise an error if the size is bigger than the max allowed
 // and warnings when:
 // - we are unable to check the bundle size because we are running a check without load the bundle
-// - we could identify that the bundle size is close to the limit (bigger than 85%)
+// - we could identify that the bundle size is close to the limit (bigger than 85% or the value informed via the
+// optional value warn-percent)
 // - [Deprecated and planned to be removed at 2023 -  The API will start growing to encompass validation for all past
-// history] if the bundle size uncompressed < ~1MB and it cannot work on clusters which uses OLM versions < 1.17.5
+// history] if the bundle size uncompressed < ~1MB and it cannot work on clusters which uses OLM versions < 1.17.5.
+// This check is only done when the optional value check-uncompressed-size=true is informed.
 // todo: remove this check when OCP 4.8 be in EOL.
 var BundleSizeValidator interfaces.Validator = interfaces.ValidatorFunc(validateBundleSizeValidator)
 
@@ -38,21 +46,130 @@ var BundleSizeValidator interfaces.Validator = interfaces.ValidatorFunc(validate
 // We will use this value to check the bundle compressed is < ~1MB
 const maxBundleSize = int64(1 << (10 * 2))
 
+// DefaultWarnPercent is the percentage of maxBundleSize from which we warn that the bundle is close to the limit
+const DefaultWarnPercent = 85.0
+
+// WarnPercentKey defines the optional value key which can be used to inform the warning threshold
+const WarnPercentKey = "warn-percent"
+
+// CheckUncompressedSizeKey defines the optional value key which enables the legacy uncompressed size check
+// required for indexes consumed by OLM versions < 1.17.5 (OCP < 4.9)
+const CheckUncompressedSizeKey = "check-uncompressed-size"
+
+// ManifestSize stores the size of a file shipped in the bundle
+type ManifestSize struct {
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	CompressedSize int64  `json:"compressedSize"`
+}
+
+// BundleSize stores the size analysis of a bundle. The CompressedSize is calculated as OLM does
+// when it stages the bundle in the ConfigMap (gzip followed by base64 encoding per file)
+type BundleSize struct {
+	Size           int64          `json:"size"`
+	CompressedSize int64          `json:"compressedSize"`
+	MaxSize        int64          `json:"maxSize"`
+	UsedPercent    float64        `json:"usedPercent"`
+	Manifests      []ManifestSize `json:"manifests,omitempty"`
+}
+
+// NewBundleSizeFromDir returns the size analysis for the bundle files found in the directory informed.
+// The manifests are returned sorted by their compressed size, biggest first.
+func NewBundleSizeFromDir(bundleDir string) (*BundleSize, error) {
+	bundleSize := &BundleSize{MaxSize: maxBundleSize}
+	err := filepath.Walk(bundleDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		contentGzip, err := encoding.GzipBase64Encode(data)
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			name = info.Name()
+		}
+		manifest := ManifestSize{Name: name, Size: info.Size(), CompressedSize: int64(len(contentGzip))}
+		bundleSize.Manifests = append(bundleSize.Manifests, manifest)
+		bundleSize.Size += manifest.Size
+		bundleSize.CompressedSize += manifest.CompressedSize
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(bundleSize.Manifests[:], func(i, j int) bool {
+		return bundleSize.Manifests[i].CompressedSize > bundleSize.Manifests[j].CompressedSize
+	})
+	bundleSize.UsedPercent = usedPercent(bundleSize.CompressedSize)
+	return bundleSize, nil
+}
+
+// IsNearLimit returns true when the compressed size is bigger than the warnPercent of the max allowed
+func (b BundleSize) IsNearLimit(warnPercent float64) bool {
+	return b.UsedPercent > warnPercent
+}
+
+// IsOverLimit returns true when the compressed size is bigger than the max allowed
+func (b BundleSize) IsOverLimit() bool {
+	return b.CompressedSize > maxBundleSize
+}
+
+// BiggestManifests returns a description of the biggest manifests to help the authors to know what
+// should be reduced
+func (b BundleSize) BiggestManifests(qtd int) []string {
+	var result []string
+	for i, m := range b.Manifests {
+		if i >= qtd {
+			break
+		}
+		result = append(result, fmt.Sprintf("%s (size=~%s, compressed=~%s)",
+			m.Name, FormatBytesInUnit(m.Size), FormatBytesInUnit(m.CompressedSize)))
+	}
+	return result
+}
+
+func usedPercent(compressedSize int64) float64 {
+	return float64(compressedSize) * 100 / float64(maxBundleSize)
+}
+
 func validateBundleSizeValidator(objs ...interface{}) (results []errors.ManifestResult) {
+	warnPercent := DefaultWarnPercent
+	checkUncompressed := false
+	for _, obj := range objs {
+		switch v := obj.(type) {
+		case map[string]string:
+			if value, ok := v[WarnPercentKey]; ok {
+				if parsed, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64); err == nil {
+					warnPercent = parsed
+				}
+			}
+			checkUncompressed = v[CheckUncompressedSizeKey] == "true"
+		}
+	}
 
 	for _, obj := range objs {
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateBundleSize(v))
+			results = append(results, validateBundleSize(v, warnPercent, checkUncompressed))
 		}
 	}
 
 	return results
 }
 
-// validateBundleSize will check the bundle size is bigger than > 1 MB ( valid up to OCP 4.9 )
-// After 4.9 we begin to compress then it is no longer an issue.
-func validateBundleSize(bundle *manifests.Bundle) errors.ManifestResult {
+// validateBundleSize will check the bundle compressed size against the limit which allows it to be staged
+// in a ConfigMap and optionally the bundle uncompressed size is bigger than > 1 MB ( valid up to OCP 4.9 )
+func validateBundleSize(bundle *manifests.Bundle, warnPercent float64, checkUncompressed bool) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
 		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
@@ -65,38 +182,67 @@ func validateBundleSize(bundle *manifests.Bundle) errors.ManifestResult {
 		return result
 	}
 
-	errors := checkBundleSize(bundle)
+	errors := checkBundleSize(bundle, warnPercent, checkUncompressed)
 	result.Add(errors...)
 
 	return result
 }
 
-func checkBundleSize(bundle *manifests.Bundle) []errors.Error {
+func checkBundleSize(bundle *manifests.Bundle, warnPercent float64, checkUncompressed bool) []errors.Error {
 	var errs []errors.Error
 
-	if bundle.Size == 0 {
+	if bundle.Size == 0 || bundle.CompressedSize == 0 {
 		errs = append(errs, errors.WarnFailedValidation("unable to check the bundle size", bundle.Name))
 		return errs
 	}
 
+	bundleSize := BundleSize{
+		Size:           bundle.Size,
+		CompressedSize: bundle.CompressedSize,
+		MaxSize:        maxBundleSize,
+		UsedPercent:    usedPercent(bundle.CompressedSize),
+	}
+
+	if bundleSize.IsOverLimit() {
+		errs = append(errs, errors.ErrInvalidBundle(
+			fmt.Sprintf("bundle compressed size exceeded the limit to be staged in a ConfigMap by OLM: "+
+				"size=~%s (%.1f%%), max=%s. Bundle uncompressed size is %s",
+				FormatBytesInUnit(bundleSize.CompressedSize),
+				bundleSize.UsedPercent,
+				FormatBytesInUnit(maxBundleSize),
+				FormatBytesInUnit(bundleSize.Size)),
+			bundle.Name))
+	} else if bundleSize.IsNearLimit(warnPercent) {
+		errs = append(errs, errors.WarnInvalidBundle(
+			fmt.Sprintf("bundle compressed size is nearing the limit to be staged in a ConfigMap by OLM: "+
+				"size=~%s (%.1f%%, warn at %.0f%%), max=%s. Bundle uncompressed size is %s",
+				FormatBytesInUnit(bundleSize.CompressedSize),
+				bundleSize.UsedPercent,
+				warnPercent,
+				FormatBytesInUnit(maxBundleSize),
+				FormatBytesInUnit(bundleSize.Size)),
+			bundle.Name))
+	}
+
 	// @Deprecated
 	// Before these versions the bundles were not compressed
 	// and their size must be < ~1MB
-	if bundle.Size > maxBundleSize {
+	if checkUncompressed && bundle.Size > maxBundleSize {
 		errs = append(errs, errors.ErrInvalidBundle(
 			fmt.Sprintf("bundle uncompressed size exceeded the limit support for OLM versions relesed prior"+
 				" 1.17.5 :  size=~%s , max=%s. "+
 				"(these bundle cannot work in any cluster or vendor which uses OLM versions < 1.17.5 and OpenShift "+
 				"versions < 4.9)",
-				formatBytesInUnit(bundle.Size),
-				formatBytesInUnit(maxBundleSize)),
+				FormatBytesInUnit(bundle.Size),
+				FormatBytesInUnit(maxBundleSize)),
 			bundle.Name))
 	}
 
 	return errs
 }
 
-func formatBytesInUnit(b int64) string {
+// FormatBytesInUnit returns the bytes informed in a human-readable format. e.g. 1.2 MB
+func FormatBytesInUnit(b int64) string {
 	const unit = 1000
 	if b < unit {
 		return fmt.Sprintf("%d B", b)
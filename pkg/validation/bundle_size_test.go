@@ -0,0 +1,108 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestNewBundleSizeFromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "manifests"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	// the random content cannot be compressed, so it is the biggest manifest once compressed
+	random := make([]byte, 2048)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"manifests/csv.yaml":        []byte(strings.Repeat("kind: ClusterServiceVersion\n", 200)),
+		"manifests/crd.yaml":        random,
+		"metadata/annotations.yaml": []byte("annotations: {}\n"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size, err := NewBundleSizeFromDir(dir)
+	if err != nil {
+		t.Fatalf("NewBundleSizeFromDir() error = %v", err)
+	}
+	if len(size.Manifests) != 3 || size.Manifests[0].Name != filepath.Join("manifests", "crd.yaml") {
+		t.Fatalf("unexpected manifests: %+v", size.Manifests)
+	}
+	if size.Size != int64(len(random)+200*28+16) || size.MaxSize != maxBundleSize {
+		t.Errorf("unexpected size: %+v", size)
+	}
+	var compressed int64
+	for _, m := range size.Manifests {
+		compressed += m.CompressedSize
+		// the repetitive content is reduced by the gzip while the random one is increased by the base64 encoding
+		if m.Name == filepath.Join("manifests", "csv.yaml") && m.CompressedSize >= m.Size ||
+			m.Name == filepath.Join("manifests", "crd.yaml") && m.CompressedSize <= m.Size {
+			t.Errorf("unexpected compressed size for %s: %+v", m.Name, m)
+		}
+	}
+	if size.CompressedSize != compressed || size.UsedPercent != usedPercent(compressed) {
+		t.Errorf("unexpected compressed size: %+v", size)
+	}
+
+	if _, err := NewBundleSizeFromDir(filepath.Join(dir, "not-found")); err == nil {
+		t.Errorf("NewBundleSizeFromDir() expected error for a directory which does not exist")
+	}
+}
+
+func TestBundleSizeValidatorWarnPercent(t *testing.T) {
+	bundle := &manifests.Bundle{Name: "memcached.v0.0.1", CSV: &v1alpha1.ClusterServiceVersion{},
+		Size: 900 * 1024, CompressedSize: maxBundleSize / 2}
+
+	tests := []struct {
+		name           string
+		optionalValues map[string]string
+		wantWarn       bool
+	}{
+		{name: "should not warn under the default threshold"},
+		{name: "should warn over the threshold informed", optionalValues: map[string]string{WarnPercentKey: "40"},
+			wantWarn: true},
+		{name: "should accept the threshold with %", optionalValues: map[string]string{WarnPercentKey: "45%"},
+			wantWarn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []interface{}{bundle}
+			if tt.optionalValues != nil {
+				objs = append(objs, tt.optionalValues)
+			}
+			results := BundleSizeValidator.Validate(objs...)
+			if len(results) != 1 || results[0].HasWarn() != tt.wantWarn || results[0].HasError() {
+				t.Errorf("unexpected results: %+v", results)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"github.com/operator-framework/audit/pkg"
+)
+
+// CRDUpgradeValidator will check the CRDs shipped in a bundle against the CRDs of the bundle that it
+// replaces in the same channel. Note that this check will raise errors when:
+// - a version which was used to store the objects is removed
+// - a field or an enum value is removed from the schema of a version which is still served
+// - a field becomes required in a version which is still served
+// and warnings when:
+// - a served version is no longer served
+// - the storage version changes and no conversion webhook is configured
+// - the CRD which was shipped in the previous bundle is no longer shipped
+// - a v1beta1 CRD cannot be converted to v1 to be compared (the CRD is skipped)
+// The v1beta1 CRDs are converted to v1 so that they are compared as the v1 ones, e.g. when a bundle
+// migrates its CRDs from v1beta1 to v1.
+var CRDUpgradeValidator interfaces.Validator = interfaces.ValidatorFunc(validateCRDUpgradeValidator)
+
+// BundleUpgrade defines a bundle and the bundle that it replaces in a channel
+type BundleUpgrade struct {
+	Channel  string
+	Bundle   *manifests.Bundle
+	Replaces *manifests.Bundle
+}
+
+func validateCRDUpgradeValidator(objs ...interface{}) (results []errors.ManifestResult) {
+	for _, obj := range objs {
+		switch v := obj.(type) {
+		case *BundleUpgrade:
+			results = append(results, validateCRDUpgrade(v)...)
+		case BundleUpgrade:
+			results = append(results, validateCRDUpgrade(&v)...)
+		}
+	}
+	return results
+}
+
+func validateCRDUpgrade(upgrade *BundleUpgrade) []errors.ManifestResult {
+	if upgrade.Bundle == nil || upgrade.Replaces == nil {
+		result := errors.ManifestResult{}
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		return []errors.ManifestResult{result}
+	}
+
+	var results []errors.ManifestResult
+	from, skippedFrom := bundleCRDs(upgrade.Replaces)
+	to, skippedTo := bundleCRDs(upgrade.Bundle)
+	skipped := append(skippedFrom, skippedTo...)
+	if len(skipped) > 0 {
+		result := errors.ManifestResult{Name: upgrade.Bundle.Name}
+		result.Add(skipped...)
+		results = append(results, result)
+	}
+
+	for _, name := range pkg.SortedKeys(from) {
+		result := errors.ManifestResult{Name: upgrade.Bundle.Name}
+		prefix := fmt.Sprintf("upgrade from %s to %s in the channel %s: CRD %s",
+			upgrade.Replaces.Name, upgrade.Bundle.Name, upgrade.Channel, name)
+
+		newCRD, found := to[name]
+		if !found && isSkipped(skippedTo, name) {
+			continue
+		}
+		if !found {
+			result.Add(errors.WarnInvalidOperation(fmt.Sprintf("%s is no longer shipped in the bundle", prefix), name))
+		} else {
+			result.Add(compareCRDs(prefix, from[name], newCRD, hasConversionWebhook(upgrade.Bundle.CSV, newCRD))...)
+		}
+
+		if result.HasError() || result.HasWarn() {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// bundleCRDs returns the CRDs of the bundle by name with the v1beta1 ones converted to v1 and warnings
+// for the v1beta1 CRDs which could not be converted, which are not compared
+func bundleCRDs(bundle *manifests.Bundle) (map[string]*apiextensionsv1.CustomResourceDefinition, []errors.Error) {
+	crds := make(map[string]*apiextensionsv1.CustomResourceDefinition)
+	for _, crd := range bundle.V1CRDs {
+		crds[crd.Name] = crd
+	}
+
+	var skipped []errors.Error
+	for _, crd := range bundle.V1beta1CRDs {
+		converted, err := convertV1beta1CRD(crd)
+		if err != nil {
+			skipped = append(skipped, errors.WarnInvalidOperation(fmt.Sprintf("bundle %s: unable to compare "+
+				"the v1beta1 CRD %s, it was skipped: %s", bundle.Name, crd.Name, err), crd.Name))
+			continue
+		}
+		crds[crd.Name] = converted
+	}
+	return crds, skipped
+}
+
+// convertV1beta1CRD converts the v1beta1 CRD to v1 as the API server does
+func convertV1beta1CRD(crd *apiextensionsv1beta1.CustomResourceDefinition) (*apiextensionsv1.CustomResourceDefinition,
+	error) {
+	// the defaults move the spec.version to the spec.versions
+	in := crd.DeepCopy()
+	apiextensionsv1beta1.SetObjectDefaults_CustomResourceDefinition(in)
+
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := apiextensionsv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(
+		in, internal, nil); err != nil {
+		return nil, err
+	}
+	out := &apiextensionsv1.CustomResourceDefinition{}
+	if err := apiextensionsv1.Convert_apiextensions_CustomResourceDefinition_To_v1_CustomResourceDefinition(
+		internal, out, nil); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// isSkipped returns true when the CRD could not be compared
+func isSkipped(skipped []errors.Error, crdName string) bool {
+	for _, err := range skipped {
+		if err.BadValue == crdName {
+			return true
+		}
+	}
+	return false
+}
+
+// compareCRDs returns the breaking changes found from the old CRD to the new one
+func compareCRDs(prefix string, oldCRD, newCRD *apiextensionsv1.CustomResourceDefinition,
+	hasWebhook bool) []errors.Error {
+	var errs []errors.Error
+
+	oldStorage := storageVersion(oldCRD)
+	newStorage := storageVersion(newCRD)
+
+	newVersions := make(map[string]apiextensionsv1.CustomResourceDefinitionVersion)
+	for _, v := range newCRD.Spec.Versions {
+		newVersions[v.Name] = v
+	}
+
+	for _, oldVersion := range oldCRD.Spec.Versions {
+		newVersion, found := newVersions[oldVersion.Name]
+		switch {
+		case !found && (oldVersion.Name == oldStorage || containsString(oldCRD.Status.StoredVersions, oldVersion.Name)):
+			errs = append(errs, errors.ErrInvalidOperation(fmt.Sprintf("%s removed the version %s which holds stored "+
+				"objects. The stored objects must be migrated before the version is removed",
+				prefix, oldVersion.Name), newCRD.Name))
+			continue
+		case !found && oldVersion.Served:
+			errs = append(errs, errors.WarnInvalidOperation(fmt.Sprintf("%s removed the served version %s",
+				prefix, oldVersion.Name), newCRD.Name))
+			continue
+		case !found:
+			continue
+		case oldVersion.Served && !newVersion.Served:
+			errs = append(errs, errors.WarnInvalidOperation(fmt.Sprintf("%s no longer serves the version %s",
+				prefix, oldVersion.Name), newCRD.Name))
+		}
+
+		if !newVersion.Served {
+			continue
+		}
+
+		var oldSchema, newSchema *apiextensionsv1.JSONSchemaProps
+		if oldVersion.Schema != nil {
+			oldSchema = oldVersion.Schema.OpenAPIV3Schema
+		}
+		if newVersion.Schema != nil {
+			newSchema = newVersion.Schema.OpenAPIV3Schema
+		}
+		for _, change := range CompareSchemas(oldSchema, newSchema) {
+			errs = append(errs, errors.ErrInvalidOperation(fmt.Sprintf("%s version %s %s",
+				prefix, oldVersion.Name, change), newCRD.Name))
+		}
+	}
+
+	if len(oldStorage) > 0 && len(newStorage) > 0 && oldStorage != newStorage && !hasWebhook {
+		errs = append(errs, errors.WarnInvalidOperation(fmt.Sprintf("%s changed the storage version from %s to %s "+
+			"without a conversion webhook. Note that the objects stored will not be converted",
+			prefix, oldStorage, newStorage), newCRD.Name))
+	}
+
+	return errs
+}
+
+// CompareSchemas returns the breaking changes found from the old schema to the new one such as
+// removed fields, removed enum values and fields which became required
+func CompareSchemas(oldSchema, newSchema *apiextensionsv1.JSONSchemaProps) []string {
+	if oldSchema == nil || newSchema == nil {
+		return nil
+	}
+	var changes []string
+	compareSchemaProps("", oldSchema, newSchema, &changes)
+	return changes
+}
+
+func compareSchemaProps(path string, oldSchema, newSchema *apiextensionsv1.JSONSchemaProps, changes *[]string) {
+	fieldPath := path
+	if len(fieldPath) == 0 {
+		fieldPath = "."
+	}
+
+	// A schema which preserve unknown fields accepts any value, so removing fields from it is not breaking
+	preserveUnknown := newSchema.XPreserveUnknownFields != nil && *newSchema.XPreserveUnknownFields
+	for _, name := range pkg.SortedKeys(oldSchema.Properties) {
+		newProp, found := newSchema.Properties[name]
+		if !found {
+			if !preserveUnknown {
+				*changes = append(*changes, fmt.Sprintf("removed the field %s.%s", path, name))
+			}
+			continue
+		}
+		oldProp := oldSchema.Properties[name]
+		compareSchemaProps(path+"."+name, &oldProp, &newProp, changes)
+	}
+
+	if oldSchema.Items != nil && oldSchema.Items.Schema != nil &&
+		newSchema.Items != nil && newSchema.Items.Schema != nil {
+		compareSchemaProps(path+"[]", oldSchema.Items.Schema, newSchema.Items.Schema, changes)
+	}
+
+	if len(newSchema.Enum) > 0 {
+		newEnum := make(map[string]bool)
+		for _, v := range newSchema.Enum {
+			newEnum[string(v.Raw)] = true
+		}
+		for _, v := range oldSchema.Enum {
+			if !newEnum[string(v.Raw)] {
+				*changes = append(*changes, fmt.Sprintf("removed the enum value %s from the field %s",
+					string(v.Raw), fieldPath))
+			}
+		}
+	}
+
+	for _, required := range newSchema.Required {
+		if !containsString(oldSchema.Required, required) {
+			*changes = append(*changes, fmt.Sprintf("added the required field %s.%s", path, required))
+		}
+	}
+}
+
+// hasConversionWebhook returns true when the CRD is configured to use a conversion webhook
+// either via its spec or via the webhook definitions of the CSV
+func hasConversionWebhook(csv *v1alpha1.ClusterServiceVersion, crd *apiextensionsv1.CustomResourceDefinition) bool {
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextensionsv1.WebhookConverter {
+		return true
+	}
+	if csv == nil {
+		return false
+	}
+	for _, webhook := range csv.Spec.WebhookDefinitions {
+		if webhook.Type != v1alpha1.ConversionWebhook {
+			continue
+		}
+		for _, name := range webhook.ConversionCRDs {
+			if strings.EqualFold(name, crd.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
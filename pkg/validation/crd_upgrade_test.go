@@ -0,0 +1,195 @@
+// Copyright 2021 The Audit Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newCRD(versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "memcacheds.cache.example.com"},
+		Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Versions: versions},
+	}
+}
+
+func newVersion(name string, served, storage bool,
+	schema *apiextensionsv1.JSONSchemaProps) apiextensionsv1.CustomResourceDefinitionVersion {
+	version := apiextensionsv1.CustomResourceDefinitionVersion{Name: name, Served: served, Storage: storage}
+	if schema != nil {
+		version.Schema = &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: schema}
+	}
+	return version
+}
+
+func newSchema(required []string, enum ...string) *apiextensionsv1.JSONSchemaProps {
+	size := apiextensionsv1.JSONSchemaProps{Type: "integer"}
+	mode := apiextensionsv1.JSONSchemaProps{Type: "string"}
+	for _, v := range enum {
+		mode.Enum = append(mode.Enum, apiextensionsv1.JSON{Raw: []byte(`"` + v + `"`)})
+	}
+	return &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type:       "object",
+				Required:   required,
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"size": size, "mode": mode},
+			},
+		},
+	}
+}
+
+func TestCRDUpgradeValidator(t *testing.T) {
+	withoutSize := newSchema(nil, "a", "b")
+	delete(withoutSize.Properties["spec"].Properties, "size")
+
+	tests := []struct {
+		name         string
+		from         *apiextensionsv1.CustomResourceDefinition
+		to           *apiextensionsv1.CustomResourceDefinition
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "should not return results when the CRDs are the same",
+			from: newCRD(newVersion("v1", true, true, newSchema(nil, "a", "b"))),
+			to:   newCRD(newVersion("v1", true, true, newSchema(nil, "a", "b"))),
+		},
+		{
+			name: "should not return results when fields and enum values are added",
+			from: newCRD(newVersion("v1", true, true, newSchema(nil, "a"))),
+			to:   newCRD(newVersion("v1", true, true, newSchema(nil, "a", "b"))),
+		},
+		{
+			name:       "should return an error when the storage version is removed",
+			from:       newCRD(newVersion("v1alpha1", true, true, nil)),
+			to:         newCRD(newVersion("v1", true, true, nil)),
+			wantErrors: []string{"removed the version v1alpha1 which holds stored objects"},
+			wantWarnings: []string{
+				"changed the storage version from v1alpha1 to v1 without a conversion webhook",
+			},
+		},
+		{
+			name:         "should return a warning when a served version is no longer served",
+			from:         newCRD(newVersion("v1alpha1", true, false, nil), newVersion("v1", true, true, nil)),
+			to:           newCRD(newVersion("v1alpha1", false, false, nil), newVersion("v1", true, true, nil)),
+			wantWarnings: []string{"no longer serves the version v1alpha1"},
+		},
+		{
+			name:       "should return an error when a field is removed",
+			from:       newCRD(newVersion("v1", true, true, newSchema(nil, "a", "b"))),
+			to:         newCRD(newVersion("v1", true, true, withoutSize)),
+			wantErrors: []string{"removed the field .spec.size"},
+		},
+		{
+			name:       "should return an error when an enum value is removed",
+			from:       newCRD(newVersion("v1", true, true, newSchema(nil, "a", "b"))),
+			to:         newCRD(newVersion("v1", true, true, newSchema(nil, "a"))),
+			wantErrors: []string{`removed the enum value "b" from the field .spec.mode`},
+		},
+		{
+			name:       "should return an error when a field becomes required",
+			from:       newCRD(newVersion("v1", true, true, newSchema(nil, "a"))),
+			to:         newCRD(newVersion("v1", true, true, newSchema([]string{"size"}, "a"))),
+			wantErrors: []string{"added the required field .spec.size"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrade := &BundleUpgrade{
+				Channel:  "stable",
+				Bundle:   &manifests.Bundle{Name: "memcached.v0.0.2", V1CRDs: []*apiextensionsv1.CustomResourceDefinition{tt.to}},
+				Replaces: &manifests.Bundle{Name: "memcached.v0.0.1", V1CRDs: []*apiextensionsv1.CustomResourceDefinition{tt.from}},
+			}
+
+			var gotErrors, gotWarnings []string
+			for _, result := range CRDUpgradeValidator.Validate(upgrade) {
+				for _, e := range result.Errors {
+					gotErrors = append(gotErrors, e.Error())
+				}
+				for _, w := range result.Warnings {
+					gotWarnings = append(gotWarnings, w.Error())
+				}
+			}
+
+			checkMessages(t, "errors", gotErrors, tt.wantErrors)
+			checkMessages(t, "warnings", gotWarnings, tt.wantWarnings)
+		})
+	}
+}
+
+func checkMessages(t *testing.T, kind string, got, want []string) {
+	if len(got) != len(want) {
+		t.Errorf("CRDUpgradeValidator() %s = %v, want %v", kind, got, want)
+		return
+	}
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("CRDUpgradeValidator() %s = %v, want %v", kind, got[i], want[i])
+		}
+	}
+}
+
+func TestCRDUpgradeValidatorV1beta1(t *testing.T) {
+	// the replaced bundle ships the CRD as v1beta1 with the schema informed for all versions
+	old := &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "memcacheds.cache.example.com"},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Version: "v1alpha1",
+			Validation: &apiextensionsv1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"spec": {Type: "object", Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+							"size": {Type: "integer"}, "mode": {Type: "string"}}},
+					},
+				},
+			},
+		},
+	}
+
+	upgrade := &BundleUpgrade{
+		Channel: "stable",
+		Bundle: &manifests.Bundle{Name: "memcached.v0.0.2",
+			V1CRDs: []*apiextensionsv1.CustomResourceDefinition{
+				newCRD(newVersion("v1alpha1", true, true, newSchema([]string{"size"})))}},
+		Replaces: &manifests.Bundle{Name: "memcached.v0.0.1",
+			V1beta1CRDs: []*apiextensionsv1beta1.CustomResourceDefinition{old}},
+	}
+
+	var gotErrors, gotWarnings []string
+	for _, result := range CRDUpgradeValidator.Validate(upgrade) {
+		for _, e := range result.Errors {
+			gotErrors = append(gotErrors, e.Error())
+		}
+		for _, w := range result.Warnings {
+			gotWarnings = append(gotWarnings, w.Error())
+		}
+	}
+	checkMessages(t, "errors", gotErrors, []string{"added the required field .spec.size"})
+	checkMessages(t, "warnings", gotWarnings, nil)
+
+	// the bundle is not changed by the conversion
+	if len(old.Spec.Versions) != 0 {
+		t.Errorf("the v1beta1 CRD of the bundle should not be changed: %+v", old.Spec.Versions)
+	}
+}